@@ -0,0 +1,375 @@
+// Package analyzer provides a go vet compatible analyzer that statically
+// checks envvar struct tags: duplicate keys, malformed env tags,
+// unsupported field types, envdef values that will not parse into the
+// field type, and invalid validate rules. It lives in its own module
+// so the envvar library itself stays dependency free.
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer checks envvar struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "envvartags",
+	Doc:      "check envvar struct tags for duplicate keys, unsupported field types, unparsable defaults, and invalid validate rules",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// extraTypes lists additional accepted field types, e.g. types with a
+// converter registered at runtime.
+var extraTypes string
+
+// extraRules lists additional validate rule names, e.g. rules
+// registered at runtime with validate.Register.
+var extraRules string
+
+func init() {
+	Analyzer.Flags.StringVar(&extraTypes, "types", "",
+		"comma-separated qualified types with registered converters (e.g. github.com/shopspring/decimal.Decimal)")
+	Analyzer.Flags.StringVar(&extraRules, "rules", "",
+		"comma-separated validate rule names registered at runtime (e.g. s3bucket)")
+}
+
+// builtinRules lists the validate rules of the validate package,
+// including the cross-field ones.
+var builtinRules = map[string]bool{
+	"required": true, "min": true, "max": true, "gt": true, "gte": true,
+	"lt": true, "lte": true, "oneof": true, "ip": true, "cidr": true,
+	"hostname": true, "port": true, "url": true, "email": true,
+	"required_with": true, "required_without": true, "eqfield": true,
+	"nefield": true, "gtfield": true, "gtefield": true, "ltfield": true,
+	"ltefield": true,
+}
+
+// run inspects every struct type literal in the package.
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	extra := map[string]bool{}
+	for _, t := range strings.Split(extraTypes, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			extra[t] = true
+		}
+	}
+	rules := map[string]bool{}
+	for _, r := range strings.Split(extraRules, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			rules[r] = true
+		}
+	}
+	ins.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st, ok := pass.TypesInfo.TypeOf(n.(*ast.StructType)).(*types.Struct)
+		if !ok {
+			return
+		}
+		c := &checker{pass: pass, extra: extra, rules: rules, seen: map[string]token.Pos{}}
+		c.checkStruct(st, token.NoPos)
+	})
+	return nil, nil
+}
+
+// checker holds per-struct state.
+type checker struct {
+	pass  *analysis.Pass
+	extra map[string]bool
+	rules map[string]bool
+	seen  map[string]token.Pos
+}
+
+// checkStruct checks the fields of st. When at is valid, st is an
+// embedded struct reached from the field at; its keys only take part in
+// duplicate detection, since its own declaration is checked separately.
+func (c *checker) checkStruct(st *types.Struct, at token.Pos) {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		ev, hasEnv := tag.Lookup("env")
+		pos := at
+		if !pos.IsValid() {
+			pos = f.Pos()
+		}
+		if !hasEnv {
+			if f.Embedded() {
+				c.checkEmbedded(f.Type(), pos)
+			}
+			continue
+		}
+		name, opts := splitTag(ev)
+		empty := false
+		for _, n := range strings.Split(name, "|") {
+			n = strings.TrimSpace(n)
+			if n == "" {
+				empty = true
+				continue
+			}
+			if prev, dup := c.seen[n]; dup {
+				c.pass.Reportf(pos, "envvar: duplicate env key %s (also at %s)",
+					n, c.pass.Fset.Position(prev))
+			} else {
+				c.seen[n] = f.Pos()
+			}
+		}
+		if at.IsValid() {
+			continue
+		}
+		if empty {
+			c.pass.Reportf(pos, "envvar: empty variable name in env tag")
+			continue
+		}
+		for _, o := range opts {
+			if o != "required" && o != "file" {
+				c.pass.Reportf(pos, "envvar: unknown env tag option %q", o)
+			}
+		}
+		if rules := tag.Get("validate"); rules != "" {
+			c.checkRules(st, f.Type(), rules, name, pos)
+		}
+		if strings.EqualFold(tag.Get("envjson"), "true") {
+			continue
+		}
+		if msg := c.unsupported(f.Type()); msg != "" {
+			c.pass.Reportf(pos, "envvar: %s: %s", name, msg)
+			continue
+		}
+		if def, ok := tag.Lookup("envdef"); ok && def != "" {
+			if msg := checkDefault(f.Type(), def, tag.Get("envlayout")); msg != "" {
+				c.pass.Reportf(pos, "envvar: %s: envdef %q %s", name, def, msg)
+			}
+		}
+	}
+}
+
+// checkRules reports unknown rule names in the validate tag rules of a
+// field of type t in st, bound parameters that do not parse for t, and
+// cross-field rules naming fields st does not have.
+func (c *checker) checkRules(st *types.Struct, t types.Type, rules, name string, pos token.Pos) {
+	for _, r := range strings.Split(rules, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(r), "=")
+		switch {
+		case rule == "":
+		case !builtinRules[rule] && !c.rules[rule]:
+			c.pass.Reportf(pos, "envvar: %s: unknown validate rule %q", name, rule)
+		case strings.HasSuffix(rule, "field") || strings.HasPrefix(rule, "required_w"):
+			for _, other := range strings.Split(param, "|") {
+				if obj, _, _ := types.LookupFieldOrMethod(st, true, c.pass.Pkg, other); obj == nil {
+					c.pass.Reportf(pos, "envvar: %s: %s names unknown field %q", name, rule, other)
+				}
+			}
+		case rule == "min" || rule == "max" || rule == "gt" || rule == "gte" ||
+			rule == "lt" || rule == "lte":
+			if !validBound(t, param) {
+				c.pass.Reportf(pos, "envvar: %s: invalid %s parameter %q for %s",
+					name, rule, param, types.TypeString(t, nil))
+			}
+		}
+	}
+}
+
+// validBound reports whether param parses as a min, max, gt, gte, lt,
+// or lte bound for a field of type t, following validate.checkBound.
+func validBound(t types.Type, param string) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if isNamed(t, "time", "Duration") {
+		_, err := time.ParseDuration(param)
+		return err == nil
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsUnsigned != 0:
+			if strings.HasPrefix(param, "-") {
+				_, err := strconv.ParseInt(param, 10, 64)
+				return err == nil
+			}
+			_, err := strconv.ParseUint(param, 10, 64)
+			return err == nil
+		case u.Info()&types.IsInteger != 0:
+			_, err := strconv.ParseInt(param, 10, 64)
+			return err == nil
+		case u.Info()&types.IsFloat != 0:
+			f, err := strconv.ParseFloat(param, 64)
+			return err == nil && !math.IsNaN(f)
+		case u.Info()&types.IsString != 0:
+			_, err := strconv.Atoi(param)
+			return err == nil
+		}
+	case *types.Slice, *types.Map:
+		_, err := strconv.Atoi(param)
+		return err == nil
+	}
+	return true
+}
+
+// checkEmbedded descends into an untagged embedded struct so duplicate
+// keys across shared fragments are detected.
+func (c *checker) checkEmbedded(t types.Type, at token.Pos) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		c.checkStruct(st, at)
+	}
+}
+
+// unsupported returns a message if Bind cannot populate t.
+func (c *checker) unsupported(t types.Type) string {
+	if c.extra[types.TypeString(t, nil)] {
+		return ""
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		if isNamed(ptr.Elem(), "net/url", "URL") {
+			return ""
+		}
+		return c.unsupported(ptr.Elem())
+	}
+	if isNamed(t, "net/url", "URL") {
+		return "use *url.URL, not url.URL"
+	}
+	if isNamed(t, "time", "Time") || isNetType(t) {
+		return ""
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Info()&(types.IsString|types.IsBoolean|types.IsInteger|types.IsFloat) != 0 {
+			return ""
+		}
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.String {
+			return ""
+		}
+		if c.extra[types.TypeString(u.Elem(), nil)] || isNetType(u.Elem()) {
+			return ""
+		}
+		return "only []string slices supported"
+	case *types.Map:
+		k, ok := u.Key().Underlying().(*types.Basic)
+		if !ok || k.Kind() != types.String {
+			return "only string map keys supported"
+		}
+		switch u.Elem().Underlying().(type) {
+		case *types.Slice, *types.Map:
+			return "unsupported map value type " + types.TypeString(u.Elem(), nil)
+		}
+		return c.unsupported(u.Elem())
+	}
+	return "unsupported field type " + types.TypeString(t, nil)
+}
+
+// checkDefault returns a message if def does not parse into t. layout
+// is the envlayout tag of time.Time fields.
+func checkDefault(t types.Type, def, layout string) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if isNamed(t, "github.com/aatuh/envvar/v2/types", "Bytes") {
+		if !bytesRe.MatchString(def) {
+			return "is not a valid byte size"
+		}
+		return ""
+	}
+	if isNamed(t, "github.com/aatuh/envvar/v2/types", "TriBool") {
+		switch strings.ToLower(strings.TrimSpace(def)) {
+		case "1", "t", "true", "y", "yes", "on", "0", "f", "false", "n", "no", "off":
+			return ""
+		}
+		return "is not a valid boolean"
+	}
+	if isNamed(t, "time", "Time") {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		if _, err := time.Parse(layout, strings.TrimSpace(def)); err != nil {
+			return "does not match layout " + layout
+		}
+		return ""
+	}
+	if isNamed(t, "time", "Duration") {
+		if _, err := time.ParseDuration(def); err != nil {
+			return "is not a valid duration"
+		}
+		return ""
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	bits := basicBits(b)
+	switch {
+	case b.Info()&types.IsBoolean != 0:
+		switch strings.ToLower(strings.TrimSpace(def)) {
+		case "1", "t", "true", "y", "yes", "on", "0", "f", "false", "n", "no", "off":
+			return ""
+		}
+		return "is not a valid boolean"
+	case b.Info()&types.IsUnsigned != 0:
+		if _, err := strconv.ParseUint(def, 10, bits); err != nil {
+			return "does not fit " + b.Name()
+		}
+	case b.Info()&types.IsInteger != 0:
+		if _, err := strconv.ParseInt(def, 10, bits); err != nil {
+			return "does not fit " + b.Name()
+		}
+	case b.Info()&types.IsFloat != 0:
+		if _, err := strconv.ParseFloat(def, bits); err != nil {
+			return "is not a valid " + b.Name()
+		}
+	}
+	return ""
+}
+
+// bytesRe matches the sizes accepted by types.ParseBytes.
+var bytesRe = regexp.MustCompile(`(?i)^\s*[0-9]*\.?[0-9]+\s*([kmgtpe](i?b?|ib)?|b)?\s*$`)
+
+// basicBits returns the bit size used for parsing b.
+func basicBits(b *types.Basic) int {
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	}
+	return 64
+}
+
+// isNetType reports whether t is netip.Prefix or net.IPNet.
+func isNetType(t types.Type) bool {
+	return isNamed(t, "net/netip", "Prefix") || isNamed(t, "net", "IPNet")
+}
+
+// isNamed reports whether t is the named type pkg.name.
+func isNamed(t types.Type, pkg, name string) bool {
+	n, ok := t.(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return false
+	}
+	return n.Obj().Pkg().Path() == pkg && n.Obj().Name() == name
+}
+
+// splitTag splits an env tag into its name and options.
+func splitTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	var opts []string
+	for _, p := range parts[1:] {
+		if p = strings.TrimSpace(p); p != "" {
+			opts = append(opts, p)
+		}
+	}
+	return strings.TrimSpace(parts[0]), opts
+}
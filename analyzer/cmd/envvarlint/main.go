@@ -0,0 +1,13 @@
+// Command envvarlint runs the envvar struct tag analyzer. It can be
+// used standalone or via go vet -vettool=$(which envvarlint).
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/aatuh/envvar/v2/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
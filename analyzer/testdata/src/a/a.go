@@ -0,0 +1,56 @@
+package a
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+type Shared struct {
+	Port int `env:"PORT"`
+}
+
+type Config struct {
+	Shared
+	Port2   int            `env:"PORT"`                // want `duplicate env key PORT`
+	Timeout time.Duration  `env:"TIMEOUT" envdef:"5x"` // want `envdef "5x" is not a valid duration`
+	Small   int8           `env:"SMALL" envdef:"300"`  // want `envdef "300" does not fit int8`
+	Flag    bool           `env:"FLAG" envdef:"maybe"` // want `envdef "maybe" is not a valid boolean`
+	U       url.URL        `env:"URL"`                 // want `use \*url.URL`
+	PU      *url.URL       `env:"PURL"`
+	Ints    []int          `env:"INTS"` // want `only \[\]string slices supported`
+	M       map[string]int `env:"M" envjson:"true"`
+	Ch      chan int       `env:"CH"`          // want `unsupported field type chan int`
+	Bad     string         `env:"BAD,requird"` // want `unknown env tag option "requird"`
+	Ok      uint16         `env:"OK" envdef:"8080"`
+	Day     time.Time      `env:"DAY" envlayout:"2006-01-02" envdef:"2024-01-31"`
+	BadDay  time.Time      `env:"BAD_DAY" envdef:"2024-01-31"` // want `envdef "2024-01-31" does not match layout`
+	Nets    []netip.Prefix `env:"NETS"`
+	IPNet   *net.IPNet     `env:"IPNET"`
+	Limits  map[string]int `env:"LIMITS"`
+	ByID    map[int]string `env:"BY_ID"` // want `only string map keys supported`
+	HTTP    int            `env:"HTTP_PORT|LEGACY_PORT"`
+	Legacy  int            `env:"LEGACY_PORT"` // want `duplicate env key LEGACY_PORT`
+	Blank   int            `env:"A|"`          // want `empty variable name in env tag`
+	Cache   types.TriBool  `env:"CACHE" envdef:"off"`
+	BadTri  types.TriBool  `env:"BAD_TRI" envdef:"2"` // want `envdef "2" is not a valid boolean`
+	Buf     types.Bytes    `env:"BUF" envdef:"64KiB"`
+	BadBuf  types.Bytes    `env:"BAD_BUF" envdef:"64 kilobytes"` // want `envdef "64 kilobytes" is not a valid byte size`
+}
+
+type Rules struct {
+	Workers int           `env:"WORKERS" validate:"required,min=1,max=64"`
+	Typo    int           `env:"TYPO" validate:"mni=1"`     // want `unknown validate rule "mni"`
+	BadMin  int           `env:"BAD_MIN" validate:"min=1s"` // want `invalid min parameter "1s" for int`
+	Wait    time.Duration `env:"WAIT" validate:"gt=1s,lte=1m"`
+	BadWait time.Duration `env:"BAD_WAIT" validate:"lt=10"` // want `invalid lt parameter "10" for time.Duration`
+	Name    string        `env:"NAME" validate:"gte=x"`     // want `invalid gte parameter "x" for string`
+	Ratio   float64       `env:"RATIO" validate:"max=NaN"`  // want `invalid max parameter "NaN" for float64`
+	Low     int           `env:"LOW" validate:"ltefield=Workers"`
+	Cross   int           `env:"CROSS" validate:"ltefield=Nope"` // want `ltefield names unknown field "Nope"`
+	With    string        `env:"WITH" validate:"required_with=Name"`
+	Host    string        `env:"HOST" validate:"hostname,oneof=a|b"`
+}
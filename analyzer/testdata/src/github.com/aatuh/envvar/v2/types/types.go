@@ -0,0 +1,7 @@
+package types
+
+// Bytes mirrors the envvar byte size type.
+type Bytes uint64
+
+// TriBool mirrors the envvar tri-state boolean type.
+type TriBool int
@@ -1,8 +1,12 @@
 package binders
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/netip"
 	"net/url"
 	"os"
 	"reflect"
@@ -10,41 +14,69 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aatuh/envvar/v2/decrypt"
 	"github.com/aatuh/envvar/v2/expand"
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/sources"
+	"github.com/aatuh/envvar/v2/types"
+	"github.com/aatuh/envvar/v2/validate"
 )
 
-// Bind populates a struct from the process environment using `env` tags.
+// Bind populates a struct from the default source using `env` tags.
 // See BindWithPrefix for details.
 //
 // Parameters:
 //   - dst: The destination.
+//   - opts: The options, e.g. WithReport.
 //
 // Returns:
 //   - error: The error if the binding fails.
-func Bind(dst any) error {
-	return bindWithOptions(dst, "")
+func Bind(dst any, opts ...Option) error {
+	return bindWithOptions(dst, "", buildOptions(opts))
+}
+
+// BindCtx is like Bind but passes ctx to sources implementing
+// sources.ContextSource and to reference resolvers implementing
+// refs.ContextResolver. Once ctx is done the remaining fields are
+// skipped and the error matches ctx.Err() with errors.Is.
+//
+// Parameters:
+//   - ctx: The context.
+//   - dst: The destination.
+//   - opts: The options.
+//
+// Returns:
+//   - error: The error if the binding fails or ctx is done.
+func BindCtx(ctx context.Context, dst any, opts ...Option) error {
+	o := buildOptions(opts)
+	o.ctx = ctx
+	return bindWithOptions(dst, "", o)
 }
 
 // BindWithPrefix is like Bind but first tries variables with the given
 // prefix. For example with prefix "MYAPP_", field `env:"PORT"` resolves
-// "MYAPP_PORT" if present, else falls back to "PORT".
+// "MYAPP_PORT" if present, else falls back to "PORT". With WithStrict,
+// prefixed variables that map to no field are reported as errors.
 //
 // Parameters:
 //   - dst: The destination.
 //   - prefix: The prefix.
+//   - opts: The options.
 //
 // Returns:
 //   - error: The error if the binding fails.
-func BindWithPrefix(dst any, prefix string) error {
-	return bindWithOptions(dst, prefix)
+func BindWithPrefix(dst any, prefix string, opts ...Option) error {
+	return bindWithOptions(dst, prefix, buildOptions(opts))
 }
 
 // MustBind panics on binding errors.
 //
 // Parameters:
 //   - dst: The destination.
-func MustBind(dst any) {
-	if err := Bind(dst); err != nil {
+//   - opts: The options.
+func MustBind(dst any, opts ...Option) {
+	if err := Bind(dst, opts...); err != nil {
 		panic(err)
 	}
 }
@@ -54,14 +86,15 @@ func MustBind(dst any) {
 // Parameters:
 //   - dst: The destination.
 //   - prefix: The prefix.
-func MustBindWithPrefix(dst any, prefix string) {
-	if err := BindWithPrefix(dst, prefix); err != nil {
+//   - opts: The options.
+func MustBindWithPrefix(dst any, prefix string, opts ...Option) {
+	if err := BindWithPrefix(dst, prefix, opts...); err != nil {
 		panic(err)
 	}
 }
 
 // bindWithOptions binds the options.
-func bindWithOptions(dst any, prefix string) error {
+func bindWithOptions(dst any, prefix string, o options) error {
 	rv := reflect.ValueOf(dst)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return fmt.Errorf("envvar: Bind expects pointer to struct")
@@ -71,176 +104,693 @@ func bindWithOptions(dst any, prefix string) error {
 		return fmt.Errorf("envvar: Bind expects pointer to struct")
 	}
 
-	var errs MultiError
+	start := types.Now()
+	b := &binder{prefix: prefix, known: map[string]bool{}, set: map[string]bool{},
+		version: AppVersion, hook: o.hook, ctx: o.ctx}
+	if b.ctx == nil {
+		b.ctx = context.Background()
+	}
+	if o.version != "" {
+		b.version = o.version
+	}
+	if o.report != nil {
+		o.report.Fields = nil
+		b.report = o.report
+	}
+	if o.prompt != nil {
+		b.prompt = newPrompter(*o.prompt)
+	}
+	if o.warnings != nil {
+		*o.warnings = nil
+		b.warnings = o.warnings
+	}
+	if o.plan == nil {
+		o.plan = planOf(rv.Type())
+	}
+	b.bindStruct(rv, o.plan)
+	b.checkCross(rv)
+	if b.ctxErr == nil {
+		b.checkGroups()
+	}
+	if b.prompt != nil {
+		if err := b.prompt.save(); err != nil {
+			b.errs = append(b.errs, err)
+		}
+	}
+	if !o.strict && !o.strictWarn {
+		p := types.CurrentProfile()
+		o.strict, o.strictWarn = p.RejectUnknown(), p.WarnUnknown()
+	}
+	if prefix != "" && (o.strict || o.strictWarn) {
+		for _, k := range b.unknown() {
+			err := &KeyError{Key: k, Kind: ErrUnknown}
+			if o.strict {
+				b.fail(&fieldCtx{key: k}, ErrUnknown, err)
+			} else {
+				b.warn(&fieldCtx{key: k}, "unknown variable")
+				b.onError(err)
+			}
+		}
+	}
+	if b.ctxErr != nil {
+		b.errs = append(b.errs, fmt.Errorf("envvar: bind: %w", b.ctxErr))
+	}
+	b.onBind(rv.Type().String(), types.Since(start))
+	if len(b.errs) > 0 {
+		return b.errs
+	}
+	return nil
+}
+
+// binder carries the state of a single Bind call.
+type binder struct {
+	prefix string
+	errs   MultiError
+	// known holds every key a field may read.
+	known map[string]bool
+	// report, when set, receives the resolution of each field.
+	report *Report
+	// set holds the Go names of fields that received a value.
+	set map[string]bool
+	// cross lists the validated fields for the cross-field pass.
+	cross []*fieldCtx
+	// prompt, when set, asks for missing required variables.
+	prompt *prompter
+	// version is the application version for envdeprecated checks.
+	version string
+	// warnings, when set, receives the soft issues.
+	warnings *Warnings
+	// fields counts the tagged fields visited.
+	fields int
+	// assigned counts the fields that received a value.
+	assigned int
+	// hook, when set, receives hook calls next to the global hook.
+	hook types.Hook
+	// ctx bounds source lookups and reference resolution.
+	ctx context.Context
+	// ctxErr is set once ctx is done; remaining fields are skipped.
+	ctxErr error
+	// groups records the fields carrying envgroup tags.
+	groups []groupField
+}
+
+// fail records err for the field fc, applying registered messages.
+// Values that fail to parse or validate emit EventValidationFailed.
+func (b *binder) fail(fc *fieldCtx, kind ErrKind, err error) {
+	var fe *FieldError
+	if errors.As(err, &fe) && fe.Rule != "file" {
+		types.Emit(types.Event{Kind: types.EventValidationFailed, Key: fc.key, Err: err})
+		b.onValidationError(fe.Field, fe.Rule, fe.Err.Error())
+	}
+	b.errs = append(b.errs, localize(*fc, kind, err))
+}
 
-	rt := rv.Type()
-	for i := 0; i < rt.NumField(); i++ {
-		f := rt.Field(i)
-		if f.PkgPath != "" { // unexported
-			continue
+// checkCross evaluates cross-field rules such as required_with and
+// ltefield against the fully bound struct rv.
+func (b *binder) checkCross(rv reflect.Value) {
+	isSet := func(field string) bool { return b.set[field] }
+	for _, fc := range b.cross {
+		err := validate.Cross(rv, fc.field, fc.rules, isSet)
+		switch {
+		case err == nil:
+		case errors.Is(err, validate.ErrRequired):
+			b.fail(fc, ErrMissing, &KeyError{Key: fc.key, Kind: ErrMissing, Msg: err.Error()})
+		default:
+			b.fail(fc, 0, fc.ruleError(err))
 		}
-		ev, ok := f.Tag.Lookup("env")
-		if !ok {
-			continue
+	}
+}
+
+// unknown returns the prefixed keys of the default source that no
+// field reads, sorted.
+func (b *binder) unknown() []string {
+	var out []string
+	for _, k := range sources.SortedKeys(sources.Default()) {
+		if strings.HasPrefix(k, b.prefix) && !b.known[k] {
+			out = append(out, k)
 		}
-		name, req := parseEnvTag(ev)
-		def := f.Tag.Get("envdef")
-		sep := f.Tag.Get("envsep")
-		if sep == "" {
-			sep = ","
+	}
+	return out
+}
+
+// bindStruct binds rv following plan, descending into embedded structs
+// so their promoted fields are populated too. The plan holds no
+// embedded type twice on a path, so the descent ends.
+func (b *binder) bindStruct(rv reflect.Value, plan *structPlan) {
+	for _, st := range plan.steps {
+		fv := rv.Field(st.index)
+		switch {
+		case st.field != nil:
+			b.bindField(st.field, fv)
+		case !st.ptr:
+			b.bindStruct(fv, st.embedded)
+		case !fv.IsNil():
+			b.bindStruct(fv.Elem(), st.embedded)
+		case fv.CanSet():
+			// Nil embedded pointers are allocated only when one of
+			// their promoted fields receives a value.
+			nv := reflect.New(fv.Type().Elem())
+			n := b.assigned
+			b.bindStruct(nv.Elem(), st.embedded)
+			if b.assigned > n {
+				fv.Set(nv)
+			}
 		}
-		jsonMode := strings.EqualFold(f.Tag.Get("envjson"), "true")
+	}
+}
+
+// bindField resolves and sets a single tagged field.
+func (b *binder) bindField(p *fieldPlan, fv reflect.Value) {
+	b.fields++
+	if b.ctxErr != nil {
+		return
+	}
+	f, tag, def, jsonMode, fo := p.f, p.tag, p.def, p.jsonMode, p.fo
+	name, req := strings.Join(tag.names, "|"), tag.required
 
-		raw, exists := lookupPrefixed(prefix, name)
-		if !exists && def != "" {
-			raw = def
-			exists = true
+	for _, n := range tag.names {
+		b.known[n] = true
+		b.known[b.prefix+n] = true
+	}
+	secret := p.secret
+	if secret {
+		for _, n := range tag.names {
+			redact.MarkSecret(n, b.prefix+n)
 		}
-		if !exists && req {
-			errs = append(errs, missingErr(name))
-			continue
+	}
+	var raw string
+	var exists bool
+	alias := -1
+	for i, n := range tag.names {
+		var key string
+		if raw, key, exists = b.lookupPrefixed(n); exists {
+			name, alias = key, i
+			break
 		}
-		if !exists {
-			continue
+	}
+	if b.ctxErr != nil {
+		return
+	}
+	if exists {
+		types.RecordAccess(name, true)
+	} else {
+		types.RecordAccess(b.prefix+tag.names[0], false)
+		b.onMissing(b.prefix + tag.names[0])
+	}
+	fr := FieldReport{Field: f.Name, Key: name}
+	if exists {
+		fr.Source = sources.Origin(sources.Default(), name)
+	} else if def != "" {
+		raw = def
+		exists = true
+		fr.Source, fr.Default = "envdef", true
+		key := b.prefix + tag.names[0]
+		if secret || redact.IsSecretKey(key) {
+			b.onDefaultUsed(key, redact.Mask)
+		} else {
+			b.onDefaultUsed(key, def)
 		}
-		raw = expand.Expand(raw)
-
-		fv := rv.Field(i)
-		if !fv.CanSet() {
-			continue
+	} else if req && b.prompt != nil {
+		key := b.prefix + tag.names[0]
+		hint := missingHint(f, jsonMode, fo)
+		if raw, exists = b.prompt.ask(key, hint, secret || redact.IsSecretKey(key)); exists {
+			name = key
+			fr.Key, fr.Source = key, "prompt"
 		}
-		if err := setField(fv, raw, sep, jsonMode); err != nil {
-			errs = append(errs, fmt.Errorf("envvar: %s: %w", name, err))
-			continue
+	}
+	if b.report != nil {
+		defer func() { b.report.Fields = append(b.report.Fields, fr) }()
+	}
+	b.set[f.Name] = exists
+	if p.groupSet != "" {
+		key := name
+		if !exists || fr.Default {
+			key = b.prefix + tag.names[0]
 		}
+		b.groups = append(b.groups, groupField{set: p.groupSet, group: p.group,
+			key: key, explicit: exists && !fr.Default, has: exists})
 	}
-	if len(errs) > 0 {
-		return errs
+	fc := &fieldCtx{field: f.Name, key: name, rules: p.rules}
+	if fc.rules != "" {
+		b.cross = append(b.cross, fc)
+	}
+	if !exists && req {
+		b.fail(fc, ErrMissing, &KeyError{Key: name, Kind: ErrMissing,
+			Msg: missingHint(f, jsonMode, fo)})
+		return
+	}
+	if !exists {
+		return
+	}
+	if alias > 0 {
+		b.warn(fc, "set through fallback name; rename it to "+b.prefix+tag.names[0])
+	}
+	if fr.Default && req {
+		b.warn(fc, "required variable not set; using envdef default")
+	}
+	if p.hasDep && !fr.Default && fr.Source != "prompt" {
+		ke, removed := deprecated(name, p.dep, b.version)
+		if removed {
+			b.fail(fc, ErrDeprecated, ke)
+			return
+		}
+		b.onError(ke)
+		b.warn(fc, ke.Msg)
+	}
+	expanded, err := expand.ExpandWith(raw, sources.LookupOrEnv)
+	var ue *expand.UnsetError
+	if errors.As(err, &ue) {
+		b.fail(fc, ErrMissing, &KeyError{Key: ue.Name, Kind: ErrMissing,
+			Msg: ue.Msg + " (referenced by " + name + ")"})
+		return
+	}
+	if err != nil {
+		b.fail(fc, ErrRef, &KeyError{Key: name, Kind: ErrRef,
+			Msg: strings.TrimPrefix(err.Error(), "envvar: ")})
+		return
+	}
+	fr.Expanded = expanded != raw
+	fr.Value = expanded
+	secret = secret || redact.IsSecretKey(name)
+	if secret {
+		fr.Value = redact.Mask
+	}
+	fc.value = fr.Value
+	raw, err = resolveContext(b.ctx, name, expanded)
+	if err != nil && b.ctx.Err() != nil {
+		b.ctxErr = b.ctx.Err()
+		return
+	}
+	if err != nil {
+		b.fail(fc, err.(*KeyError).Kind, err)
+		return
+	}
+	if p.fileMode {
+		if raw, err = readValueFile(raw); err != nil {
+			b.fail(fc, 0, &FieldError{Field: fc.field, Key: name,
+				Rule: "file", Value: fc.value, Err: err})
+			return
+		}
+	}
+	if msg := types.CurrentLimits().CheckValue(raw); msg != "" {
+		b.fail(fc, ErrLimit, &KeyError{Key: name, Kind: ErrLimit, Msg: msg})
+		return
+	}
+	if secret {
+		redact.MarkSecretValue(raw)
+	}
+	if err := types.CheckPin(name, raw, true); err != nil {
+		b.fail(fc, ErrPin, err)
+		return
+	}
+
+	if !fv.CanSet() {
+		return
+	}
+	if jsonMode {
+		err = setFieldJSON(fv, raw)
+	} else {
+		err = p.setValue(fv, raw)
+	}
+	if err != nil {
+		want, _ := formatOf(f.Type, jsonMode, fo)
+		b.fail(fc, ErrType, &FieldError{Field: f.Name, Key: name, Rule: "type",
+			Value: fc.value, Want: want, Err: redactErr(err, raw, secret)})
+		return
+	}
+	b.assigned++
+	if b.report != nil && !jsonMode {
+		fr.Coerced = coercions(fv, raw, secret)
+	}
+	if !jsonMode && lenientBool(f.Type, raw) {
+		b.warn(fc, "lenient boolean "+strconv.Quote(fc.value)+" accepted; use true or false")
+	}
+	if fc.rules != "" {
+		if err := p.validate(fv); err != nil {
+			b.fail(fc, 0, fc.ruleError(redactErr(err, raw, secret)))
+		}
 	}
-	return nil
 }
 
-// lookupPrefixed looks up the prefixed name.
-func lookupPrefixed(prefix, name string) (string, bool) {
-	if prefix != "" {
-		if v, ok := os.LookupEnv(prefix + name); ok {
-			return v, true
+// ruleError wraps a validation failure of fc in a FieldError.
+func (fc *fieldCtx) ruleError(err error) *FieldError {
+	fe := &FieldError{Field: fc.field, Key: fc.key, Value: fc.value, Err: err}
+	var re *validate.RuleError
+	if errors.As(err, &re) {
+		fe.Rule, fe.Want = re.Rule, re.Param
+	}
+	return fe
+}
+
+// redactErr masks raw in the message of err when secret, keeping the
+// failed rule of validation errors. Parse and rule errors may quote the
+// input; this keeps secrets out of logs.
+func redactErr(err error, raw string, secret bool) error {
+	if !secret || raw == "" {
+		return err
+	}
+	masked := errors.New(strings.ReplaceAll(err.Error(), raw, redact.Mask))
+	if re, ok := err.(*validate.RuleError); ok {
+		return &validate.RuleError{Rule: re.Rule, Param: re.Param, Err: masked}
+	}
+	return masked
+}
+
+// lookupPrefixed looks up the prefixed name in the default source and
+// returns the value and the key that held it. A lookup abandoned
+// because ctx is done sets ctxErr and reports the name as unset.
+func (b *binder) lookupPrefixed(name string) (string, string, bool) {
+	keys := []string{name}
+	if b.prefix != "" {
+		keys = []string{b.prefix + name, name}
+	}
+	for _, k := range keys {
+		v, ok, err := sources.LookupContext(b.ctx, k)
+		if err != nil {
+			b.ctxErr = err
+			return "", "", false
+		}
+		if ok {
+			return v, k, true
 		}
 	}
-	return os.LookupEnv(name)
+	return "", name, false
+}
+
+// envTag is a parsed env tag.
+type envTag struct {
+	// names are the keys to try in order: "HTTP_PORT|PORT" reads
+	// HTTP_PORT and falls back to PORT.
+	names    []string
+	required bool
+	// file means the variable holds a path whose contents are the value.
+	file bool
 }
 
 // parseEnvTag parses the env tag.
-func parseEnvTag(tag string) (name string, required bool) {
-	name = tag
+func parseEnvTag(tag string) envTag {
+	var t envTag
+	name := tag
 	if i := strings.Index(tag, ","); i >= 0 {
 		name = tag[:i]
 		for _, part := range strings.Split(tag[i+1:], ",") {
-			if strings.TrimSpace(part) == "required" {
-				required = true
+			switch strings.TrimSpace(part) {
+			case "required":
+				t.required = true
+			case "file":
+				t.file = true
 			}
 		}
 	}
-	name = strings.TrimSpace(name)
-	return
+	for _, n := range strings.Split(name, "|") {
+		t.names = append(t.names, strings.TrimSpace(n))
+	}
+	return t
 }
 
-// setField sets the field.
-func setField(v reflect.Value, raw, sep string, jsonMode bool) error {
-	// If JSON mode is enabled, unmarshal into the field type.
-	if jsonMode {
-		return setFieldJSON(v, raw)
+// readValueFile reads a value from the file at path, trimming
+// surrounding whitespace, per the Docker/Kubernetes *_FILE convention.
+func readValueFile(path string) (string, error) {
+	b, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(string(b)), nil
+}
 
-	t := v.Type()
-	kind := t.Kind()
+// Parse parses raw into a T the way Bind populates a field of type T:
+// registered converters, strings, bools, integers, floats, durations,
+// byte sizes, times (RFC 3339), CIDRs, *url.URL, pointers, and
+// comma-separated slices.
+//
+// Parameters:
+//   - raw: The value to parse.
+//
+// Returns:
+//   - T: The parsed value.
+//   - error: The error if raw does not parse into T.
+func Parse[T any](raw string) (T, error) {
+	var out T
+	if err := setField(reflect.ValueOf(&out).Elem(), raw, fieldOpts{sep: ",", kvSep: "="}); err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
 
-	// Pointers
-	if kind == reflect.Ptr {
+// fieldOpts holds the tag options that affect parsing.
+type fieldOpts struct {
+	// sep separates slice elements and map pairs.
+	sep string
+	// kvSep separates a map key from its value.
+	kvSep string
+	// layout is the time.Time layout; empty means RFC 3339.
+	layout string
+}
+
+// setField sets the field.
+func setField(v reflect.Value, raw string, fo fieldOpts) error {
+	s := setterFor(v.Type(), fo)
+	return s.set(v, raw)
+}
+
+// setKind selects how a setter parses values.
+type setKind uint8
+
+const (
+	setFail setKind = iota
+	setConv
+	setURLPtr
+	setPtr
+	setTriBool
+	setDuration
+	setBytes
+	setPrefix
+	setIPNet
+	setTime
+	setString
+	setBool
+	setInt
+	setUint
+	setFloat
+	setSlice
+	setMap
+)
+
+// setter parses raw values into values of one type. It resolves
+// converters and the kind dispatch once: Bind builds one per field,
+// and Compiled keeps them in its plan.
+type setter struct {
+	kind setKind
+	t    reflect.Type
+	fo   fieldOpts
+	conv Converter
+	// elem sets pointer targets and slice elements.
+	elem *setter
+	// err is the error of setFail.
+	err error
+}
+
+// setterFor returns the setter for values of type t.
+func setterFor(t reflect.Type, fo fieldOpts) setter {
+	s := setter{t: t, fo: fo}
+	// Registered converters take precedence.
+	if conv, ok := converterFor(t); ok {
+		s.kind, s.conv = setConv, conv
+		return s
+	}
+	switch t {
+	case triBoolType:
+		s.kind = setTriBool
+		return s
+	case durationType:
+		s.kind = setDuration
+		return s
+	case bytesType:
+		s.kind = setBytes
+		return s
+	case prefixType:
+		s.kind = setPrefix
+		return s
+	case ipNetType:
+		s.kind = setIPNet
+		return s
+	case timeType:
+		s.kind = setTime
+		return s
+	}
+	switch kind := t.Kind(); kind {
+	case reflect.Ptr:
 		// Special-case *url.URL
 		if t.Elem().PkgPath() == "net/url" && t.Elem().Name() == "URL" {
-			u, err := url.Parse(raw)
-			if err != nil || u.Scheme == "" {
-				return fmt.Errorf("invalid url: %s", raw)
-			}
-			// Set the pointed value directly
-			elem := reflect.New(t.Elem())
-			elem.Elem().Set(reflect.ValueOf(*u))
-			v.Set(elem)
-			return nil
+			s.kind = setURLPtr
+			return s
 		}
-		elem := reflect.New(t.Elem())
-		if err := setField(elem.Elem(), raw, sep, false); err != nil {
-			return err
+		elem := setterFor(t.Elem(), fo)
+		s.kind, s.elem = setPtr, &elem
+	case reflect.String:
+		s.kind = setString
+	case reflect.Bool:
+		s.kind = setBool
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		s.kind = setInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		s.kind = setUint
+	case reflect.Float32, reflect.Float64:
+		s.kind = setFloat
+	case reflect.Slice:
+		_, hasConv := converterFor(t.Elem())
+		netElem := t.Elem() == prefixType || t.Elem() == ipNetType
+		if t.Elem().Kind() != reflect.String && !hasConv && !netElem {
+			s.err = fmt.Errorf("only []string slices supported")
+			return s
 		}
-		v.Set(elem)
-		return nil
+		elem := setterFor(t.Elem(), fo)
+		s.kind, s.elem = setSlice, &elem
+	case reflect.Map:
+		s.kind = setMap
+	case reflect.Struct:
+		// url.URL supported via pointer. Direct struct is awkward;
+		// keep a helpful error for clarity.
+		if t.PkgPath() == "net/url" && t.Name() == "URL" {
+			s.err = fmt.Errorf("use *url.URL in struct, not url.URL")
+		} else {
+			s.err = fmt.Errorf("unsupported struct type %s", t.String())
+		}
+	default:
+		s.err = fmt.Errorf("unsupported kind %s", kind)
 	}
+	return s
+}
 
-	switch kind {
-	case reflect.String:
+// set parses raw into v.
+func (s *setter) set(v reflect.Value, raw string) error {
+	switch s.kind {
+	case setConv:
+		return convert(v, raw, s.conv)
+	case setURLPtr:
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" {
+			return fmt.Errorf("invalid url: %s", raw)
+		}
+		v.Set(reflect.ValueOf(u))
+	case setPtr:
+		elem := reflect.New(s.t.Elem())
+		if err := s.elem.set(elem.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case setTriBool:
+		tb := types.TriUnset
+		if strings.TrimSpace(raw) != "" {
+			b, err := ParseBoolValue(raw)
+			if err != nil {
+				return err
+			}
+			tb = types.TriOf(b)
+		}
+		v.Set(reflect.ValueOf(tb))
+	case setDuration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %s", raw)
+		}
+		v.SetInt(int64(d))
+	case setBytes:
+		n, err := types.ParseBytes(raw)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case setPrefix:
+		p, err := netip.ParsePrefix(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid cidr: %s", raw)
+		}
+		v.Set(reflect.ValueOf(p))
+	case setIPNet:
+		_, n, err := net.ParseCIDR(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid cidr: %s", raw)
+		}
+		v.Set(reflect.ValueOf(*n))
+	case setTime:
+		tm, err := ParseTime(raw, s.fo.layout)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(tm))
+	case setString:
 		v.SetString(raw)
-		return nil
-	case reflect.Bool:
+	case setBool:
 		b, err := ParseBoolValue(raw)
 		if err != nil {
 			return err
 		}
 		v.SetBool(b)
-		return nil
-	case reflect.Int, reflect.Int8, reflect.Int16,
-		reflect.Int32, reflect.Int64:
-		// Special case time.Duration
-		if t.PkgPath() == "time" && t.Name() == "Duration" {
-			d, err := time.ParseDuration(raw)
-			if err != nil {
-				return fmt.Errorf("invalid duration: %s", raw)
-			}
-			v.SetInt(int64(d))
-			return nil
-		}
-		i, err := strconv.ParseInt(raw, 10, t.Bits())
+	case setInt:
+		i, err := strconv.ParseInt(raw, 10, s.t.Bits())
 		if err != nil {
 			return fmt.Errorf("invalid int: %s", raw)
 		}
 		v.SetInt(i)
-		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16,
-		reflect.Uint32, reflect.Uint64:
-		u, err := strconv.ParseUint(raw, 10, t.Bits())
+	case setUint:
+		u, err := strconv.ParseUint(raw, 10, s.t.Bits())
 		if err != nil {
 			return fmt.Errorf("invalid uint: %s", raw)
 		}
 		v.SetUint(u)
-		return nil
-	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(raw, t.Bits())
+	case setFloat:
+		f, err := strconv.ParseFloat(raw, s.t.Bits())
 		if err != nil {
 			return fmt.Errorf("invalid float: %s", raw)
 		}
 		v.SetFloat(f)
-		return nil
-	case reflect.Slice:
-		if t.Elem().Kind() != reflect.String {
-			return fmt.Errorf("only []string slices supported")
-		}
-		parts := SplitAndTrim(raw, sep)
-		sv := reflect.MakeSlice(t, len(parts), len(parts))
+	case setSlice:
+		parts := SplitAndTrim(raw, s.fo.sep)
+		sv := reflect.MakeSlice(s.t, len(parts), len(parts))
 		for i := range parts {
-			sv.Index(i).SetString(parts[i])
+			if err := s.elem.set(sv.Index(i), parts[i]); err != nil {
+				return err
+			}
 		}
 		v.Set(sv)
-		return nil
-	case reflect.Struct:
-		// url.URL supported via pointer. Direct struct is awkward;
-		// keep a helpful error for clarity.
-		if t.PkgPath() == "net/url" && t.Name() == "URL" {
-			return fmt.Errorf("use *url.URL in struct, not url.URL")
-		}
-		return fmt.Errorf("unsupported struct type %s", t.String())
+	case setMap:
+		return setMapValue(v, raw, s.fo)
 	default:
-		return fmt.Errorf("unsupported kind %s", kind)
+		return s.err
 	}
+	return nil
+}
+
+// setMapValue sets a map with string keys from "k1=v1,k2=v2" pairs. Values
+// are parsed like scalar fields.
+func setMapValue(v reflect.Value, raw string, fo fieldOpts) error {
+	t := v.Type()
+	switch t.Elem().Kind() {
+	case reflect.Slice, reflect.Map:
+		return fmt.Errorf("unsupported map value type %s", t.Elem())
+	}
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("only string map keys supported")
+	}
+	mv := reflect.MakeMap(t)
+	for _, p := range SplitAndTrim(raw, fo.sep) {
+		k, val, ok := strings.Cut(p, fo.kvSep)
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return fmt.Errorf("invalid pair %q", p)
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		if err := setField(ev, strings.TrimSpace(val), fo); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		mv.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+	}
+	v.Set(mv)
+	return nil
 }
 
 // setFieldJSON sets the field as JSON.
@@ -267,7 +817,43 @@ func setFieldJSON(v reflect.Value, raw string) error {
 	return nil
 }
 
-// ParseBoolValue parses a boolean value.
+var (
+	// timeType is the reflect type of time.Time.
+	timeType = reflect.TypeOf(time.Time{})
+	// durationType is the reflect type of time.Duration.
+	durationType = reflect.TypeOf(time.Duration(0))
+	// bytesType is the reflect type of types.Bytes.
+	bytesType = reflect.TypeOf(types.Bytes(0))
+	// prefixType is the reflect type of netip.Prefix.
+	prefixType = reflect.TypeOf(netip.Prefix{})
+	// ipNetType is the reflect type of net.IPNet.
+	ipNetType = reflect.TypeOf(net.IPNet{})
+	// triBoolType is the reflect type of types.TriBool.
+	triBoolType = reflect.TypeOf(types.TriUnset)
+)
+
+// ParseTime parses a time with layout, defaulting to RFC 3339.
+//
+// Parameters:
+//   - v: The value to parse.
+//   - layout: The time layout, or "" for time.RFC3339.
+//
+// Returns:
+//   - time.Time: The time.
+//   - error: The error if the parsing fails.
+func ParseTime(v, layout string) (time.Time, error) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, strings.TrimSpace(v))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s (layout %s)", v, layout)
+	}
+	return t, nil
+}
+
+// ParseBoolValue parses a boolean value. Under the prod profile only
+// true, false, 1, and 0 are accepted.
 //
 // Parameters:
 //   - v: The value to parse.
@@ -276,15 +862,11 @@ func setFieldJSON(v reflect.Value, raw string) error {
 //   - bool: The boolean value.
 //   - error: The error if the parsing fails.
 func ParseBoolValue(v string) (bool, error) {
-	s := strings.TrimSpace(strings.ToLower(v))
-	switch s {
-	case "1", "t", "true", "y", "yes", "on":
-		return true, nil
-	case "0", "f", "false", "n", "no", "off":
-		return false, nil
-	default:
+	b, ok := types.ParseBool(v)
+	if !ok {
 		return false, fmt.Errorf("invalid boolean: %s", v)
 	}
+	return b, nil
 }
 
 // SplitAndTrim splits a string into a slice of strings and trims each string.
@@ -307,7 +889,16 @@ func SplitAndTrim(s, sep string) []string {
 	return out
 }
 
-// missingErr returns a missing error.
-func missingErr(key string) error {
-	return &KeyError{Key: key, Kind: ErrMissing}
+// resolveContext resolves references, honoring ctx, and then decrypts
+// encrypted values.
+func resolveContext(ctx context.Context, key, v string) (string, error) {
+	v, err := refs.ValueContext(ctx, v)
+	if err != nil {
+		return "", &KeyError{Key: key, Kind: ErrRef, Msg: err.Error()}
+	}
+	v, err = decrypt.Value(v)
+	if err != nil {
+		return "", &KeyError{Key: key, Kind: ErrDecrypt, Msg: err.Error()}
+	}
+	return v, nil
 }
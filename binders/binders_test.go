@@ -1,10 +1,26 @@
 package binders
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/sources"
+	"github.com/aatuh/envvar/v2/types"
+	"github.com/aatuh/envvar/v2/validate"
 )
 
 func TestBindBasic(t *testing.T) {
@@ -84,3 +100,1266 @@ func TestBindPrefix(t *testing.T) {
 		t.Fatalf("Mode should be production, got %v", c.Mode)
 	}
 }
+
+type HTTPConfig struct {
+	Addr    string        `env:"HTTP_ADDR" envdef:":8080"`
+	Timeout time.Duration `env:"HTTP_TIMEOUT,required"`
+}
+
+type dbConfig struct {
+	DSN string `env:"DB_DSN,required"`
+}
+
+func TestBindEmbedded(t *testing.T) {
+	type C struct {
+		HTTPConfig
+		dbConfig
+		*LogConfig
+		Name string `env:"APP_NAME"`
+	}
+	t.Setenv("HTTP_TIMEOUT", "2s")
+	t.Setenv("DB_DSN", "postgres://x")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("APP_NAME", "svc")
+
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if c.Addr != ":8080" || c.Timeout != 2*time.Second {
+		t.Fatalf("embedded HTTPConfig: %+v", c.HTTPConfig)
+	}
+	if c.DSN != "postgres://x" {
+		t.Fatalf("unexported embedded: %+v", c.dbConfig)
+	}
+	if c.LogConfig == nil || c.Level != "debug" {
+		t.Fatalf("embedded pointer: %+v", c.LogConfig)
+	}
+	if c.Name != "svc" {
+		t.Fatalf("Name: %q", c.Name)
+	}
+}
+
+type LogConfig struct {
+	Level string `env:"LOG_LEVEL"`
+}
+
+type node struct {
+	*node
+	X string `env:"NODE_X"`
+}
+
+func TestBindEmbeddedCycle(t *testing.T) {
+	t.Setenv("NODE_X", "x")
+	n := node{}
+	if err := Bind(&n); err != nil || n.X != "x" || n.node != nil {
+		t.Fatalf("Bind = %+v, %v", n, err)
+	}
+	n.node = &n
+	if m, err := EnvMap(&n); err != nil || len(m) != 1 || m["NODE_X"] != "x" {
+		t.Fatalf("EnvMap = %v, %v", m, err)
+	}
+	if _, err := MarshalJSON(&n, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Completion(&n, "bash", "app"); err != nil {
+		t.Fatal(err)
+	}
+	if SchemaFingerprint(&n) == "" {
+		t.Fatal("empty fingerprint")
+	}
+}
+
+func TestBindEmbeddedPointerUnset(t *testing.T) {
+	type Limits struct {
+		Min int `env:"EPU_MIN" validate:"ltefield=Max"`
+		Max int `env:"EPU_MAX"`
+	}
+	type C struct {
+		*Limits
+		*LogConfig
+	}
+	t.Setenv("LOG_LEVEL", "info")
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if c.Limits != nil {
+		t.Fatalf("allocated without values: %+v", c.Limits)
+	}
+	if c.LogConfig == nil || c.Level != "info" {
+		t.Fatalf("LogConfig: %+v", c.LogConfig)
+	}
+}
+
+type level int
+
+func TestRegisterConverter(t *testing.T) {
+	RegisterConverterFor(func(raw string) (level, error) {
+		switch raw {
+		case "debug":
+			return 0, nil
+		case "info":
+			return 1, nil
+		}
+		return 0, errors.New("bad level " + raw)
+	})
+	defer RegisterConverter(reflect.TypeOf(level(0)), nil)
+
+	type C struct {
+		Level  level   `env:"CONV_LEVEL"`
+		PLevel *level  `env:"CONV_LEVEL"`
+		Levels []level `env:"CONV_LEVELS"`
+	}
+	t.Setenv("CONV_LEVEL", "info")
+	t.Setenv("CONV_LEVELS", "debug,info")
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if c.Level != 1 || c.PLevel == nil || *c.PLevel != 1 {
+		t.Fatalf("level: %+v", c)
+	}
+	if len(c.Levels) != 2 || c.Levels[0] != 0 || c.Levels[1] != 1 {
+		t.Fatalf("levels: %v", c.Levels)
+	}
+
+	t.Setenv("CONV_LEVEL", "loud")
+	if err := Bind(&c); err == nil || !strings.Contains(err.Error(), "bad level") {
+		t.Fatalf("want converter error, got %v", err)
+	}
+}
+
+func TestBindFileMode(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "db_password")
+	if err := os.WriteFile(p, []byte("  s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	type C struct {
+		Pass  string `env:"DB_PASSWORD_FILE,file,required"`
+		Token string `env:"TOKEN_FILE" envfile:"true"`
+		Miss  string `env:"MISSING_FILE,file"`
+	}
+	t.Setenv("DB_PASSWORD_FILE", p)
+	t.Setenv("TOKEN_FILE", p)
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if c.Pass != "s3cr3t" || c.Token != "s3cr3t" || c.Miss != "" {
+		t.Fatalf("file mode: %+v", c)
+	}
+	t.Setenv("TOKEN_FILE", filepath.Join(dir, "nope"))
+	if err := Bind(&c); err == nil || !strings.Contains(err.Error(), "TOKEN_FILE") {
+		t.Fatalf("want read error, got %v", err)
+	}
+}
+
+func TestBindAndWatch(t *testing.T) {
+	type C struct {
+		Level string `env:"WATCH_LEVEL" envdef:"info"`
+		Rate  int    `env:"WATCH_RATE,required"`
+	}
+	p := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(p, []byte("WATCH_RATE=10\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WATCH_LEVEL", "")
+	os.Unsetenv("WATCH_LEVEL")
+	t.Setenv("WATCH_RATE", "10")
+
+	var c C
+	var gotOld, gotNew C
+	w, err := BindAndWatch(&c, WatchOptions[C]{
+		Files:    []string{p},
+		Interval: time.Hour,
+		OnChange: func(old, new *C) { gotOld, gotNew = *old, *new },
+	})
+	if err != nil {
+		t.Fatalf("BindAndWatch: %v", err)
+	}
+	defer w.Stop()
+	if c.Rate != 10 || c.Level != "info" {
+		t.Fatalf("initial bind: %+v", c)
+	}
+
+	if err := os.WriteFile(p, []byte("WATCH_RATE=20\nWATCH_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := w.Refresh()
+	if err != nil || !changed {
+		t.Fatalf("Refresh = %v, %v", changed, err)
+	}
+	if gotOld.Rate != 10 || gotNew.Rate != 20 || gotNew.Level != "debug" {
+		t.Fatalf("callback old=%+v new=%+v", gotOld, gotNew)
+	}
+	if cur := w.Current(); cur.Rate != 20 {
+		t.Fatalf("Current = %+v", cur)
+	}
+
+	if err := os.WriteFile(p, []byte("WATCH_RATE=oops\nWATCH_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Refresh(); err == nil {
+		t.Fatalf("want bind error")
+	}
+	if cur := w.Current(); cur.Rate != 20 {
+		t.Fatalf("failed re-bind replaced value: %+v", cur)
+	}
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	type A struct {
+		Port int    `env:"PORT,required"`
+		Host string `env:"HOST" envdef:"localhost"`
+	}
+	type Reordered struct {
+		Hostname string `env:"HOST" envdef:"localhost" envdesc:"ignored"`
+		P        int    `env:"PORT,required"`
+	}
+	type Changed struct {
+		Port int64  `env:"PORT,required"`
+		Host string `env:"HOST" envdef:"localhost"`
+	}
+	fp := SchemaFingerprint(&A{})
+	if !strings.HasPrefix(fp, "sha256:") {
+		t.Fatalf("fingerprint = %s", fp)
+	}
+	if got := SchemaFingerprint(Reordered{}); got != fp {
+		t.Fatalf("reordered fingerprint differs: %s != %s", got, fp)
+	}
+	if got := SchemaFingerprint(Changed{}); got == fp {
+		t.Fatalf("type change not detected")
+	}
+
+	if err := CheckSchema(A{}, fp+"\n"); err != nil {
+		t.Fatalf("CheckSchema: %v", err)
+	}
+	t.Setenv(SchemaKey, SchemaFingerprint(Changed{}))
+	if err := CheckDeployedSchema(A{}); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("want ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestBindTime(t *testing.T) {
+	type C struct {
+		Start  time.Time  `env:"BIND_START"`
+		Cutoff time.Time  `env:"BIND_CUTOFF" envlayout:"2006-01-02"`
+		Opt    *time.Time `env:"BIND_OPT" envdef:"2020-01-01T00:00:00Z"`
+	}
+	t.Setenv("BIND_START", "2024-03-01T12:30:00+02:00")
+	t.Setenv("BIND_CUTOFF", "2024-12-31")
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if c.Start.Hour() != 12 || c.Cutoff.Day() != 31 || c.Opt == nil || c.Opt.Year() != 2020 {
+		t.Fatalf("bound %+v", c)
+	}
+	t.Setenv("BIND_CUTOFF", "31.12.2024")
+	if err := Bind(&c); err == nil {
+		t.Fatalf("want layout error")
+	}
+}
+
+func TestBindBytes(t *testing.T) {
+	type C struct {
+		Limit  types.Bytes `env:"BIND_MEM_LIMIT"`
+		Buffer types.Bytes `env:"BIND_BUFFER" envdef:"64KiB"`
+	}
+	t.Setenv("BIND_MEM_LIMIT", "512MB")
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if c.Limit != 512e6 || c.Buffer != 64<<10 {
+		t.Fatalf("bound %+v", c)
+	}
+	t.Setenv("BIND_MEM_LIMIT", "lots")
+	if err := Bind(&c); err == nil {
+		t.Fatalf("want byte size error")
+	}
+}
+
+func TestBindValueSizeLimit(t *testing.T) {
+	type C struct {
+		Blob map[string]any `env:"BIND_BLOB" envjson:"true"`
+	}
+	types.SetLimits(types.Limits{MaxValueSize: 16})
+	defer types.SetLimits(types.Limits{})
+	t.Setenv("BIND_BLOB", `{"a":"`+strings.Repeat("x", 64)+`"}`)
+	var c C
+	err := Bind(&c)
+	var me MultiError
+	var ke *KeyError
+	if !errors.As(err, &me) || !errors.As(me[0], &ke) || ke.Kind != ErrLimit {
+		t.Fatalf("want ErrLimit, got %v", err)
+	}
+	if c.Blob != nil {
+		t.Fatalf("oversized value decoded")
+	}
+}
+
+func TestBindCIDR(t *testing.T) {
+	type C struct {
+		Trusted []netip.Prefix `env:"BIND_TRUSTED"`
+		Allowed *net.IPNet     `env:"BIND_ALLOWED"`
+		Net     net.IPNet      `env:"BIND_NET" envdef:"192.168.0.0/16"`
+	}
+	t.Setenv("BIND_TRUSTED", "10.0.0.0/8, fd00::/8")
+	t.Setenv("BIND_ALLOWED", "172.16.0.0/12")
+	var c C
+	if err := Bind(&c); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if len(c.Trusted) != 2 || !c.Trusted[1].Addr().Is6() {
+		t.Fatalf("Trusted = %v", c.Trusted)
+	}
+	if c.Allowed == nil || c.Allowed.String() != "172.16.0.0/12" || c.Net.String() != "192.168.0.0/16" {
+		t.Fatalf("bound %+v", c)
+	}
+	t.Setenv("BIND_ALLOWED", "172.16.0.0")
+	if err := Bind(&c); err == nil {
+		t.Fatalf("want cidr error")
+	}
+}
+
+func TestMissingHint(t *testing.T) {
+	var cfg struct {
+		Port    int           `env:"HINT_PORT,required" envdesc:"HTTP listen port" envexample:"8080"`
+		Timeout time.Duration `env:"HINT_TIMEOUT,required"`
+	}
+	err := Bind(&cfg)
+	if err == nil {
+		t.Fatal("want error")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		`missing HINT_PORT: HTTP listen port; expected integer, e.g. "8080"`,
+		`missing HINT_TIMEOUT: expected duration, e.g. "30s"`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q lacks %q", msg, want)
+		}
+	}
+}
+
+func TestBindMap(t *testing.T) {
+	t.Setenv("MAP_LIMITS", "api=10, web = 20")
+	t.Setenv("MAP_LABELS", "team:core;tier:1")
+	t.Setenv("MAP_BAD", "api")
+	var cfg struct {
+		Limits map[string]int    `env:"MAP_LIMITS"`
+		Labels map[string]string `env:"MAP_LABELS" envsep:";" envkvsep:":"`
+	}
+	if err := Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Limits, map[string]int{"api": 10, "web": 20}) {
+		t.Fatalf("Limits = %v", cfg.Limits)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"team": "core", "tier": "1"}) {
+		t.Fatalf("Labels = %v", cfg.Labels)
+	}
+	var bad struct {
+		M map[string]int `env:"MAP_BAD"`
+	}
+	if err := Bind(&bad); err == nil {
+		t.Fatal("want invalid pair error")
+	}
+}
+
+func TestBindAliases(t *testing.T) {
+	t.Setenv("ALIAS_OLD_PORT", "8080")
+	var cfg struct {
+		Port int    `env:"ALIAS_PORT|ALIAS_OLD_PORT"`
+		Host string `env:"ALIAS_HOST|ALIAS_OLD_HOST,required"`
+	}
+	err := Bind(&cfg)
+	if cfg.Port != 8080 {
+		t.Fatalf("Port = %d", cfg.Port)
+	}
+	if err == nil || !strings.Contains(err.Error(), "missing ALIAS_HOST|ALIAS_OLD_HOST") {
+		t.Fatalf("err = %v", err)
+	}
+	t.Setenv("ALIAS_PORT", "9090")
+	t.Setenv("ALIAS_OLD_HOST", "db")
+	if err := Bind(&cfg); err != nil || cfg.Port != 9090 || cfg.Host != "db" {
+		t.Fatalf("cfg = %+v, %v", cfg, err)
+	}
+}
+
+func TestBindTriBool(t *testing.T) {
+	t.Setenv("TRI_CACHE", "yes")
+	var cfg struct {
+		Cache   types.TriBool `env:"TRI_CACHE"`
+		Metrics types.TriBool `env:"TRI_METRICS"`
+	}
+	if err := Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cache != types.TriTrue || cfg.Metrics != types.TriUnset {
+		t.Fatalf("cfg = %v, %v", cfg.Cache, cfg.Metrics)
+	}
+	if !cfg.Metrics.Or(true) {
+		t.Fatal("Or should fall back to the default when unset")
+	}
+}
+
+type recordingHook struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (h *recordingHook) OnLoad(string, int)                       {}
+func (h *recordingHook) OnGet(string, bool, error, time.Duration) {}
+func (h *recordingHook) OnError(_ string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+func TestBindPrefixStrict(t *testing.T) {
+	type C struct {
+		Port int `env:"PORT" envdef:"80"`
+	}
+	t.Setenv("STRICT_PROT", "8080")
+	t.Setenv("STRICT_PORT", "9090")
+
+	var c C
+	if err := BindWithPrefix(&c, "STRICT_"); err != nil {
+		t.Fatalf("non-strict bind failed: %v", err)
+	}
+	err := BindWithPrefix(&c, "STRICT_", WithStrict())
+	me, ok := err.(MultiError)
+	if !ok || len(me) != 1 {
+		t.Fatalf("want one error, got %v", err)
+	}
+	var ke *KeyError
+	if !errors.As(me[0], &ke) || ke.Kind != ErrUnknown || ke.Key != "STRICT_PROT" {
+		t.Fatalf("want unknown STRICT_PROT, got %v", err)
+	}
+
+	h := &recordingHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+	if err := BindWithPrefix(&c, "STRICT_", WithStrictWarn()); err != nil {
+		t.Fatalf("warn mode should not fail: %v", err)
+	}
+	if len(h.errs) != 1 || !strings.Contains(h.errs[0].Error(), "unknown variable STRICT_PROT") {
+		t.Fatalf("hook errs = %v", h.errs)
+	}
+}
+
+func TestBindReport(t *testing.T) {
+	t.Setenv("REP_HOST", "db")
+	prev := sources.Default()
+	defer sources.SetDefault(prev)
+	sources.SetDefault(sources.Chain(
+		sources.Named("vault", sources.Map{"REP_DB_PASSWORD": "hunter2"}),
+		sources.Named("env", sources.Map{"REP_URL": "postgres://${REP_HOST}"}),
+	))
+	var cfg struct {
+		Password string        `env:"REP_DB_PASSWORD"`
+		URL      string        `env:"REP_URL"`
+		Timeout  time.Duration `env:"REP_TIMEOUT" envdef:"5s"`
+		Debug    bool          `env:"REP_DEBUG"`
+	}
+	var rep Report
+	if err := Bind(&cfg, WithReport(&rep)); err != nil {
+		t.Fatal(err)
+	}
+	want := []FieldReport{
+		{Field: "Password", Key: "REP_DB_PASSWORD", Source: "vault", Value: "***"},
+		{Field: "URL", Key: "REP_URL", Source: "env", Value: "postgres://db", Expanded: true},
+		{Field: "Timeout", Key: "REP_TIMEOUT", Source: "envdef", Value: "5s", Default: true},
+		{Field: "Debug", Key: "REP_DEBUG"},
+	}
+	if !reflect.DeepEqual(rep.Fields, want) {
+		t.Fatalf("report = %+v", rep.Fields)
+	}
+	if !strings.Contains(rep.String(), "Debug: REP_DEBUG from unset") {
+		t.Fatalf("String() = %q", rep.String())
+	}
+}
+
+func TestMarshalConfig(t *testing.T) {
+	u, _ := url.Parse("postgres://app:pw@db/app")
+	cfg := struct {
+		Port     int            `env:"PORT"`
+		Timeout  time.Duration  `env:"TIMEOUT"`
+		Token    string         `env:"API_TOKEN"`
+		DB       *url.URL       `env:"DB_URL"`
+		Hosts    []string       `env:"HOSTS"`
+		Limits   map[string]int `env:"LIMITS"`
+		Debug    types.TriBool  `env:"DEBUG"`
+		internal string         `env:"INTERNAL"`
+	}{Port: 8080, Timeout: 5 * time.Second, Token: "t0k", DB: u,
+		Hosts: []string{"a", "b"}, Limits: map[string]int{"api": 10}, internal: "x"}
+
+	js, err := MarshalJSON(&cfg, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(js, &doc); err != nil {
+		t.Fatalf("invalid JSON %s: %v", js, err)
+	}
+	want := map[string]any{
+		"PORT": 8080.0, "TIMEOUT": "5s", "API_TOKEN": "***",
+		"DB_URL": "postgres://app:xxxxx@db/app", "HOSTS": []any{"a", "b"},
+		"LIMITS": map[string]any{"api": 10.0}, "DEBUG": nil,
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Fatalf("doc = %v", doc)
+	}
+
+	y, err := MarshalYAML(cfg, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{`API_TOKEN: "t0k"`, `HOSTS: ["a","b"]`, `PORT: 8080`} {
+		if !strings.Contains(string(y), line+"\n") {
+			t.Errorf("YAML lacks %q:\n%s", line, y)
+		}
+	}
+	if _, err := MarshalJSON(42, false); err == nil {
+		t.Fatal("want error for non-struct")
+	}
+}
+
+func TestBindSecretTag(t *testing.T) {
+	t.Setenv("TAGGED_DSN", "postgres://u:hunter2@db")
+	t.Setenv("TAGGED_PIN", "hunter2")
+	var cfg struct {
+		DSN string `env:"TAGGED_DSN" secret:"true"`
+		PIN int    `env:"TAGGED_PIN" secret:"true"`
+	}
+	var rep Report
+	err := Bind(&cfg, WithReport(&rep))
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("error must fail without leaking the value: %v", err)
+	}
+	if rep.Fields[0].Value != redact.Mask {
+		t.Fatalf("report leaks %q", rep.Fields[0].Value)
+	}
+	if !redact.IsSecretKey("TAGGED_DSN") {
+		t.Fatal("bound secret key should be marked")
+	}
+	doc, err := MarshalJSON(&cfg, true)
+	if err != nil || strings.Contains(string(doc), "hunter2") {
+		t.Fatalf("document leaks: %s, %v", doc, err)
+	}
+}
+
+func TestBindValidate(t *testing.T) {
+	t.Setenv("VAL_PORT", "0")
+	t.Setenv("VAL_LEVEL", "info")
+	var cfg struct {
+		Port  int    `env:"VAL_PORT" validate:"min=1,max=65535"`
+		Level string `env:"VAL_LEVEL" validate:"oneof=debug|info"`
+		Unset int    `env:"VAL_UNSET" validate:"min=1"`
+	}
+	err := Bind(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "envvar: VAL_PORT: must be >= 1") {
+		t.Fatalf("err = %v", err)
+	}
+	if strings.Contains(err.Error(), "VAL_LEVEL") || strings.Contains(err.Error(), "VAL_UNSET") {
+		t.Fatalf("only VAL_PORT should fail: %v", err)
+	}
+}
+
+func TestBindCrossField(t *testing.T) {
+	t.Setenv("XF_TLS_CERT", "/etc/tls.crt")
+	t.Setenv("XF_PASS", "pw")
+	t.Setenv("XF_PASS2", "pw")
+	t.Setenv("XF_MIN", "20")
+	var cfg struct {
+		TLSCert string `env:"XF_TLS_CERT"`
+		TLSKey  string `env:"XF_TLS_KEY" validate:"required_with=TLSCert"`
+		Pass    string `env:"XF_PASS" validate:"eqfield=Pass2"`
+		Pass2   string `env:"XF_PASS2"`
+		MinConn int    `env:"XF_MIN" validate:"ltefield=MaxConn"`
+		MaxConn int    `env:"XF_MAX" envdef:"10"`
+		URL     string `env:"XF_URL" validate:"required_without=Host"`
+		Host    string `env:"XF_HOST"`
+	}
+	err := Bind(&cfg)
+	var me MultiError
+	if !errors.As(err, &me) || len(me) != 3 {
+		t.Fatalf("want 3 errors, got %v", err)
+	}
+	if got := me[2].Error(); got != "envvar: missing XF_URL: required when Host is not set" {
+		t.Fatalf("required_without: %s", got)
+	}
+	if got := me[0].Error(); got != "envvar: missing XF_TLS_KEY: required when TLSCert is set" {
+		t.Fatalf("required_with: %s", got)
+	}
+	if got := me[1].Error(); got != "envvar: XF_MIN: must be <= MaxConn" {
+		t.Fatalf("ltefield: %s", got)
+	}
+}
+
+func TestBindFieldError(t *testing.T) {
+	t.Setenv("FE_PORT", "0")
+	t.Setenv("FE_WORKERS", "many")
+	var cfg struct {
+		Port    int `env:"FE_PORT" validate:"min=1"`
+		Workers int `env:"FE_WORKERS"`
+	}
+	var me MultiError
+	if !errors.As(Bind(&cfg), &me) || len(me) != 2 {
+		t.Fatalf("want 2 errors, got %v", me)
+	}
+	var fe *FieldError
+	if !errors.As(me[0], &fe) || fe.Field != "Port" || fe.Key != "FE_PORT" ||
+		fe.Rule != "min" || fe.Value != "0" || fe.Want != "1" {
+		t.Fatalf("validation FieldError: %+v", fe)
+	}
+	if got := me[0].Error(); got != "envvar: FE_PORT: must be >= 1" {
+		t.Fatalf("message: %s", got)
+	}
+	if !errors.As(me[1], &fe) || fe.Rule != "type" || fe.Want != "integer" {
+		t.Fatalf("type FieldError: %+v", fe)
+	}
+	b, err := json.Marshal(fe)
+	if err != nil || !strings.Contains(string(b), `"rule":"type"`) {
+		t.Fatalf("json: %s %v", b, err)
+	}
+}
+
+func TestBindErrorSentinels(t *testing.T) {
+	t.Setenv("SEN_PORT", "0")
+	t.Setenv("SEN_WORKERS", "many")
+	var cfg struct {
+		Host    string `env:"SEN_HOST,required"`
+		Port    int    `env:"SEN_PORT" validate:"min=1"`
+		Workers int    `env:"SEN_WORKERS"`
+	}
+	err := Bind(&cfg)
+	for _, target := range []error{ErrMissingVar, ErrValidation, ErrTypeMismatch} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(err, %v) = false", target)
+		}
+	}
+	var ke *KeyError
+	if !errors.As(err, &ke) || ke.Key != "SEN_HOST" {
+		t.Fatalf("errors.As must reach into MultiError: %v", err)
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Key != "SEN_PORT" {
+		t.Fatalf("first FieldError: %+v", fe)
+	}
+
+	t.Setenv("SEN_HOST", "db")
+	t.Setenv("SEN_PORT", "80")
+	t.Setenv("SEN_WORKERS", "4")
+	if err := Bind(&cfg); errors.Is(err, ErrMissingVar) {
+		t.Fatalf("unexpected match: %v", err)
+	}
+}
+
+func TestBindExpansionRequired(t *testing.T) {
+	t.Setenv("EXR_DSN", "postgres://${EXR_HOST:?set EXR_HOST}/app")
+	var cfg struct {
+		DSN string `env:"EXR_DSN"`
+	}
+	err := Bind(&cfg)
+	if !errors.Is(err, ErrMissingVar) ||
+		!strings.Contains(err.Error(), "missing EXR_HOST: set EXR_HOST (referenced by EXR_DSN)") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestEnvMapRoundTrip(t *testing.T) {
+	type C struct {
+		Port    int               `env:"RT_PORT"`
+		Hosts   []string          `env:"RT_HOSTS" envsep:";"`
+		Limits  map[string]string `env:"RT_LIMITS"`
+		Timeout time.Duration     `env:"RT_TIMEOUT"`
+		Day     time.Time         `env:"RT_DAY" envlayout:"2006-01-02"`
+		Token   string            `env:"RT_TAGGED" secret:"true"`
+		Opt     *int              `env:"RT_OPT"`
+	}
+	in := C{Port: 80, Hosts: []string{"a", "b"}, Limits: map[string]string{"x": "1", "y": "2"},
+		Timeout: 1500 * time.Millisecond, Day: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), Token: "t"}
+	m, err := EnvMap(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"RT_PORT": "80", "RT_HOSTS": "a;b", "RT_LIMITS": "x=1,y=2",
+		"RT_TIMEOUT": "1.5s", "RT_DAY": "2024-01-31", "RT_TAGGED": "t"}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("EnvMap = %v", m)
+	}
+	if !redact.IsSecretKey("RT_TAGGED") {
+		t.Fatal("secret-tagged keys must be registered")
+	}
+	for k, v := range m {
+		t.Setenv(k, v)
+	}
+	var out C
+	if err := Bind(&out); err != nil || !reflect.DeepEqual(out, in) {
+		t.Fatalf("round trip: %+v %v", out, err)
+	}
+}
+
+func TestBindMessages(t *testing.T) {
+	if err := SetRuleMessage("min", "{{.Field}} must be between {{.Min}} and {{.Max}}, got {{.Value}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetKindMessage(ErrMissing, "please set {{.Key}}"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetRuleMessage("min", "")
+	defer SetKindMessage(ErrMissing, "")
+	if SetRuleMessage("max", "{{.Field") == nil {
+		t.Fatal("want template parse error")
+	}
+
+	t.Setenv("MSG_PORT", "0")
+	var cfg struct {
+		Port int    `env:"MSG_PORT" validate:"min=1,max=65535"`
+		Host string `env:"MSG_HOST,required"`
+	}
+	err := Bind(&cfg)
+	var me MultiError
+	if !errors.As(err, &me) || len(me) != 2 {
+		t.Fatalf("want 2 errors, got %v", err)
+	}
+	if got := me[0].Error(); got != "Port must be between 1 and 65535, got 0" {
+		t.Fatalf("rule message: %s", got)
+	}
+	if got := me[1].Error(); got != "please set MSG_HOST" {
+		t.Fatalf("kind message: %s", got)
+	}
+	var ke *KeyError
+	if !errors.As(me[1], &ke) || ke.Kind != ErrMissing {
+		t.Fatalf("message errors must wrap the original: %v", me[1])
+	}
+}
+
+func TestBindPrompt(t *testing.T) {
+	var cfg struct {
+		Host  string `env:"PR_HOST,required"`
+		Token string `env:"PR_TOKEN,required" secret:"true"`
+		Port  int    `env:"PR_PORT" envdef:"80"`
+	}
+	var out bytes.Buffer
+	var hidden int
+	save := filepath.Join(t.TempDir(), ".env")
+	err := Bind(&cfg, WithPrompt(Prompt{
+		In:         strings.NewReader("db.local\n"),
+		Out:        &out,
+		ReadSecret: func() (string, error) { hidden++; return "s3cret", nil },
+		SaveTo:     save,
+	}))
+	if err != nil || cfg.Host != "db.local" || cfg.Token != "s3cret" || hidden != 1 {
+		t.Fatalf("cfg = %+v, hidden = %d, err = %v", cfg, hidden, err)
+	}
+	if !strings.Contains(out.String(), "PR_HOST (") || strings.Contains(out.String(), "PR_PORT") {
+		t.Fatalf("prompts = %q", out.String())
+	}
+	data, err := os.ReadFile(save)
+	if err != nil || !strings.Contains(string(data), "PR_HOST=db.local") {
+		t.Fatalf("saved = %q, %v", data, err)
+	}
+
+	// An empty answer leaves the variable missing.
+	var cfg2 struct {
+		Host string `env:"PR_HOST2,required"`
+	}
+	err = Bind(&cfg2, WithPrompt(Prompt{In: strings.NewReader("\n"), Out: io.Discard}))
+	if !errors.Is(err, ErrMissingVar) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	type C struct {
+		Level string `env:"CMP_LEVEL" validate:"oneof=debug|info|warn"`
+		Debug bool   `env:"CMP_DEBUG"`
+		Port  int    `env:"CMP_PORT" envdesc:"HTTP listen port"`
+	}
+	for shell, want := range map[string][]string{
+		"bash": {"CMP_LEVEL=*) vals='debug info warn' ;;", `'CMP_DEBUG= CMP_LEVEL= CMP_PORT='`, "complete -F _my_app_envvar 'my-app'"},
+		"zsh":  {`CMP_DEBUG) compadd -- 'true' 'false'`, `'CMP_PORT:HTTP listen port'`, "compdef _my_app_envvar 'my-app'"},
+		"fish": {`-a 'CMP_LEVEL=debug CMP_LEVEL=info CMP_LEVEL=warn'`, `-a 'CMP_PORT=' -d 'HTTP listen port'`},
+	} {
+		out, err := Completion(&C{}, shell, "my-app")
+		if err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(string(out), w) {
+				t.Fatalf("%s: missing %q in\n%s", shell, w, out)
+			}
+		}
+	}
+	if _, err := Completion(&C{}, "tcsh", "x"); err == nil {
+		t.Fatal("unsupported shell must fail")
+	}
+}
+
+func TestBindDeprecated(t *testing.T) {
+	type C struct {
+		Old  string `env:"DEP_OLD" envdeprecated:"since=1.4,remove=2.0,use=DEP_NEW"`
+		Gone string `env:"DEP_GONE" envdeprecated:"since=1.0" envdef:"x"`
+	}
+	t.Setenv("DEP_OLD", "v")
+	h := &recordingHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+	var c C
+	if err := Bind(&c, WithVersion("1.9.3")); err != nil || c.Old != "v" {
+		t.Fatalf("c = %+v, err = %v", c, err)
+	}
+	if len(h.errs) != 1 || h.errs[0].Error() !=
+		"envvar: variable DEP_OLD: deprecated since 1.4, to be removed in 2.0; use DEP_NEW" {
+		t.Fatalf("warnings = %v", h.errs)
+	}
+	err := Bind(&c, WithVersion("v2.0.0-rc1"))
+	var ke *KeyError
+	if !errors.As(err, &ke) || ke.Kind != ErrDeprecated ||
+		!strings.Contains(ke.Msg, "removed in 2.0") {
+		t.Fatalf("err = %v", err)
+	}
+	if compareVersions("1.10", "1.9") <= 0 || compareVersions("2", "2.0.0") != 0 {
+		t.Fatal("compareVersions")
+	}
+}
+
+func TestBindMarksSecretValues(t *testing.T) {
+	t.Setenv("SV_DSN", "postgres://u:pa55word@db/app")
+	var cfg struct {
+		DSN string `env:"SV_DSN" secret:"true"`
+	}
+	if err := Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	w := redact.NewWriter(&out)
+	w.Write([]byte("connecting to postgres://u:pa55word@db/app\n"))
+	if out.String() != "connecting to ***\n" {
+		t.Fatalf("out = %q", out.String())
+	}
+}
+
+func TestBindWarnings(t *testing.T) {
+	type C struct {
+		Port  int    `env:"WN_HTTP_PORT|WN_PORT"`
+		Host  string `env:"WN_HOST,required" envdef:"localhost"`
+		Debug bool   `env:"WN_DEBUG"`
+		Old   string `env:"WN_OLD" envdeprecated:"since=1.0"`
+		Bad   int    `env:"WN_BAD"`
+	}
+	t.Setenv("WN_PORT", "8080")
+	t.Setenv("WN_DEBUG", "yes")
+	t.Setenv("WN_OLD", "x")
+	t.Setenv("WN_BAD", "nope")
+	var c C
+	ws, err := BindWith(&c)
+	if err == nil {
+		t.Fatal("want type error for WN_BAD")
+	}
+	want := []string{
+		"Port (WN_PORT): set through fallback name; rename it to WN_HTTP_PORT",
+		"Host (WN_HOST): required variable not set; using envdef default",
+		`Debug (WN_DEBUG): lenient boolean "yes" accepted; use true or false`,
+		"Old (WN_OLD): deprecated since 1.0",
+	}
+	if got := strings.Split(strings.TrimSpace(ws.String()), "\n"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("warnings =\n%s", ws)
+	}
+}
+
+type bindHook struct {
+	recordingHook
+	structType     string
+	fields, errors int
+	failed         []string
+}
+
+func (h *bindHook) OnBind(structType string, fields, errors int, _ time.Duration) {
+	h.structType, h.fields, h.errors = structType, fields, errors
+}
+
+func (h *bindHook) OnValidationError(field, rule, msg string) {
+	h.failed = append(h.failed, field+" "+rule+": "+msg)
+}
+
+func TestBindHooks(t *testing.T) {
+	type C struct {
+		Port  int    `env:"BH_PORT" validate:"min=1"`
+		Level string `env:"BH_LEVEL" validate:"oneof=debug|info"`
+		Name  string `env:"BH_NAME"`
+	}
+	t.Setenv("BH_PORT", "0")
+	t.Setenv("BH_LEVEL", "info")
+
+	h := &bindHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+	var c C
+	if err := Bind(&c); err == nil {
+		t.Fatal("want validation error")
+	}
+	if h.structType != "binders.C" || h.fields != 3 || h.errors != 1 {
+		t.Fatalf("OnBind(%q, %d, %d)", h.structType, h.fields, h.errors)
+	}
+	if len(h.failed) != 1 || !strings.HasPrefix(h.failed[0], "Port min: ") {
+		t.Fatalf("OnValidationError calls = %q", h.failed)
+	}
+}
+
+func TestBindReportCoercions(t *testing.T) {
+	t.Setenv("CO_RATIO", "0.1234567891")
+	t.Setenv("CO_SCALE", "1.00000000000000000001")
+	t.Setenv("CO_DEBUG", " true ")
+	t.Setenv("CO_PORT", " 8080 ")
+	t.Setenv("CO_NAME", " web ")
+	t.Setenv("CO_EXACT", "0.5")
+	var cfg struct {
+		Ratio float32 `env:"CO_RATIO"`
+		Scale float64 `env:"CO_SCALE"`
+		Debug bool    `env:"CO_DEBUG"`
+		Port  int     `env:"CO_PORT"`
+		Name  string  `env:"CO_NAME"`
+		Exact float32 `env:"CO_EXACT"`
+	}
+	var rep Report
+	if err := Bind(&cfg, WithReport(&rep)); err == nil {
+		t.Fatal("want type error for CO_PORT")
+	}
+	got := map[string][]string{}
+	for _, f := range rep.Fields {
+		got[f.Field] = f.Coerced
+	}
+	want := map[string][]string{
+		"Ratio": {"rounded to float32 precision: 0.12345679"},
+		"Scale": {"rounded to float64 precision: 1"},
+		"Debug": {"surrounding whitespace trimmed"},
+		"Port":  nil,
+		"Name":  nil,
+		"Exact": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("coerced = %q", got)
+	}
+	if !strings.Contains(rep.String(), "(coerced: rounded to float32 precision: 0.12345679)") {
+		t.Fatalf("String() = %q", rep.String())
+	}
+}
+
+type driftHook struct {
+	recordingHook
+	defaults []string
+	missing  []string
+}
+
+func (h *driftHook) OnDefaultUsed(key, value string) { h.defaults = append(h.defaults, key+"="+value) }
+func (h *driftHook) OnMissing(key string)            { h.missing = append(h.missing, key) }
+
+func TestBindDefaultAndMissingHooks(t *testing.T) {
+	t.Setenv("BDH_HOST", "db")
+	var cfg struct {
+		Host  string `env:"BDH_HOST" envdef:"localhost"`
+		Port  int    `env:"BDH_PORT" envdef:"5432"`
+		Pass  string `env:"BDH_PASS" envdef:"dev" secret:"true"`
+		Debug bool   `env:"BDH_DEBUG"`
+	}
+	h := &driftHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+	if err := Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"BDH_PORT=5432", "BDH_PASS=***"}; !reflect.DeepEqual(h.defaults, want) {
+		t.Fatalf("defaults = %q", h.defaults)
+	}
+	if want := []string{"BDH_PORT", "BDH_PASS", "BDH_DEBUG"}; !reflect.DeepEqual(h.missing, want) {
+		t.Fatalf("missing = %q", h.missing)
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	cfg := struct {
+		Port  int            `env:"PORT"`
+		Token string         `env:"API_TOKEN"`
+		Hosts []string       `env:"HOSTS"`
+		Debug *bool          `env:"DEBUG"`
+		Wait  time.Duration  `env:"WAIT"`
+		Caps  map[string]int `env:"CAPS"`
+	}{Port: 8080, Token: "t0k", Hosts: []string{"a", "b"}, Wait: time.Second}
+
+	var b strings.Builder
+	if err := PrintConfig(&b, &cfg, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := "KEY        VALUE\n" +
+		"API_TOKEN  ***\n" +
+		"CAPS       -\n" +
+		"DEBUG      -\n" +
+		"HOSTS      [\"a\",\"b\"]\n" +
+		"PORT       8080\n" +
+		"WAIT       1s\n"
+	if b.String() != want {
+		t.Fatalf("table =\n%s", b.String())
+	}
+	for _, format := range []string{"json", "yaml"} {
+		b.Reset()
+		if err := PrintConfig(&b, cfg, format); err != nil || strings.Contains(b.String(), "t0k") {
+			t.Fatalf("%s: %v\n%s", format, err, b.String())
+		}
+	}
+	if err := PrintConfig(&b, cfg, "xml"); err == nil {
+		t.Fatal("want error for unknown format")
+	}
+}
+
+func TestBindWithHook(t *testing.T) {
+	t.Setenv("WH_A_PORT", "0")
+	type A struct {
+		Port int `env:"WH_A_PORT" validate:"min=1"`
+	}
+	type B struct {
+		Host string `env:"WH_B_HOST" envdef:"localhost"`
+	}
+	global := &bindHook{}
+	types.SetHook(global)
+	defer types.SetHook(nil)
+
+	ha, hb := &bindHook{}, &driftHook{}
+	Bind(&A{}, WithHook(ha))
+	if err := Bind(&B{}, WithHook(hb)); err != nil {
+		t.Fatal(err)
+	}
+	if ha.structType != "binders.A" || len(ha.failed) != 1 {
+		t.Fatalf("hook A = %+v", ha)
+	}
+	if !reflect.DeepEqual(hb.defaults, []string{"WH_B_HOST=localhost"}) {
+		t.Fatalf("hook B defaults = %q", hb.defaults)
+	}
+	if global.structType != "binders.B" || len(global.failed) != 1 {
+		t.Fatalf("global hook = %+v", global)
+	}
+}
+
+// blockingSource blocks lookups of B until ctx is done.
+type blockingSource struct{ sources.Map }
+
+func (s blockingSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if key != "BC_B" {
+		v, ok := s.Lookup(key)
+		return v, ok, nil
+	}
+	<-ctx.Done()
+	return "", false, ctx.Err()
+}
+
+func TestBindCtx(t *testing.T) {
+	prev := sources.Default()
+	defer sources.SetDefault(prev)
+	sources.SetDefault(blockingSource{sources.Map{"BC_A": "1", "BC_C": "3"}})
+	var cfg struct {
+		A int `env:"BC_A"`
+		B int `env:"BC_B,required"`
+		C int `env:"BC_C"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := BindCtx(ctx, &cfg)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrMissingVar) {
+		t.Fatalf("err = %v", err)
+	}
+	if cfg.A != 1 || cfg.C != 0 {
+		t.Fatalf("cfg = %+v; fields after cancellation must be skipped", cfg)
+	}
+}
+
+type CompiledBase struct {
+	Host string `env:"CB_HOST" envdef:"localhost"`
+}
+
+type compiledConfig struct {
+	*CompiledBase
+	Port  int      `env:"CB_PORT,required" validate:"min=1"`
+	Tags  []string `env:"CB_TAGS" envsep:";"`
+	Token string   `env:"CB_TOKEN" secret:"true"`
+}
+
+func TestCompile(t *testing.T) {
+	t.Setenv("CB_PORT", "8080")
+	t.Setenv("CB_TAGS", "a;b")
+	t.Setenv("APP_CB_PORT", "9090")
+	c, err := Compile[compiledConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg compiledConfig
+	if err := c.Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CompiledBase == nil || cfg.Host != "localhost" || cfg.Port != 8080 ||
+		!reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	var pcfg compiledConfig
+	if err := c.BindWithPrefix(&pcfg, "APP_"); err != nil || pcfg.Port != 9090 {
+		t.Fatalf("prefixed cfg = %+v, err = %v", pcfg, err)
+	}
+
+	t.Setenv("CB_PORT", "0")
+	var rep Report
+	err = c.Bind(&cfg, WithReport(&rep))
+	if !errors.Is(err, ErrValidation) || len(rep.Fields) != 4 {
+		t.Fatalf("err = %v, report = %+v", err, rep.Fields)
+	}
+
+	if _, err := Compile[int](); err == nil {
+		t.Fatal("want error for non-struct type")
+	}
+
+	// Converters and rules registered after Compile still apply.
+	type upper string
+	type U struct {
+		Name upper `env:"CB_NAME" validate:"cbnotx"`
+	}
+	t.Setenv("CB_NAME", "x")
+	cu := MustCompile[U]()
+	RegisterConverterFor(func(raw string) (upper, error) { return upper(strings.ToUpper(raw)), nil })
+	defer RegisterConverter(reflect.TypeOf(upper("")), nil)
+	validate.Register("cbnotx", func(v reflect.Value) error {
+		if v.String() == "x" {
+			return errors.New("must not be x")
+		}
+		return nil
+	})
+	var u U
+	if err := cu.Bind(&u); err != nil || u.Name != "X" {
+		t.Fatalf("u = %+v, err = %v", u, err)
+	}
+}
+
+func BenchmarkBind(b *testing.B) {
+	b.Setenv("CB_PORT", "8080")
+	b.Setenv("CB_TAGS", "a;b")
+	for i := 0; i < b.N; i++ {
+		var cfg compiledConfig
+		if err := Bind(&cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindPlan(b *testing.B) {
+	b.Setenv("CB_PORT", "8080")
+	b.Setenv("CB_TAGS", "a;b")
+	c := MustCompile[compiledConfig]()
+	for i := 0; i < b.N; i++ {
+		var cfg compiledConfig
+		if err := c.Bind(&cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBindGroups(t *testing.T) {
+	type C struct {
+		User     string `env:"BASIC_AUTH_USER" envgroup:"auth=basic"`
+		Pass     string `env:"BASIC_AUTH_PASS" envgroup:"auth=basic" secret:"true"`
+		Issuer   string `env:"OIDC_ISSUER" envgroup:"auth=oidc"`
+		ClientID string `env:"OIDC_CLIENT_ID" envgroup:"auth=oidc"`
+		Scopes   string `env:"OIDC_SCOPES" envgroup:"auth=oidc" envdef:"openid"`
+	}
+	tests := []struct {
+		name string
+		env  sources.Map
+		want string
+	}{
+		{"basic", sources.Map{"BASIC_AUTH_USER": "u", "BASIC_AUTH_PASS": "p"}, ""},
+		{"oidc with default", sources.Map{"OIDC_ISSUER": "i", "OIDC_CLIENT_ID": "c"}, ""},
+		{"none", sources.Map{},
+			"envvar: missing auth: set one of basic (BASIC_AUTH_USER, BASIC_AUTH_PASS) or oidc (OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_SCOPES)"},
+		{"incomplete", sources.Map{"OIDC_ISSUER": "i"},
+			"envvar: missing auth: group oidc also needs OIDC_CLIENT_ID"},
+		{"conflict", sources.Map{"BASIC_AUTH_USER": "u", "BASIC_AUTH_PASS": "p", "OIDC_ISSUER": "i"},
+			"envvar: conflicting settings in auth: basic (BASIC_AUTH_USER, BASIC_AUTH_PASS) and oidc (OIDC_ISSUER) are mutually exclusive; set only one group"},
+	}
+	prev := sources.Default()
+	defer sources.SetDefault(prev)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources.SetDefault(tt.env)
+			err := Bind(&C{})
+			got := ""
+			if me, ok := err.(MultiError); ok && len(me) == 1 {
+				got = me[0].Error()
+			} else if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Fatalf("err = %q, want %q", got, tt.want)
+			}
+			if tt.name == "conflict" && !errors.Is(err, ErrValidation) {
+				t.Fatal("conflict should match ErrValidation")
+			}
+		})
+	}
+}
+
+func TestBindValidateRequired(t *testing.T) {
+	var cfg struct {
+		Host string `env:"VR_HOST" validate:"required,hostname"`
+		Mode string `env:"VR_MODE" validate:"omitempty,alphanum"`
+	}
+	err := Bind(&cfg)
+	var me MultiError
+	if !errors.As(err, &me) || len(me) != 1 || !errors.Is(me[0], ErrMissingVar) {
+		t.Fatalf("want one missing error, got %v", err)
+	}
+	t.Setenv("VR_HOST", "db.local")
+	t.Setenv("VR_MODE", "fast")
+	if err := Bind(&cfg); err != nil {
+		t.Fatalf("unknown rules must be ignored: %v", err)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	t.Setenv("PV_HOST", "db.local")
+	t.Setenv("PV_URL", "postgres://${PV_HOST}/app")
+	t.Setenv("PV_PORT", "0")
+	type C struct {
+		Host  string `env:"PV_HOST"`
+		URL   string `env:"PV_URL"`
+		Port  int    `env:"PV_PORT" validate:"min=1"`
+		Mode  string `env:"PV_MODE" envdef:"fast"`
+		Token string `env:"PV_TOKEN_NEW|PV_TOKEN" secret:"true"`
+	}
+	t.Setenv("PV_TOKEN", "s3cr3t")
+	h := &bindHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+
+	got, err := Provenance(&C{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FieldReport{
+		{Field: "Host", Key: "PV_HOST", Source: "os", Value: "db.local"},
+		{Field: "URL", Key: "PV_URL", Source: "os", Value: "postgres://db.local/app", Expanded: true},
+		{Field: "Port", Key: "PV_PORT", Source: "os", Value: "0"},
+		{Field: "Mode", Key: "PV_MODE", Source: "envdef", Value: "fast", Default: true},
+		{Field: "Token", Key: "PV_TOKEN", Source: "os", Value: redact.Mask},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Provenance = %+v", got)
+	}
+	if h.structType != "" || len(h.failed) != 0 || len(h.errs) != 0 {
+		t.Fatalf("Provenance must not call hooks: %+v", h)
+	}
+	if _, err := Provenance(42); err == nil {
+		t.Fatal("want error for non-struct")
+	}
+}
@@ -0,0 +1,51 @@
+package binders
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// coercions describes how storing raw into fv changed the value:
+// surrounding whitespace the parser dropped and decimal digits lost to
+// float precision. Values of secret fields are not repeated.
+//
+// Parameters:
+//   - fv: The field after a successful set.
+//   - raw: The value it was parsed from.
+//   - secret: Whether the field holds a secret.
+//
+// Returns:
+//   - []string: The notes, or nil when the value was stored as given.
+func coercions(fv reflect.Value, raw string, secret bool) []string {
+	var notes []string
+	kind := fv.Kind()
+	if kind == reflect.Pointer {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+		kind = fv.Kind()
+	}
+	if kind != reflect.String && strings.TrimSpace(raw) != raw {
+		notes = append(notes, "surrounding whitespace trimmed")
+	}
+	if kind == reflect.Float32 || kind == reflect.Float64 {
+		bits := 64
+		if kind == reflect.Float32 {
+			bits = 32
+		}
+		got := strconv.FormatFloat(fv.Float(), 'g', -1, bits)
+		want, ok1 := new(big.Rat).SetString(strings.TrimSpace(raw))
+		have, ok2 := new(big.Rat).SetString(got)
+		if ok1 && ok2 && want.Cmp(have) != 0 {
+			note := "rounded to float" + strconv.Itoa(bits) + " precision"
+			if !secret {
+				note += ": " + got
+			}
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
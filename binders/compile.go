@@ -0,0 +1,251 @@
+package binders
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/validate"
+)
+
+// Compiled binds a struct type from a field plan built once by Compile.
+// It is safe for concurrent use.
+type Compiled[T any] struct {
+	plan *structPlan
+}
+
+// Compile reflects over T once, parsing its tags into a field plan, and
+// returns a binder that reuses the plan. Services that bind the same
+// type repeatedly, for example on every reload, avoid walking the type
+// and parsing tags on each call. Values are still looked up, expanded,
+// resolved, and validated on every Bind.
+//
+// Returns:
+//   - *Compiled[T]: The binder.
+//   - error: The error if T is not a struct.
+func Compile[T any]() (*Compiled[T], error) {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("envvar: Compile expects a struct type, got %s", rt)
+	}
+	plan := planOf(rt)
+	plan.compile()
+	return &Compiled[T]{plan: plan}, nil
+}
+
+// MustCompile is like Compile but panics if T is not a struct.
+//
+// Returns:
+//   - *Compiled[T]: The binder.
+func MustCompile[T any]() *Compiled[T] {
+	c, err := Compile[T]()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Bind populates dst from the default source like Bind.
+//
+// Parameters:
+//   - dst: The destination.
+//   - opts: The options, e.g. WithReport.
+//
+// Returns:
+//   - error: The error if the binding fails.
+func (c *Compiled[T]) Bind(dst *T, opts ...Option) error {
+	return c.BindWithPrefix(dst, "", opts...)
+}
+
+// BindWithPrefix populates dst like BindWithPrefix.
+//
+// Parameters:
+//   - dst: The destination.
+//   - prefix: The prefix.
+//   - opts: The options.
+//
+// Returns:
+//   - error: The error if the binding fails.
+func (c *Compiled[T]) BindWithPrefix(dst *T, prefix string, opts ...Option) error {
+	if dst == nil {
+		return fmt.Errorf("envvar: Bind expects pointer to struct")
+	}
+	o := buildOptions(opts)
+	o.plan = c.plan
+	return bindWithOptions(dst, prefix, o)
+}
+
+// structPlan is the precomputed binding of a struct type: its tagged
+// fields and the embedded structs to descend into, in field order.
+type structPlan struct {
+	steps []planStep
+}
+
+// planStep is either a tagged field or an embedded struct.
+type planStep struct {
+	// index is the field index within the struct.
+	index int
+	// field is set for tagged fields.
+	field *fieldPlan
+	// embedded is set for untagged embedded structs; ptr means the
+	// field is a pointer to the struct.
+	embedded *structPlan
+	ptr      bool
+}
+
+// fieldPlan holds what bindField derives from a field's type and tags:
+// the parsed tags, the setter for its type, and its parsed rules.
+type fieldPlan struct {
+	f        reflect.StructField
+	tag      envTag
+	fileMode bool
+	def      string
+	jsonMode bool
+	fo       fieldOpts
+	secret   bool
+	rules    string
+	dep      string
+	hasDep   bool
+	// groupSet and group come from the envgroup tag; groupSet is ""
+	// for fields in no group.
+	groupSet string
+	group    string
+	// compiled is set by compile, which fills set, convGen, and check.
+	compiled bool
+	// set parses values into the field; convGen is the converter
+	// registry generation it was built in.
+	set     setter
+	convGen uint64
+	// check holds the parsed validate rules.
+	check validate.Rules
+}
+
+// compile resolves the setters and parses the rules of every field,
+// for plans that are reused. Plans built for a single Bind skip this.
+func (plan *structPlan) compile() {
+	for _, st := range plan.steps {
+		if st.embedded != nil {
+			st.embedded.compile()
+			continue
+		}
+		p := st.field
+		p.convGen = convGen.Load()
+		p.set = setterFor(p.f.Type, p.fo)
+		p.check = validate.Parse(p.rules)
+		p.compiled = true
+	}
+}
+
+// setValue parses raw into fv with the compiled setter, or resolves
+// one when the plan is not compiled or converters were registered
+// since.
+func (p *fieldPlan) setValue(fv reflect.Value, raw string) error {
+	if !p.compiled || p.convGen != convGen.Load() {
+		return setField(fv, raw, p.fo)
+	}
+	return p.set.set(fv, raw)
+}
+
+// validate checks fv against the field's rules.
+func (p *fieldPlan) validate(fv reflect.Value) error {
+	if !p.compiled {
+		return validate.Field(fv, p.rules)
+	}
+	return p.check.Check(fv)
+}
+
+// planOf builds the plan for the struct type rt.
+func planOf(rt reflect.Type) *structPlan {
+	return planPath(rt, nil)
+}
+
+// planPath builds the plan for rt, whose embedding structs are path.
+// An embedded struct already on the path, as in
+// `type Node struct{ *Node }`, is skipped: its promoted fields are
+// those of the outer occurrence.
+func planPath(rt reflect.Type, path []reflect.Type) *structPlan {
+	p := &structPlan{}
+	path = append(path, rt)
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		ev, ok := f.Tag.Lookup("env")
+		if !ok {
+			if !f.Anonymous {
+				continue
+			}
+			switch et := embeddedStruct(f.Type); {
+			case et == nil || slices.Contains(path, et):
+			case f.Type.Kind() == reflect.Struct:
+				p.steps = append(p.steps, planStep{index: i, embedded: planPath(et, path)})
+			default:
+				p.steps = append(p.steps, planStep{index: i, embedded: planPath(et, path), ptr: true})
+			}
+			continue
+		}
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		p.steps = append(p.steps, planStep{index: i, field: newFieldPlan(f, ev)})
+	}
+	return p
+}
+
+// embeddedStruct returns the struct type of an embedded field of type
+// t, a struct or a pointer to one, or nil.
+func embeddedStruct(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// newFieldPlan parses the tags of f, whose env tag is ev.
+func newFieldPlan(f reflect.StructField, ev string) *fieldPlan {
+	tag := parseEnvTag(ev)
+	sep := f.Tag.Get("envsep")
+	if sep == "" {
+		sep = ","
+	}
+	kvSep := f.Tag.Get("envkvsep")
+	if kvSep == "" {
+		kvSep = "="
+	}
+	rules := f.Tag.Get("validate")
+	if hasRule(rules, "required") {
+		tag.required = true
+	}
+	dep, hasDep := f.Tag.Lookup("envdeprecated")
+	var groupSet, group string
+	if g, ok := f.Tag.Lookup("envgroup"); ok {
+		groupSet, group = parseGroupTag(g)
+	}
+	fo := fieldOpts{sep: sep, kvSep: kvSep, layout: f.Tag.Get("envlayout")}
+	return &fieldPlan{
+		f:        f,
+		tag:      tag,
+		fileMode: tag.file || strings.EqualFold(f.Tag.Get("envfile"), "true"),
+		def:      f.Tag.Get("envdef"),
+		jsonMode: strings.EqualFold(f.Tag.Get("envjson"), "true"),
+		fo:       fo,
+		secret:   strings.EqualFold(f.Tag.Get("secret"), "true"),
+		rules:    rules,
+		dep:      dep,
+		hasDep:   hasDep,
+		groupSet: groupSet,
+		group:    group,
+	}
+}
+
+// hasRule reports whether the validate tag rules names rule.
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(r), "="); name == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package binders
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// completionVar is one declared variable offered for completion.
+type completionVar struct {
+	name   string
+	desc   string
+	values []string
+}
+
+// Completion returns a bash, zsh, or fish completion script for
+// command that completes NAME= assignments of the variables cfg binds,
+// e.g. for "env PORT=8080 app" or tools taking assignments as
+// arguments. After NAME=, the values of a oneof validation rule are
+// offered, and true and false for bool fields. Descriptions come from
+// envdesc tags where the shell shows them.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct with env tags.
+//   - shell: "bash", "zsh", or "fish".
+//   - command: The command the completion is registered for.
+//
+// Returns:
+//   - []byte: The completion script.
+//   - error: The error if cfg is not a struct or shell is unsupported.
+func Completion(cfg any, shell, command string) ([]byte, error) {
+	t := structType(cfg)
+	if t == nil {
+		return nil, fmt.Errorf("envvar: Completion expects struct or pointer to struct")
+	}
+	var vars []completionVar
+	completionVars(t, nil, &vars)
+	sort.Slice(vars, func(i, j int) bool { return vars[i].name < vars[j].name })
+	var b strings.Builder
+	switch shell {
+	case "bash":
+		bashCompletion(&b, vars, command)
+	case "zsh":
+		zshCompletion(&b, vars, command)
+	case "fish":
+		fishCompletion(&b, vars, command)
+	default:
+		return nil, fmt.Errorf("envvar: unsupported shell %q", shell)
+	}
+	return []byte(b.String()), nil
+}
+
+// completionVars appends the tagged fields of t, descending into
+// untagged embedded structs not already on path like Bind.
+func completionVars(t reflect.Type, path []reflect.Type, out *[]completionVar) {
+	path = append(path, t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ev, ok := f.Tag.Lookup("env")
+		if !ok {
+			if et := embeddedStruct(f.Type); f.Anonymous && et != nil && !slices.Contains(path, et) {
+				completionVars(et, path, out)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		*out = append(*out, completionVar{
+			name:   parseEnvTag(ev).names[0],
+			desc:   f.Tag.Get("envdesc"),
+			values: completionValues(f),
+		})
+	}
+}
+
+// completionValues returns the values offered for f: its oneof
+// options, or true and false for bools. Values containing whitespace
+// or quotes cannot be completed as single words and are skipped.
+func completionValues(f reflect.StructField) []string {
+	var vals []string
+	for _, r := range strings.Split(f.Tag.Get("validate"), ",") {
+		if name, param, _ := strings.Cut(strings.TrimSpace(r), "="); name == "oneof" {
+			vals = strings.Split(param, "|")
+		}
+	}
+	ft := f.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	if vals == nil && ft.Kind() == reflect.Bool {
+		vals = []string{"true", "false"}
+	}
+	out := vals[:0]
+	for _, v := range vals {
+		if v != "" && !strings.ContainsAny(v, " \t\n'\"\\$`") {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// funcName turns command into a shell function name.
+func funcName(command string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, command) + "_envvar"
+}
+
+// quote single-quotes s for sh, bash, zsh, and fish.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// bashCompletion writes the bash script. The word is taken from
+// COMP_LINE, and values get the NAME= prefix back when '=' is not in
+// COMP_WORDBREAKS, so it works either way.
+func bashCompletion(b *strings.Builder, vars []completionVar, command string) {
+	fn := funcName(command)
+	fmt.Fprintf(b, "# bash completion for %s configuration variables\n", command)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString("\tlocal cur=${COMP_LINE:0:COMP_POINT}\n")
+	b.WriteString("\tcur=${cur##*[[:space:]]}\n")
+	b.WriteString("\tlocal vals\n\tcase $cur in\n")
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.name + "="
+		if len(v.values) > 0 {
+			fmt.Fprintf(b, "\t%s=*) vals=%s ;;\n", v.name, quote(strings.Join(v.values, " ")))
+		}
+	}
+	b.WriteString("\t*=*) COMPREPLY=(); return ;;\n")
+	fmt.Fprintf(b, "\t*) COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", quote(strings.Join(names, " ")))
+	b.WriteString("\t\tcompopt -o nospace 2>/dev/null\n\t\treturn ;;\n\tesac\n")
+	b.WriteString("\tCOMPREPLY=($(compgen -W \"$vals\" -- \"${cur#*=}\"))\n")
+	b.WriteString("\t[[ $COMP_WORDBREAKS == *=* ]] || COMPREPLY=(\"${COMPREPLY[@]/#/${cur%%=*}=}\")\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(b, "complete -F %s %s\n", fn, quote(command))
+}
+
+// zshCompletion writes the zsh script.
+func zshCompletion(b *strings.Builder, vars []completionVar, command string) {
+	fn := funcName(command)
+	fmt.Fprintf(b, "#compdef %s\n", command)
+	fmt.Fprintf(b, "# zsh completion for %s configuration variables\n", command)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString("\tif compset -P '*='; then\n")
+	b.WriteString("\t\tcase ${IPREFIX%=} in\n")
+	for _, v := range vars {
+		if len(v.values) > 0 {
+			vals := make([]string, len(v.values))
+			for i, val := range v.values {
+				vals[i] = quote(val)
+			}
+			fmt.Fprintf(b, "\t\t%s) compadd -- %s ;;\n", v.name, strings.Join(vals, " "))
+		}
+	}
+	b.WriteString("\t\tesac\n\t\treturn\n\tfi\n")
+	b.WriteString("\tlocal -a vars\n\tvars=(\n")
+	for _, v := range vars {
+		d := v.name
+		if v.desc != "" {
+			d += ":" + v.desc
+		}
+		fmt.Fprintf(b, "\t\t%s\n", quote(d))
+	}
+	b.WriteString("\t)\n\t_describe -t variables 'configuration variable' vars -S '='\n}\n")
+	fmt.Fprintf(b, "compdef %s %s\n", fn, quote(command))
+}
+
+// fishCompletion writes the fish script. fish matches whole tokens, so
+// enumerated values are offered as complete NAME=value words.
+func fishCompletion(b *strings.Builder, vars []completionVar, command string) {
+	fmt.Fprintf(b, "# fish completion for %s configuration variables\n", command)
+	for _, v := range vars {
+		words := []string{v.name + "="}
+		if len(v.values) > 0 {
+			words = words[:0]
+			for _, val := range v.values {
+				words = append(words, v.name+"="+val)
+			}
+		}
+		fmt.Fprintf(b, "complete -c %s -f -a %s", quote(command), quote(strings.Join(words, " ")))
+		if v.desc != "" {
+			fmt.Fprintf(b, " -d %s", quote(v.desc))
+		}
+		b.WriteString("\n")
+	}
+}
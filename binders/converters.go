@@ -0,0 +1,81 @@
+package binders
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Converter parses a raw value into a field value of a registered type.
+type Converter func(raw string) (any, error)
+
+var (
+	// convMu protects converters.
+	convMu sync.RWMutex
+	// converters maps field types to their converters.
+	converters = map[reflect.Type]Converter{}
+	// convGen counts registrations, so compiled setters built before
+	// a registration are rebuilt.
+	convGen atomic.Uint64
+)
+
+// RegisterConverter teaches Bind to populate fields of type t using
+// conv. Registered converters take precedence over built-in parsing and
+// also apply to pointer fields and slice elements of type t. Passing a
+// nil conv removes the registration.
+//
+// Parameters:
+//   - t: The field type.
+//   - conv: The converter.
+func RegisterConverter(t reflect.Type, conv Converter) {
+	convMu.Lock()
+	defer convMu.Unlock()
+	convGen.Add(1)
+	if conv == nil {
+		delete(converters, t)
+		return
+	}
+	converters[t] = conv
+}
+
+// ResetConverters removes every registered converter. Meant for tests;
+// see envvartest.ResetGlobalState.
+func ResetConverters() {
+	convMu.Lock()
+	defer convMu.Unlock()
+	convGen.Add(1)
+	converters = map[reflect.Type]Converter{}
+}
+
+// RegisterConverterFor is a typed helper for RegisterConverter.
+//
+// Parameters:
+//   - conv: The converter for T.
+func RegisterConverterFor[T any](conv func(raw string) (T, error)) {
+	RegisterConverter(reflect.TypeOf((*T)(nil)).Elem(), func(raw string) (any, error) {
+		return conv(raw)
+	})
+}
+
+// converterFor returns the converter registered for t, if any.
+func converterFor(t reflect.Type) (Converter, bool) {
+	convMu.RLock()
+	defer convMu.RUnlock()
+	c, ok := converters[t]
+	return c, ok
+}
+
+// convert sets v using conv.
+func convert(v reflect.Value, raw string, conv Converter) error {
+	out, err := conv(raw)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(out)
+	if !rv.IsValid() || !rv.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("converter for %s returned %T", v.Type(), out)
+	}
+	v.Set(rv)
+	return nil
+}
@@ -0,0 +1,123 @@
+package binders
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// AppVersion is the application version compared with the remove=
+// version of envdeprecated tags. Set it at build time, e.g.
+//
+//	go build -ldflags "-X github.com/aatuh/envvar/v2/binders.AppVersion=2.0.0"
+//
+// or per call with WithVersion. When empty, deprecated variables only
+// warn.
+var AppVersion string
+
+// WithVersion sets the application version for envdeprecated checks,
+// overriding AppVersion.
+//
+// Parameters:
+//   - version: The application version, e.g. "2.0.0".
+//
+// Returns:
+//   - Option: The option.
+func WithVersion(version string) Option {
+	return func(o *options) { o.version = version }
+}
+
+// deprecation is a parsed envdeprecated tag.
+type deprecation struct {
+	since, remove, use string
+}
+
+// parseDeprecation parses `since=1.4,remove=2.0,use=NEW_KEY`. Any part
+// may be omitted.
+func parseDeprecation(tag string) deprecation {
+	var d deprecation
+	for _, part := range strings.Split(tag, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "since":
+			d.since = v
+		case "remove":
+			d.remove = v
+		case "use":
+			d.use = v
+		}
+	}
+	return d
+}
+
+// describe describes the timeline, e.g. "deprecated since 1.4, removed
+// in 2.0; use NEW_KEY".
+func (d deprecation) describe(removed bool) string {
+	var b strings.Builder
+	b.WriteString("deprecated")
+	if d.since != "" {
+		b.WriteString(" since " + d.since)
+	}
+	if d.remove != "" {
+		if removed {
+			b.WriteString(", removed in " + d.remove)
+		} else {
+			b.WriteString(", to be removed in " + d.remove)
+		}
+	}
+	if d.use != "" {
+		b.WriteString("; use " + d.use)
+	}
+	return b.String()
+}
+
+// deprecated describes a set deprecated variable key, emits an
+// EventDeprecated, and reports whether version has reached the removal
+// version. Before removal callers report the error to the error hook as
+// a warning.
+func deprecated(key, tag, version string) (*KeyError, bool) {
+	d := parseDeprecation(tag)
+	removed := version != "" && d.remove != "" &&
+		compareVersions(version, d.remove) >= 0
+	err := &KeyError{Key: key, Kind: ErrDeprecated, Msg: d.describe(removed)}
+	types.Emit(types.Event{Kind: types.EventDeprecated, Key: key, Err: err})
+	return err, removed
+}
+
+// compareVersions compares dotted versions such as "v1.10.2" and "1.9"
+// numerically by component, treating missing components as zero and
+// ignoring a "v" prefix and pre-release or build suffixes.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts returns the numeric components of v.
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	var out []int
+	for _, p := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(p)
+		out = append(out, n)
+	}
+	return out
+}
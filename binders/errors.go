@@ -1,6 +1,11 @@
 package binders
 
-import "strings"
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/types"
+)
 
 // ErrKind describes the class of error.
 type ErrKind int
@@ -9,6 +14,57 @@ const (
 	// ErrMissing is the error kind for missing values.
 	ErrMissing ErrKind = iota + 1
 	ErrType
+	// ErrDecrypt is the error kind for values that fail to decrypt.
+	ErrDecrypt
+	// ErrRef is the error kind for references that fail to resolve.
+	ErrRef
+	// ErrLimit is the error kind for values exceeding types.Limits.
+	ErrLimit
+	// ErrUnknown is the error kind for prefixed variables that map to
+	// no field in strict mode.
+	ErrUnknown
+	// ErrDeprecated is the error kind for set envdeprecated variables.
+	ErrDeprecated
+	// ErrPin is the error kind for values not matching their pin.
+	ErrPin
+	// ErrConflict is the error kind for variables set in more than one
+	// group of an envgroup set.
+	ErrConflict
+)
+
+// String returns the kind name, e.g. "missing", or "" for no kind.
+//
+// Returns:
+//   - string: The kind name.
+func (k ErrKind) String() string {
+	switch k {
+	case ErrMissing:
+		return "missing"
+	case ErrType:
+		return "type"
+	case ErrDecrypt:
+		return "decrypt"
+	case ErrRef:
+		return "ref"
+	case ErrLimit:
+		return "limit"
+	case ErrUnknown:
+		return "unknown"
+	case ErrDeprecated:
+		return "deprecated"
+	case ErrPin:
+		return "pin"
+	case ErrConflict:
+		return "conflict"
+	}
+	return ""
+}
+
+// Sentinels matched by KeyError via errors.Is; see the types package.
+var (
+	ErrMissingVar   = types.ErrMissingVar
+	ErrTypeMismatch = types.ErrTypeMismatch
+	ErrValidation   = types.ErrValidation
 )
 
 // KeyError is an error for envvar key-related errors.
@@ -30,6 +86,18 @@ func (e *KeyError) Error() string {
 		b.WriteString("missing ")
 	case ErrType:
 		b.WriteString("type error for ")
+	case ErrDecrypt:
+		b.WriteString("decrypt error for ")
+	case ErrRef:
+		b.WriteString("reference error for ")
+	case ErrLimit:
+		b.WriteString("limit exceeded for ")
+	case ErrUnknown:
+		b.WriteString("unknown variable ")
+	case ErrDeprecated:
+		b.WriteString("variable ")
+	case ErrConflict:
+		b.WriteString("conflicting settings in ")
 	}
 	b.WriteString(e.Key)
 	if e.Msg != "" {
@@ -39,6 +107,98 @@ func (e *KeyError) Error() string {
 	return b.String()
 }
 
+// Is reports whether the error matches target: ErrMissingVar for
+// missing values, ErrTypeMismatch for type errors, types.ErrLimit for
+// values exceeding limits, and ErrValidation for envgroup conflicts.
+//
+// Parameters:
+//   - target: The sentinel to compare with.
+//
+// Returns:
+//   - bool: Whether the error matches.
+func (e *KeyError) Is(target error) bool {
+	switch target {
+	case types.ErrMissingVar:
+		return e.Kind == ErrMissing
+	case types.ErrTypeMismatch:
+		return e.Kind == ErrType
+	case types.ErrLimit:
+		return e.Kind == ErrLimit
+	case types.ErrValidation:
+		return e.Kind == ErrConflict
+	}
+	return false
+}
+
+// FieldError is a bind failure of a single field with machine-readable
+// details, for tooling that renders configuration reports.
+type FieldError struct {
+	// Field is the Go field name.
+	Field string
+	// Key is the variable name.
+	Key string
+	// Rule is the failed validation rule, "type" for values that do not
+	// parse into the field, or "file" for unreadable file values.
+	Rule string
+	// Value is the offending value, masked for secrets.
+	Value string
+	// Want is the rule parameter, e.g. "1" for min=1, or the expected
+	// format for type errors, e.g. "integer".
+	Want string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the error message.
+//
+// Returns:
+//   - string: The error message.
+func (e *FieldError) Error() string {
+	return "envvar: " + e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+//
+// Returns:
+//   - error: The underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches target: ErrTypeMismatch for
+// values that do not parse and ErrValidation for rule failures.
+//
+// Parameters:
+//   - target: The sentinel to compare with.
+//
+// Returns:
+//   - bool: Whether the error matches.
+func (e *FieldError) Is(target error) bool {
+	switch target {
+	case types.ErrTypeMismatch:
+		return e.Rule == "type"
+	case types.ErrValidation:
+		return e.Rule != "type" && e.Rule != "file"
+	}
+	return false
+}
+
+// MarshalJSON encodes the details and the message.
+//
+// Returns:
+//   - []byte: The JSON object.
+//   - error: Always nil.
+func (e *FieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field   string `json:"field"`
+		Key     string `json:"key"`
+		Rule    string `json:"rule"`
+		Value   string `json:"value,omitempty"`
+		Want    string `json:"want,omitempty"`
+		Message string `json:"message"`
+	}{e.Field, e.Key, e.Rule, e.Value, e.Want, e.Err.Error()})
+}
+
 // MultiError aggregates multiple errors into one.
 type MultiError []error
 
@@ -55,3 +215,12 @@ func (m MultiError) Error() string {
 	}
 	return b.String()
 }
+
+// Unwrap returns the aggregated errors, so errors.Is and errors.As
+// match any of them.
+//
+// Returns:
+//   - []error: The errors.
+func (m MultiError) Unwrap() []error {
+	return m
+}
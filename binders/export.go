@@ -0,0 +1,133 @@
+package binders
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aatuh/envvar/v2/redact"
+)
+
+// EnvMap renders the bound fields of cfg back into variable values in
+// the form Bind parses: lists joined with envsep, maps with envkvsep,
+// times with envlayout, and envjson fields as JSON. Nil pointers and
+// unset TriBools are omitted. Keys of fields tagged `secret:"true"` are
+// registered with redact.MarkSecret, so exporters such as
+// loaders.SystemdEnvironment reference them instead of inlining them.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct with env tags.
+//
+// Returns:
+//   - map[string]string: The values by variable name.
+//   - error: The error if cfg is not a struct or a JSON field fails to
+//     encode.
+func EnvMap(cfg any) (map[string]string, error) {
+	rv := reflect.ValueOf(cfg)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("envvar: EnvMap expects struct or pointer to struct")
+	}
+	out := map[string]string{}
+	if err := collectEnv(rv, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// collectEnv adds the tagged fields of rv, descending into untagged
+// embedded structs not already on path like Bind does.
+func collectEnv(rv reflect.Value, path []reflect.Type, out map[string]string) error {
+	rt := rv.Type()
+	path = append(path, rt)
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		fv := rv.Field(i)
+		ev, ok := f.Tag.Lookup("env")
+		if !ok {
+			if f.Anonymous {
+				if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+					fv = fv.Elem()
+				}
+				if fv.Kind() == reflect.Struct && !slices.Contains(path, fv.Type()) {
+					if err := collectEnv(fv, path, out); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		key := parseEnvTag(ev).names[0]
+		if strings.EqualFold(f.Tag.Get("secret"), "true") {
+			redact.MarkSecret(key)
+		}
+		if strings.EqualFold(f.Tag.Get("envjson"), "true") {
+			b, err := json.Marshal(fv.Interface())
+			if err != nil {
+				return fmt.Errorf("envvar: %s: %w", key, err)
+			}
+			out[key] = string(b)
+			continue
+		}
+		fo := fieldOpts{sep: f.Tag.Get("envsep"), kvSep: f.Tag.Get("envkvsep"),
+			layout: f.Tag.Get("envlayout")}
+		if fo.sep == "" {
+			fo.sep = ","
+		}
+		if fo.kvSep == "" {
+			fo.kvSep = "="
+		}
+		if s, ok := envValue(fv, fo); ok {
+			out[key] = s
+		}
+	}
+	return nil
+}
+
+// envValue formats v as Bind would read it; ok is false for values
+// that are not set.
+func envValue(v reflect.Value, fo fieldOpts) (string, bool) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return "", false
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		layout := fo.layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return t.Format(layout), true
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if _, ok := converterFor(v.Type()); !ok {
+			parts := make([]string, v.Len())
+			for i := range parts {
+				parts[i], _ = envValue(v.Index(i), fo)
+			}
+			return strings.Join(parts, fo.sep), true
+		}
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			s, _ := envValue(iter.Value(), fo)
+			pairs = append(pairs, iter.Key().String()+fo.kvSep+s)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, fo.sep), true
+	}
+	d := docValue(v, false)
+	if d == nil {
+		return "", false
+	}
+	return fmt.Sprint(d), true
+}
@@ -0,0 +1,105 @@
+package binders
+
+import (
+	"strings"
+)
+
+// defaultGroupSet names the set of envgroup tags without "set=".
+const defaultGroupSet = "envgroup"
+
+// groupField records how a field of an envgroup was bound.
+type groupField struct {
+	set, group string
+	// key is the variable that held the value, or the primary
+	// (prefixed) name when unset.
+	key string
+	// explicit means the value came from the environment or a prompt
+	// rather than an envdef default.
+	explicit bool
+	// has means the field received a value, defaults included.
+	has bool
+}
+
+// parseGroupTag splits an envgroup tag of the form "set=group". A tag
+// without "=" names a group of defaultGroupSet.
+func parseGroupTag(tag string) (string, string) {
+	set, group, ok := strings.Cut(tag, "=")
+	if !ok {
+		return defaultGroupSet, strings.TrimSpace(tag)
+	}
+	return strings.TrimSpace(set), strings.TrimSpace(group)
+}
+
+// checkGroups enforces the envgroup sets: within a set, exactly one
+// group must be fully set and the variables of the others must be
+// unset. Each violated set yields a single error naming the variables
+// involved.
+func (b *binder) checkGroups() {
+	var sets []string
+	bySet := map[string][]groupField{}
+	for _, g := range b.groups {
+		if _, ok := bySet[g.set]; !ok {
+			sets = append(sets, g.set)
+		}
+		bySet[g.set] = append(bySet[g.set], g)
+	}
+	for _, set := range sets {
+		var groups []string
+		members := map[string][]groupField{}
+		for _, g := range bySet[set] {
+			if _, ok := members[g.group]; !ok {
+				groups = append(groups, g.group)
+			}
+			members[g.group] = append(members[g.group], g)
+		}
+		var used []string
+		for _, name := range groups {
+			for _, g := range members[name] {
+				if g.explicit {
+					used = append(used, name)
+					break
+				}
+			}
+		}
+		fc := &fieldCtx{key: set}
+		switch len(used) {
+		case 0:
+			alts := make([]string, len(groups))
+			for i, name := range groups {
+				alts[i] = describeGroup(name, members[name], func(groupField) bool { return true })
+			}
+			b.fail(fc, ErrMissing, &KeyError{Key: set, Kind: ErrMissing,
+				Msg: "set one of " + strings.Join(alts, " or ")})
+		case 1:
+			var missing []string
+			for _, g := range members[used[0]] {
+				if !g.has {
+					missing = append(missing, g.key)
+				}
+			}
+			if len(missing) > 0 {
+				b.fail(fc, ErrMissing, &KeyError{Key: set, Kind: ErrMissing,
+					Msg: "group " + used[0] + " also needs " + strings.Join(missing, ", ")})
+			}
+		default:
+			parts := make([]string, len(used))
+			for i, name := range used {
+				parts[i] = describeGroup(name, members[name], func(g groupField) bool { return g.explicit })
+			}
+			b.fail(fc, ErrConflict, &KeyError{Key: set, Kind: ErrConflict,
+				Msg: strings.Join(parts, " and ") + " are mutually exclusive; set only one group"})
+		}
+	}
+}
+
+// describeGroup renders a group as "name (A, B)", listing the
+// variables of fields matching keep.
+func describeGroup(name string, fields []groupField, keep func(groupField) bool) string {
+	var keys []string
+	for _, g := range fields {
+		if keep(g) {
+			keys = append(keys, g.key)
+		}
+	}
+	return name + " (" + strings.Join(keys, ", ") + ")"
+}
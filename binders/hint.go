@@ -0,0 +1,81 @@
+package binders
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// missingHint describes a missing required field for the error
+// message: its envdesc tag, the expected format, and an example taken
+// from envexample or, failing that, a generic one for the type.
+func missingHint(f reflect.StructField, jsonMode bool, fo fieldOpts) string {
+	format, example := formatOf(f.Type, jsonMode, fo)
+	if ex, ok := f.Tag.Lookup("envexample"); ok {
+		example = ex
+	}
+	var b strings.Builder
+	if desc := f.Tag.Get("envdesc"); desc != "" {
+		b.WriteString(desc)
+		b.WriteString("; ")
+	}
+	b.WriteString("expected ")
+	b.WriteString(format)
+	if example != "" {
+		b.WriteString(", e.g. ")
+		b.WriteString(strconv.Quote(example))
+	}
+	return b.String()
+}
+
+// formatOf returns a human description of the values accepted for t
+// and a generic example.
+func formatOf(t reflect.Type, jsonMode bool, fo fieldOpts) (string, string) {
+	if jsonMode {
+		return "JSON", ""
+	}
+	if _, ok := converterFor(t); ok {
+		return t.String(), ""
+	}
+	if t.Kind() == reflect.Ptr {
+		if t.Elem().PkgPath() == "net/url" && t.Elem().Name() == "URL" {
+			return "absolute URL", "https://example.com"
+		}
+		return formatOf(t.Elem(), jsonMode, fo)
+	}
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return "duration", "30s"
+	case triBoolType:
+		return "boolean", "true"
+	case bytesType:
+		return "byte size", "512MB"
+	case prefixType, ipNetType:
+		return "CIDR", "10.0.0.0/8"
+	case timeType:
+		layout := fo.layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return "time in layout " + layout, time.Date(2024, 1, 31, 15, 4, 5, 0, time.UTC).Format(layout)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "boolean", "true"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer", "42"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "unsigned integer", "42"
+	case reflect.Float32, reflect.Float64:
+		return "number", "1.5"
+	case reflect.Slice:
+		elem, _ := formatOf(t.Elem(), false, fo)
+		return "list of " + elem + " separated by " + strconv.Quote(fo.sep), ""
+	case reflect.Map:
+		return "pairs such as " + strconv.Quote("a"+fo.kvSep+"1"+fo.sep+"b"+fo.kvSep+"2"), ""
+	}
+	return t.String(), ""
+}
@@ -0,0 +1,68 @@
+package binders
+
+import (
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// WithHook delivers the hook calls of this Bind to h as well as to the
+// global hook installed with types.SetHook, so components sharing a
+// process can observe their own binds without replacing each other's
+// hook. h may implement the optional ErrorHook, BindHook,
+// ValidationHook, DefaultHook, and MissHook.
+//
+// The scope is this Bind only: h sees the bind's errors, validation
+// failures, defaults, and misses, but not OnGet, OnLoad, or OnChange.
+// Getters, loaders, and watchers report only to the global hook, so
+// per-key read timings still need types.SetHook.
+//
+// Parameters:
+//   - h: The hook; nil adds none.
+//
+// Returns:
+//   - Option: The option.
+func WithHook(h types.Hook) Option {
+	return func(o *options) { o.hook = h }
+}
+
+// onError reports err to the global and bind hooks.
+func (b *binder) onError(err error) {
+	types.CallOnError("bind", err)
+	if eh, ok := b.hook.(types.ErrorHook); ok {
+		eh.OnError("bind", err)
+	}
+}
+
+// onBind reports a finished bind to the global and bind hooks.
+func (b *binder) onBind(structType string, dur time.Duration) {
+	types.CallOnBind(structType, b.fields, len(b.errs), dur)
+	if bh, ok := b.hook.(types.BindHook); ok {
+		bh.OnBind(structType, b.fields, len(b.errs), dur)
+	}
+}
+
+// onValidationError reports a failed rule to the global and bind
+// hooks.
+func (b *binder) onValidationError(field, rule, msg string) {
+	types.CallOnValidationError(field, rule, msg)
+	if vh, ok := b.hook.(types.ValidationHook); ok {
+		vh.OnValidationError(field, rule, msg)
+	}
+}
+
+// onDefaultUsed reports an applied envdef to the global and bind hooks.
+func (b *binder) onDefaultUsed(key, value string) {
+	types.CallOnDefaultUsed(key, value)
+	if dh, ok := b.hook.(types.DefaultHook); ok {
+		dh.OnDefaultUsed(key, value)
+	}
+}
+
+// onMissing reports an unset variable to the global and bind hooks.
+func (b *binder) onMissing(key string) {
+	types.CallOnMissing(key)
+	if mh, ok := b.hook.(types.MissHook); ok {
+		mh.OnMissing(key)
+	}
+}
@@ -0,0 +1,251 @@
+package binders
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// MarshalJSON renders the bound fields of cfg as an indented JSON
+// object keyed by variable name, for support bundles and --dump-config
+// flags. Durations, times, sizes, networks, and URLs are rendered in
+// the form they are configured with. With redactSecrets, values of
+// fields tagged `secret:"true"` and of secret-like keys are masked and
+// URL passwords hidden.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct with env tags.
+//   - redactSecrets: Whether to mask secrets.
+//
+// Returns:
+//   - []byte: The document.
+//   - error: The error if cfg is not a struct.
+func MarshalJSON(cfg any, redactSecrets bool) ([]byte, error) {
+	doc, err := document(cfg, redactSecrets)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// MarshalYAML is like MarshalJSON but renders a YAML mapping with one
+// variable per line. Composite values use flow style.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct with env tags.
+//   - redactSecrets: Whether to mask secrets.
+//
+// Returns:
+//   - []byte: The document.
+//   - error: The error if cfg is not a struct.
+func MarshalYAML(cfg any, redactSecrets bool) ([]byte, error) {
+	doc, err := document(cfg, redactSecrets)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for _, k := range keys {
+		// JSON scalars and flow collections are valid YAML.
+		v, err := json.Marshal(doc[k])
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s: %s\n", k, v)
+	}
+	return b.Bytes(), nil
+}
+
+// PrintConfig writes the bound fields of cfg, redacted, to back a
+// conventional --print-config flag. Formats are "table" (the default
+// for ""), one aligned "KEY  VALUE" row per variable with unset values
+// shown as "-"; "json", as MarshalJSON; and "yaml", as MarshalYAML.
+//
+// Parameters:
+//   - w: The writer.
+//   - cfg: A struct or pointer to struct with env tags.
+//   - format: "table", "json", or "yaml".
+//
+// Returns:
+//   - error: The error if cfg is not a struct, format is unknown, or
+//     writing fails.
+func PrintConfig(w io.Writer, cfg any, format string) error {
+	var out []byte
+	var err error
+	switch strings.ToLower(format) {
+	case "", "table":
+		out, err = configTable(cfg)
+	case "json":
+		if out, err = MarshalJSON(cfg, true); err == nil {
+			out = append(out, '\n')
+		}
+	case "yaml", "yml":
+		out, err = MarshalYAML(cfg, true)
+	default:
+		return fmt.Errorf("envvar: unknown config format %q (want table, json, or yaml)", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// configTable renders the redacted document of cfg as aligned rows.
+func configTable(cfg any) ([]byte, error) {
+	doc, err := document(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE")
+	for _, k := range keys {
+		var v string
+		switch x := doc[k].(type) {
+		case nil:
+			v = "-"
+		case string:
+			v = x
+		default:
+			js, err := json.Marshal(x)
+			if err != nil {
+				return nil, err
+			}
+			v = string(js)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", k, v)
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// document collects the bound fields of cfg by variable name.
+func document(cfg any, redactSecrets bool) (map[string]any, error) {
+	rv := reflect.ValueOf(cfg)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("envvar: Marshal expects struct or pointer to struct")
+	}
+	doc := map[string]any{}
+	collectDoc(rv, nil, redactSecrets, doc)
+	return doc, nil
+}
+
+// collectDoc adds the tagged fields of rv, descending into untagged
+// embedded structs not already on path like Bind does.
+func collectDoc(rv reflect.Value, path []reflect.Type, redactSecrets bool, doc map[string]any) {
+	rt := rv.Type()
+	path = append(path, rt)
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		fv := rv.Field(i)
+		ev, ok := f.Tag.Lookup("env")
+		if !ok {
+			if f.Anonymous {
+				if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+					fv = fv.Elem()
+				}
+				if fv.Kind() == reflect.Struct && !slices.Contains(path, fv.Type()) {
+					collectDoc(fv, path, redactSecrets, doc)
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		key := parseEnvTag(ev).names[0]
+		secret := strings.EqualFold(f.Tag.Get("secret"), "true")
+		if redactSecrets && (secret || redact.IsSecretKey(key)) {
+			doc[key] = redact.Mask
+			continue
+		}
+		doc[key] = docValue(fv, redactSecrets)
+	}
+}
+
+// docValue converts a field value to its document form.
+func docValue(v reflect.Value, redactSecrets bool) any {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		if u, ok := v.Interface().(*url.URL); ok {
+			if redactSecrets {
+				return u.Redacted()
+			}
+			return u.String()
+		}
+		return docValue(v.Elem(), redactSecrets)
+	}
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case types.Bytes:
+		return x.String()
+	case types.TriBool:
+		if !x.IsSet() {
+			return nil
+		}
+		return x == types.TriTrue
+	case netip.Prefix:
+		return x.String()
+	case net.IPNet:
+		return x.String()
+	case json.Marshaler:
+		return x
+	case fmt.Stringer:
+		return x.String()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = docValue(v.Index(i), redactSecrets)
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = docValue(iter.Value(), redactSecrets)
+		}
+		return out
+	}
+	return v.Interface()
+}
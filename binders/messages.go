@@ -0,0 +1,158 @@
+package binders
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/aatuh/envvar/v2/validate"
+)
+
+var (
+	// messagesMu protects kindMessages and ruleMessages.
+	messagesMu sync.RWMutex
+	// kindMessages maps error kinds to message templates.
+	kindMessages = map[ErrKind]*template.Template{}
+	// ruleMessages maps validation rule names to message templates.
+	ruleMessages = map[string]*template.Template{}
+)
+
+// SetKindMessage registers the message template for bind errors of
+// kind, e.g. `{{.Key}} is not set`. An empty tmpl removes it. See
+// SetRuleMessage for the template data.
+//
+// Parameters:
+//   - kind: The error kind.
+//   - tmpl: The text/template source.
+//
+// Returns:
+//   - error: The error if tmpl does not parse.
+func SetKindMessage(kind ErrKind, tmpl string) error {
+	t, err := parseMessage(kind.String(), tmpl)
+	if err != nil {
+		return err
+	}
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	if t == nil {
+		delete(kindMessages, kind)
+	} else {
+		kindMessages[kind] = t
+	}
+	return nil
+}
+
+// SetRuleMessage registers the message template for failures of the
+// validation rule, e.g. `{{.Field}} must be between {{.Min}} and
+// {{.Max}}` for "min". An empty tmpl removes it. Rule messages take
+// precedence over kind messages.
+//
+// Templates see Field (the Go field name), Key, Value (masked for
+// secrets), Kind, Rule, Param (the failed rule's parameter), Err (the
+// default message), and every rule parameter of the field's validate
+// tag under its camel-cased name, e.g. Min, Max, and RequiredWith.
+//
+// Parameters:
+//   - rule: The rule name.
+//   - tmpl: The text/template source.
+//
+// Returns:
+//   - error: The error if tmpl does not parse.
+func SetRuleMessage(rule, tmpl string) error {
+	t, err := parseMessage(rule, tmpl)
+	if err != nil {
+		return err
+	}
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	if t == nil {
+		delete(ruleMessages, rule)
+	} else {
+		ruleMessages[rule] = t
+	}
+	return nil
+}
+
+// parseMessage parses tmpl, returning nil for an empty template.
+func parseMessage(name, tmpl string) (*template.Template, error) {
+	if tmpl == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return nil, errors.New("envvar: invalid message template: " + err.Error())
+	}
+	return t, nil
+}
+
+// fieldCtx describes the field an error belongs to.
+type fieldCtx struct {
+	// field is the Go field name; empty for unknown keys.
+	field string
+	// key is the variable name.
+	key string
+	// rules is the field's validate tag.
+	rules string
+	// value is the raw value, masked for secrets.
+	value string
+}
+
+// messageError replaces the text of err with a registered message.
+type messageError struct {
+	msg string
+	err error
+}
+
+// Error returns the rendered message.
+func (e *messageError) Error() string { return e.msg }
+
+// Unwrap returns the original error.
+func (e *messageError) Unwrap() error { return e.err }
+
+// localize renders the registered message for err, if any. kind is the
+// error kind, or zero for validation failures.
+func localize(fc fieldCtx, kind ErrKind, err error) error {
+	var re *validate.RuleError
+	rule := ""
+	if errors.As(err, &re) {
+		rule = re.Rule
+	}
+	messagesMu.RLock()
+	t := ruleMessages[rule]
+	if t == nil {
+		t = kindMessages[kind]
+	}
+	messagesMu.RUnlock()
+	if t == nil {
+		return err
+	}
+	data := map[string]any{}
+	for _, r := range strings.Split(fc.rules, ",") {
+		if name, param, ok := strings.Cut(strings.TrimSpace(r), "="); ok {
+			data[camel(name)] = param
+		}
+	}
+	data["Field"], data["Key"], data["Value"] = fc.field, fc.key, fc.value
+	data["Kind"], data["Rule"], data["Err"] = kind.String(), rule, err.Error()
+	data["Param"] = ""
+	if re != nil {
+		data["Param"] = re.Param
+	}
+	var b strings.Builder
+	if t.Execute(&b, data) != nil {
+		return err
+	}
+	return &messageError{msg: b.String(), err: err}
+}
+
+// camel turns a rule name such as "required_with" into "RequiredWith".
+func camel(s string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(s, "_") {
+		if p != "" {
+			b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package binders
+
+import (
+	"context"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// Option configures binding.
+type Option func(*options)
+
+// options holds the binding configuration.
+type options struct {
+	// strict reports prefixed variables that map to no field.
+	strict bool
+	// strictWarn reports them to the error hook instead of failing.
+	strictWarn bool
+	// report receives the per-field resolution.
+	report *Report
+	// prompt, when set, asks for missing required variables.
+	prompt *Prompt
+	// version overrides AppVersion for envdeprecated checks.
+	version string
+	// warnings receives the soft issues.
+	warnings *Warnings
+	// hook receives hook calls next to the global hook.
+	hook types.Hook
+	// ctx, set by BindCtx, bounds lookups; nil means Background.
+	ctx context.Context
+	// plan, set by Compiled, replaces walking the destination type.
+	plan *structPlan
+}
+
+// WithStrict makes BindWithPrefix fail when the default source holds
+// variables with the prefix that map to no field, catching typos such
+// as MYAPP_PROT=8080 that would otherwise fall back to defaults.
+//
+// Returns:
+//   - Option: The option.
+func WithStrict() Option {
+	return func(o *options) { o.strict = true }
+}
+
+// WithStrictWarn is like WithStrict but reports unknown prefixed
+// variables to the error hook and lets the bind succeed.
+//
+// Returns:
+//   - Option: The option.
+func WithStrictWarn() Option {
+	return func(o *options) { o.strictWarn = true }
+}
+
+// buildOptions applies opts.
+func buildOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
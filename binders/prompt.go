@@ -0,0 +1,139 @@
+package binders
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/loaders"
+)
+
+// Prompt configures interactive prompting for missing required
+// variables, for CLI tools and first-run developer setups.
+type Prompt struct {
+	// In is read for answers. Defaults to os.Stdin. When In is a file
+	// that is not a terminal, prompting is skipped and missing variables
+	// fail as usual.
+	In io.Reader
+	// Out receives the questions. Defaults to os.Stderr.
+	Out io.Writer
+	// ReadSecret reads a secret answer without echoing it. Defaults to
+	// disabling terminal echo with stty while reading from In.
+	ReadSecret func() (string, error)
+	// SaveTo, when set, is an env file the answers are appended to, so
+	// the next run finds them.
+	SaveTo string
+}
+
+// WithPrompt asks for missing required variables on a terminal instead
+// of failing. Input is hidden for fields tagged `secret:"true"` and for
+// secret-like keys. Empty answers leave the variable missing.
+//
+// Parameters:
+//   - p: The prompt configuration.
+//
+// Returns:
+//   - Option: The option.
+func WithPrompt(p Prompt) Option {
+	return func(o *options) { o.prompt = &p }
+}
+
+// prompter asks for values during one Bind call.
+type prompter struct {
+	p       Prompt
+	r       *bufio.Reader
+	answers map[string]string
+}
+
+// newPrompter returns a prompter for p, or nil when In is a file that
+// is not a terminal.
+func newPrompter(p Prompt) *prompter {
+	if p.In == nil {
+		p.In = os.Stdin
+	}
+	if p.Out == nil {
+		p.Out = os.Stderr
+	}
+	if f, ok := p.In.(*os.File); ok && !isTerminal(f) {
+		return nil
+	}
+	pr := &prompter{p: p, r: bufio.NewReader(p.In), answers: map[string]string{}}
+	if pr.p.ReadSecret == nil {
+		pr.p.ReadSecret = pr.readHidden
+	}
+	return pr
+}
+
+// ask prompts for key, described by hint, and reports whether a
+// non-empty answer was given.
+func (pr *prompter) ask(key, hint string, secret bool) (string, bool) {
+	fmt.Fprintf(pr.p.Out, "%s (%s): ", key, hint)
+	var v string
+	var err error
+	if secret {
+		v, err = pr.p.ReadSecret()
+	} else {
+		v, err = pr.readLine()
+	}
+	if err != nil || v == "" {
+		return "", false
+	}
+	pr.answers[key] = v
+	return v, true
+}
+
+// readLine reads one line from In without its line ending.
+func (pr *prompter) readLine() (string, error) {
+	line, err := pr.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readHidden reads a line with terminal echo disabled when In is a
+// terminal and stty is available.
+func (pr *prompter) readHidden() (string, error) {
+	if f, ok := pr.p.In.(*os.File); ok && stty(f, "-echo") == nil {
+		defer func() {
+			_ = stty(f, "echo")
+			fmt.Fprintln(pr.p.Out)
+		}()
+	}
+	return pr.readLine()
+}
+
+// save appends the answers to SaveTo.
+func (pr *prompter) save() error {
+	if pr.p.SaveTo == "" || len(pr.answers) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(pr.p.SaveTo, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("envvar: save answers: %w", err)
+	}
+	_, err = f.Write(loaders.Marshal(pr.answers))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("envvar: save answers: %w", err)
+	}
+	return nil
+}
+
+// isTerminal reports whether f is a character device, e.g. a TTY.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// stty runs stty with arg on the terminal f.
+func stty(f *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
@@ -0,0 +1,67 @@
+package binders
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/expand"
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+// Provenance reports where each field of cfg would get its value, in
+// the form of a Bind report, without binding. It reads the default
+// source directly, so no hooks, events, or access records fire, and it
+// neither resolves references nor parses or validates values. Use it
+// for diagnostics that must not have side effects, such as support
+// bundles.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct with env tags.
+//
+// Returns:
+//   - []FieldReport: The fields in Bind order.
+//   - error: The error if cfg is not a struct.
+func Provenance(cfg any) ([]FieldReport, error) {
+	t := structType(cfg)
+	if t == nil {
+		return nil, errors.New("envvar: Provenance expects a struct or pointer to struct")
+	}
+	var out []FieldReport
+	provenance(planOf(t), &out)
+	return out, nil
+}
+
+// provenance appends the reports of the fields of plan to out.
+func provenance(plan *structPlan, out *[]FieldReport) {
+	for _, st := range plan.steps {
+		if st.field == nil {
+			provenance(st.embedded, out)
+			continue
+		}
+		p := st.field
+		fr := FieldReport{Field: p.f.Name, Key: strings.Join(p.tag.names, "|")}
+		var raw string
+		var ok bool
+		for _, n := range p.tag.names {
+			if raw, ok = sources.Lookup(n); ok {
+				fr.Key, fr.Source = n, sources.Origin(sources.Default(), n)
+				break
+			}
+		}
+		if !ok && p.def != "" {
+			raw, ok = p.def, true
+			fr.Source, fr.Default = "envdef", true
+		}
+		if ok {
+			fr.Value = raw
+			if v, err := expand.ExpandWith(raw, sources.LookupOrEnv); err == nil {
+				fr.Value, fr.Expanded = v, v != raw
+			}
+			if p.secret || redact.IsSecretKey(fr.Key) {
+				fr.Value = redact.Mask
+			}
+		}
+		*out = append(*out, fr)
+	}
+}
@@ -0,0 +1,72 @@
+package binders
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldReport describes how one field was resolved.
+type FieldReport struct {
+	// Field is the Go field name.
+	Field string
+	// Key is the variable that supplied the value, or the tag's key
+	// list when none was present.
+	Key string
+	// Source names the source that supplied the value, "envdef" when
+	// the default was applied, "prompt" when it was entered
+	// interactively, or "" when the field was left unset.
+	Source string
+	// Value is the raw value, redacted for fields tagged
+	// `secret:"true"` and secret-like keys.
+	Value string
+	// Default reports whether envdef was applied.
+	Default bool
+	// Expanded reports whether ${...} expansion changed the value.
+	Expanded bool
+	// Coerced lists how parsing changed the value, such as trimmed
+	// whitespace or digits lost to float precision.
+	Coerced []string
+}
+
+// Report lists how each field of a Bind call was resolved, in field
+// order.
+type Report struct {
+	Fields []FieldReport
+}
+
+// String renders one line per field, suitable for startup logs.
+//
+// Returns:
+//   - string: The report.
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, f := range r.Fields {
+		src := f.Source
+		if src == "" {
+			src = "unset"
+		}
+		fmt.Fprintf(&b, "%s: %s from %s", f.Field, f.Key, src)
+		if f.Source != "" {
+			fmt.Fprintf(&b, " = %q", f.Value)
+		}
+		if f.Expanded {
+			b.WriteString(" (expanded)")
+		}
+		if len(f.Coerced) > 0 {
+			fmt.Fprintf(&b, " (coerced: %s)", strings.Join(f.Coerced, "; "))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// WithReport fills r with the resolution of every bound field.
+//
+// Parameters:
+//   - r: The report to fill; previous contents are replaced.
+//
+// Returns:
+//   - Option: The option.
+func WithReport(r *Report) Option {
+	return func(o *options) { o.report = r }
+}
@@ -0,0 +1,125 @@
+package binders
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/sources"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// SchemaKey is the variable holding the fingerprint of the schema an
+// environment was rendered for, compared by CheckDeployedSchema.
+const SchemaKey = "ENVVAR_SCHEMA_FINGERPRINT"
+
+// ErrSchemaMismatch is returned (wrapped) when a deployed fingerprint
+// does not match the struct.
+var ErrSchemaMismatch = errors.New("envvar: schema mismatch")
+
+// schemaTags are the tags that affect how a field is bound. Purely
+// descriptive tags do not change the fingerprint.
+var schemaTags = []string{"env", "envdef", "envsep", "envkvsep", "envjson", "envfile", "envlayout", "envgroup", "validate"}
+
+// SchemaFingerprint returns a stable hash of the variables cfg binds:
+// their names, Go types, and binding and validation tags. Field order
+// and names do not matter, so the fingerprint only changes when the
+// expected environment does.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct.
+//
+// Returns:
+//   - string: The fingerprint, "sha256:<hex>".
+func SchemaFingerprint(cfg any) string {
+	var lines []string
+	if t := structType(cfg); t != nil {
+		schemaLines(t, nil, &lines)
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// CheckSchema compares a deployed fingerprint with the one of cfg.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct.
+//   - deployed: The deployed fingerprint.
+//
+// Returns:
+//   - error: An error wrapping ErrSchemaMismatch if they differ.
+func CheckSchema(cfg any, deployed string) error {
+	want := SchemaFingerprint(cfg)
+	if got := strings.TrimSpace(deployed); got != want {
+		return fmt.Errorf("%w: deployed %s, binary expects %s",
+			ErrSchemaMismatch, got, want)
+	}
+	return nil
+}
+
+// CheckDeployedSchema compares SchemaKey from the default source with
+// the fingerprint of cfg. It returns nil when SchemaKey is unset. A
+// mismatch is also reported through types.ErrorHook so drift can be
+// alerted on.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct.
+//
+// Returns:
+//   - error: An error wrapping ErrSchemaMismatch if they differ.
+func CheckDeployedSchema(cfg any) error {
+	deployed, ok := sources.Lookup(SchemaKey)
+	if !ok {
+		return nil
+	}
+	err := CheckSchema(cfg, deployed)
+	if err != nil {
+		types.CallOnError("schema", err)
+	}
+	return err
+}
+
+// structType returns the struct type of cfg, or nil.
+func structType(cfg any) reflect.Type {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// schemaLines appends one canonical line per tagged field of t,
+// descending into untagged embedded structs not already on path like
+// Bind.
+func schemaLines(t reflect.Type, path []reflect.Type, out *[]string) {
+	path = append(path, t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("env"); !ok {
+			if et := embeddedStruct(f.Type); f.Anonymous && et != nil && !slices.Contains(path, et) {
+				schemaLines(et, path, out)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(f.Type.String())
+		for _, name := range schemaTags {
+			if v, ok := f.Tag.Lookup(name); ok {
+				fmt.Fprintf(&b, " %s=%q", name, v)
+			}
+		}
+		*out = append(*out, b.String())
+	}
+}
@@ -0,0 +1,100 @@
+package binders
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Warning is a soft issue found while binding that does not fail it,
+// such as a variable set through a fallback name.
+type Warning struct {
+	// Field is the Go field name, or "" for unknown variables.
+	Field string
+	// Key is the variable concerned.
+	Key string
+	// Msg describes the issue.
+	Msg string
+}
+
+// String returns the warning as one line.
+//
+// Returns:
+//   - string: The warning.
+func (w Warning) String() string {
+	if w.Field == "" {
+		return w.Key + ": " + w.Msg
+	}
+	return w.Field + " (" + w.Key + "): " + w.Msg
+}
+
+// Warnings lists the warnings of a Bind call in field order.
+type Warnings []Warning
+
+// String renders one line per warning, suitable for startup logs.
+//
+// Returns:
+//   - string: The warnings.
+func (ws Warnings) String() string {
+	var b strings.Builder
+	for _, w := range ws {
+		b.WriteString(w.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// WithWarnings fills ws with the soft issues of the bind:
+//
+//   - a variable set through a fallback name of `env:"NEW|OLD"`;
+//   - a variable tagged envdeprecated that has not reached removal;
+//   - a required variable satisfied only by its envdef default;
+//   - a lenient boolean such as "yes" or "on" accepted;
+//   - an unknown prefixed variable under WithStrictWarn.
+//
+// Parameters:
+//   - ws: The warnings to fill; previous contents are replaced.
+//
+// Returns:
+//   - Option: The option.
+func WithWarnings(ws *Warnings) Option {
+	return func(o *options) { o.warnings = ws }
+}
+
+// BindWith is like Bind but also returns the soft issues found, so
+// callers can log them without failing startup. See WithWarnings.
+//
+// Parameters:
+//   - dst: The destination.
+//   - opts: The options.
+//
+// Returns:
+//   - Warnings: The warnings, even when binding fails.
+//   - error: The error if the binding fails.
+func BindWith(dst any, opts ...Option) (Warnings, error) {
+	var ws Warnings
+	err := Bind(dst, append(opts, WithWarnings(&ws))...)
+	return ws, err
+}
+
+// warn records a warning for fc when warnings are collected.
+func (b *binder) warn(fc *fieldCtx, msg string) {
+	if b.warnings != nil {
+		*b.warnings = append(*b.warnings, Warning{Field: fc.field, Key: fc.key, Msg: msg})
+	}
+}
+
+// lenientBool reports whether raw, bound into a bool field of type t,
+// is accepted only by the lenient parser.
+func lenientBool(t reflect.Type, raw string) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Bool {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "true", "false", "1", "0":
+		return false
+	}
+	return true
+}
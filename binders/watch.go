@@ -0,0 +1,154 @@
+package binders
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aatuh/envvar/v2/loaders"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// WatchOptions configures BindAndWatch.
+type WatchOptions[T any] struct {
+	// Prefix is passed to BindWithPrefix.
+	Prefix string
+	// Files are env files to watch. Their changes are applied to the
+	// process env before re-binding. Leave empty to only poll the
+	// default source.
+	Files []string
+	// Load configures reading Files, e.g. loaders.WithVerifier.
+	Load []loaders.Option
+	// Interval is the polling interval. Defaults to
+	// loaders.DefaultWatchInterval.
+	Interval time.Duration
+	// OnChange is called after a successful re-bind that changed the
+	// struct. old and new must not be modified.
+	OnChange func(old, new *T)
+}
+
+// Watched holds the latest successfully bound value of a watched struct.
+type Watched[T any] struct {
+	base     T
+	opts     WatchOptions[T]
+	files    *loaders.Watcher
+	cur      atomic.Pointer[T]
+	mu       sync.Mutex
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// BindAndWatch binds dst and keeps re-binding a fresh copy whenever the
+// underlying values change. Each re-bind starts from the value dst had
+// before the first bind (copied shallowly), so preset fields behave as
+// with Bind. A re-bind that fails leaves the current value in place
+// and reports the error through types.ErrorHook; a successful one
+// that changes the struct is published atomically and passed to
+// OnChange.
+//
+// dst receives only the initial value. Read later values with Current.
+//
+// Parameters:
+//   - dst: The destination struct.
+//   - opts: The options.
+//
+// Returns:
+//   - *Watched[T]: The running watch.
+//   - error: The error if the initial bind or file read fails.
+func BindAndWatch[T any](dst *T, opts WatchOptions[T]) (*Watched[T], error) {
+	w := &Watched[T]{
+		base: *dst,
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := BindWithPrefix(dst, opts.Prefix); err != nil {
+		return nil, err
+	}
+	first := *dst
+	w.cur.Store(&first)
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = loaders.DefaultWatchInterval
+	}
+	if len(opts.Files) > 0 {
+		fw, err := loaders.Watch(opts.Files, loaders.WatchOptions{
+			// Polled from Refresh instead of its own loop.
+			Interval: -1,
+			Load:     opts.Load,
+		})
+		if err != nil {
+			return nil, err
+		}
+		w.files = fw
+	}
+	// Create the ticker before returning so clock advances made right
+	// after BindAndWatch are observed.
+	go w.loop(types.CurrentClock().NewTicker(interval))
+	return w, nil
+}
+
+// Current returns the latest successfully bound value. The returned
+// value must not be modified.
+//
+// Returns:
+//   - *T: The current value.
+func (w *Watched[T]) Current() *T {
+	return w.cur.Load()
+}
+
+// Refresh re-reads the watched files, re-binds, and publishes the
+// result if it changed.
+//
+// Returns:
+//   - bool: Whether the value changed.
+//   - error: The error if reading or binding fails.
+func (w *Watched[T]) Refresh() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.files != nil {
+		if _, err := w.files.Poll(); err != nil {
+			return false, err
+		}
+	}
+	next := w.base
+	if err := BindWithPrefix(&next, w.opts.Prefix); err != nil {
+		return false, err
+	}
+	old := w.cur.Load()
+	if reflect.DeepEqual(*old, next) {
+		return false, nil
+	}
+	w.cur.Store(&next)
+	if w.opts.OnChange != nil {
+		w.opts.OnChange(old, &next)
+	}
+	return true, nil
+}
+
+// Stop stops polling. It is safe to call more than once.
+func (w *Watched[T]) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+	if w.files != nil {
+		w.files.Stop()
+	}
+}
+
+// loop refreshes until stopped.
+func (w *Watched[T]) loop(t types.Ticker) {
+	defer close(w.done)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C():
+			if _, err := w.Refresh(); err != nil {
+				types.CallOnError("bind", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Command envvargen generates key constants and typed accessors from a
+// tagged config struct. Typical use:
+//
+//	//go:generate go run github.com/aatuh/envvar/v2/cmd/envvargen -type Config -pkg cfgkeys -o cfgkeys/keys_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aatuh/envvar/v2/gen"
+)
+
+func main() {
+	typeName := flag.String("type", "", "struct type name (required)")
+	pkg := flag.String("pkg", "", "package name of the output (default: input package)")
+	in := flag.String("in", os.Getenv("GOFILE"), "input Go file (default: $GOFILE)")
+	out := flag.String("o", "", "output file (default: stdout)")
+	flag.Parse()
+
+	if *typeName == "" || *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	src, err := os.ReadFile(*in)
+	if err != nil {
+		fatal(err)
+	}
+	code, err := gen.Generate(*in, src, gen.Options{TypeName: *typeName, Package: *pkg})
+	if err != nil {
+		fatal(err)
+	}
+	if *out == "" {
+		_, _ = os.Stdout.Write(code)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+// fatal prints err and exits.
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "envvargen:", err)
+	os.Exit(1)
+}
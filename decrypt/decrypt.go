@@ -0,0 +1,174 @@
+// Package decrypt resolves encrypted values of the form
+// "enc:<version>:<base64 ciphertext>" through registered Decryptors, so
+// secrets can be stored encrypted at rest inside ordinary env vars and
+// ConfigMaps.
+package decrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Prefix marks an encrypted value.
+const Prefix = "enc:"
+
+// Decryptor turns ciphertext into plaintext. Implementations may wrap
+// KMS, age, or a local key.
+type Decryptor interface {
+	// Decrypt returns the plaintext for ciphertext.
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// DecryptorFunc adapts a function to Decryptor.
+type DecryptorFunc func(ciphertext []byte) ([]byte, error)
+
+// Decrypt calls f.
+func (f DecryptorFunc) Decrypt(ciphertext []byte) ([]byte, error) {
+	return f(ciphertext)
+}
+
+var (
+	// regMu protects registry.
+	regMu sync.RWMutex
+	// registry maps version tags to decryptors.
+	registry = map[string]Decryptor{}
+)
+
+// Register installs d for values tagged with version, e.g. "v1".
+// Passing a nil d removes the registration.
+//
+// Parameters:
+//   - version: The version tag.
+//   - d: The decryptor.
+func Register(version string, d Decryptor) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	if d == nil {
+		delete(registry, version)
+		return
+	}
+	registry[version] = d
+}
+
+// Reset removes every registered decryptor. Meant for tests; see
+// envvartest.ResetGlobalState.
+func Reset() {
+	regMu.Lock()
+	defer regMu.Unlock()
+	registry = map[string]Decryptor{}
+}
+
+// IsEncrypted reports whether s uses the encrypted value syntax.
+//
+// Parameters:
+//   - s: The value.
+//
+// Returns:
+//   - bool: True if s starts with Prefix.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Value returns s unchanged unless it is encrypted, in which case it is
+// decoded and decrypted with the decryptor registered for its version.
+//
+// Parameters:
+//   - s: The value.
+//
+// Returns:
+//   - string: The plaintext.
+//   - error: The error if the value is malformed or decryption fails.
+func Value(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	version, payload, ok := strings.Cut(s[len(Prefix):], ":")
+	if !ok || version == "" {
+		return "", errors.New("malformed encrypted value")
+	}
+	regMu.RLock()
+	d := registry[version]
+	regMu.RUnlock()
+	if d == nil {
+		return "", fmt.Errorf("no decryptor registered for %q", version)
+	}
+	ct, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted payload: %w", err)
+	}
+	pt, err := d.Decrypt(ct)
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", version, err)
+	}
+	return string(pt), nil
+}
+
+// aesGCM implements Decryptor with AES-GCM. Ciphertext is nonce||sealed.
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM returns a Decryptor using AES-GCM with a 16, 24, or 32 byte
+// key. The ciphertext layout is the nonce followed by the sealed data.
+//
+// Parameters:
+//   - key: The AES key.
+//
+// Returns:
+//   - Decryptor: The decryptor.
+//   - error: The error if the key is invalid.
+func NewAESGCM(key []byte) (Decryptor, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCM{aead: aead}, nil
+}
+
+// Decrypt opens nonce||sealed.
+func (a *aesGCM) Decrypt(ct []byte) ([]byte, error) {
+	n := a.aead.NonceSize()
+	if len(ct) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return a.aead.Open(nil, ct[:n], ct[n:], nil)
+}
+
+// SealAESGCM encrypts plaintext with AES-GCM and returns the full
+// "enc:<version>:<base64>" value, ready to be placed in an env var.
+//
+// Parameters:
+//   - version: The version tag.
+//   - key: The AES key.
+//   - plaintext: The value to encrypt.
+//
+// Returns:
+//   - string: The encrypted value.
+//   - error: The error if encryption fails.
+func SealAESGCM(version string, key, plaintext []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	ct := aead.Seal(nonce, nonce, plaintext, nil)
+	return Prefix + version + ":" + base64.StdEncoding.EncodeToString(ct), nil
+}
+
+// newAEAD builds an AES-GCM AEAD.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,33 @@
+package decrypt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealAndValue(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	d, err := NewAESGCM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Register("vt", d)
+	defer Register("vt", nil)
+
+	enc, err := SealAESGCM("vt", key, []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Value(enc); err != nil || got != "hunter2" {
+		t.Fatalf("Value: %q %v", got, err)
+	}
+	if got, err := Value("plain"); err != nil || got != "plain" {
+		t.Fatalf("plain passthrough: %q %v", got, err)
+	}
+	if _, err := Value("enc:nope:AAAA"); err == nil {
+		t.Fatalf("expected unregistered version error")
+	}
+	if _, err := Value(enc[:len(enc)-4] + "AAAA"); err == nil {
+		t.Fatalf("expected tamper error")
+	}
+}
@@ -1,16 +1,31 @@
 package envvar
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aatuh/envvar/v2/binders"
+	"github.com/aatuh/envvar/v2/decrypt"
+	"github.com/aatuh/envvar/v2/expand"
 	"github.com/aatuh/envvar/v2/getters"
 	"github.com/aatuh/envvar/v2/lazy"
 	"github.com/aatuh/envvar/v2/loaders"
+	"github.com/aatuh/envvar/v2/policy"
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/sources"
 	"github.com/aatuh/envvar/v2/types"
 )
 
@@ -18,6 +33,30 @@ import (
 // Provide your own implementation and register with SetHook.
 type Hook = types.Hook
 
+// ErrorHook is an optional Hook extension notified of background errors,
+// such as failed source refreshes.
+type ErrorHook = types.ErrorHook
+
+// ChangeHook is an optional Hook extension notified when watched env
+// files change.
+type ChangeHook = types.ChangeHook
+
+// BindHook is an optional Hook extension notified when a Bind call
+// finishes.
+type BindHook = types.BindHook
+
+// ValidationHook is an optional Hook extension notified when a field
+// fails a validation rule.
+type ValidationHook = types.ValidationHook
+
+// DefaultHook is an optional Hook extension notified when an Or getter
+// or envdef default is used.
+type DefaultHook = types.DefaultHook
+
+// MissHook is an optional Hook extension notified when a lookup finds
+// no value.
+type MissHook = types.MissHook
+
 // SetHook installs a global hook. It is safe to call at program init.
 //
 // Parameters:
@@ -26,8 +65,293 @@ func SetHook(h Hook) {
 	types.SetHook(h)
 }
 
+// AsyncHook runs a Hook's calls on a background goroutine through a
+// bounded queue, dropping and counting calls instead of blocking.
+type AsyncHook = types.AsyncHook
+
+// NewAsyncHook wraps h so slow hooks cannot block getters. Install the
+// result with SetHook and Close it on shutdown; Dropped reports calls
+// lost to a full queue.
+//
+// Parameters:
+//   - h: The hook to call asynchronously.
+//   - size: The queue capacity; <= 0 means 1024.
+//
+// Returns:
+//   - *AsyncHook: The running hook.
+func NewAsyncHook(h Hook, size int) *AsyncHook {
+	return types.NewAsyncHook(h, size)
+}
+
+// Source resolves raw values by key. See the sources package for
+// implementations.
+type Source = sources.Source
+
+// ContextSource is implemented by sources whose lookups honor deadlines
+// and cancellation; GetCtx and BindCtx use it.
+type ContextSource = sources.ContextSource
+
+// Limits bounds value sizes, key counts, and total environment size.
+// Zero fields are unlimited.
+type Limits = types.Limits
+
+// SetLimits installs global limits enforced by getters, Bind, and the
+// env file loaders. Oversized values fail with an error instead of
+// being decoded.
+//
+// Parameters:
+//   - l: The limits. The zero Limits disables them.
+func SetLimits(l Limits) {
+	types.SetLimits(l)
+}
+
+// SetClock installs the clock used for hook timings, load records, and
+// watcher polling; nil restores the system clock. envvartest.Clock is a
+// manually advanced implementation for tests.
+//
+// Parameters:
+//   - c: The clock.
+func SetClock(c types.Clock) {
+	types.SetClock(c)
+}
+
+// Sentinel errors for errors.Is on getter and Bind errors, including
+// the MultiError returned by Bind.
+var (
+	ErrMissingVar   = types.ErrMissingVar
+	ErrTypeMismatch = types.ErrTypeMismatch
+	ErrValidation   = types.ErrValidation
+)
+
+// Event is one envvar activity record delivered by Events.
+type Event = types.Event
+
+// EventKind classifies an Event.
+type EventKind = types.EventKind
+
+// Event kinds.
+const (
+	EventLoaded           = types.EventLoaded
+	EventGet              = types.EventGet
+	EventMiss             = types.EventMiss
+	EventDeprecated       = types.EventDeprecated
+	EventChanged          = types.EventChanged
+	EventValidationFailed = types.EventValidationFailed
+)
+
+// Events returns a new channel receiving loads, reads, misses, changes,
+// and validation failures as typed events, an alternative to SetHook
+// that is easy to consume from one goroutine. Events are dropped when
+// the buffer is full. Release the channel with StopEvents.
+//
+// Returns:
+//   - <-chan Event: The event stream.
+func Events() <-chan Event {
+	return types.Events()
+}
+
+// StopEvents unsubscribes and closes a channel returned by Events.
+//
+// Parameters:
+//   - ch: The channel.
+func StopEvents(ch <-chan Event) {
+	types.StopEvents(ch)
+}
+
+// Profile is a strictness profile: ProfileDev (lenient), ProfileStaging
+// (warn through ErrorHook), or ProfileProd (fail).
+type Profile = types.Profile
+
+// Strictness profiles.
+const (
+	ProfileDev     = types.ProfileDev
+	ProfileStaging = types.ProfileStaging
+	ProfileProd    = types.ProfileProd
+)
+
+// SetProfile selects the strictness profile, overriding APP_ENV and
+// GO_ENV. Under ProfileProd booleans must be true, false, 1, or 0, Or
+// getters panic instead of silently falling back on unparsable values,
+// BindWithPrefix rejects unknown prefixed keys, and LoadOnce requires a
+// file. The empty profile restores detection from the environment.
+//
+// Parameters:
+//   - p: The profile.
+func SetProfile(p Profile) {
+	types.SetProfile(p)
+}
+
+// CurrentProfile returns the active strictness profile.
+//
+// Returns:
+//   - Profile: The profile.
+func CurrentProfile() Profile {
+	return types.CurrentProfile()
+}
+
+// CheckLimits checks the whole default source against the limits set
+// with SetLimits.
+//
+// Returns:
+//   - error: An error wrapping types.ErrLimit listing every violation.
+func CheckLimits() error {
+	return sources.CheckLimits(sources.Default())
+}
+
+// ReadOnlyView returns a view of the default source restricted to the
+// keys starting with prefix, with the prefix stripped. Hand it to
+// libraries so they can read their own configuration but nothing
+// outside their namespace.
+//
+// Parameters:
+//   - prefix: The namespace prefix, e.g. "PAYMENTS_".
+//
+// Returns:
+//   - Source: The view.
+func ReadOnlyView(prefix string) Source {
+	return sources.ReadOnlyView(sources.Default(), prefix)
+}
+
+// Warmup concurrently pre-resolves keys from the default source and
+// caches them, so slow sources are queried during startup rather than
+// on the first request. The default source is wrapped with
+// sources.WarmCache on first use, which caches only warmed keys; other
+// keys are read through. Loading env files and Watcher reloads drop
+// the keys they change from the cache. Progress is reported through
+// the OnGet hook per key and OnLoad("warmup", n) at the end.
+//
+// Parameters:
+//   - ctx: Bounds the warmup.
+//   - keys: The keys to warm.
+//
+// Returns:
+//   - error: The context or reference resolution error, if any.
+func Warmup(ctx context.Context, keys ...string) error {
+	c, ok := sources.Default().(*sources.Cached)
+	if !ok {
+		c = sources.WarmCache(sources.Default())
+		sources.SetDefault(c)
+	}
+	return c.Warmup(ctx, keys...)
+}
+
+// SortedKeys returns the keys of src in sorted order, or nil if src
+// cannot list its keys.
+//
+// Parameters:
+//   - src: The source.
+//
+// Returns:
+//   - []string: The sorted keys.
+func SortedKeys(src Source) []string {
+	return sources.SortedKeys(src)
+}
+
+// Route directs keys matching a pattern to a single source.
+type Route = sources.Route
+
+// SetSource installs the source used by getters and Bind. Passing nil
+// restores the process environment.
+//
+// Parameters:
+//   - src: The source to install.
+func SetSource(src Source) {
+	sources.SetDefault(src)
+}
+
+// NewRouter returns a source that resolves keys matching a route only
+// from that route's source and everything else from fallback.
+//
+// Parameters:
+//   - fallback: The source for keys matching no route.
+//   - routes: The routing rules.
+//
+// Returns:
+//   - *sources.Router: The router.
+//   - error: The error if a route is invalid.
+func NewRouter(fallback Source, routes ...Route) (*sources.Router, error) {
+	return sources.NewRouter(fallback, routes...)
+}
+
+// SetStrictExpansion makes ${NAME} and $NAME references to undefined
+// variables fail with a missing error for NAME instead of silently
+// expanding to the empty string. Use ${NAME:-} to allow an empty
+// expansion explicitly.
+//
+// Parameters:
+//   - on: Whether strict expansion is enabled.
+func SetStrictExpansion(on bool) {
+	expand.SetStrict(on)
+}
+
+// EnableExpansionFuncs sets the functions, such as file, b64, and
+// urlencode, that values may call with ${name:arg}. None are enabled by
+// default; see expand.EnableFuncs.
+//
+// Parameters:
+//   - names: The functions to enable; none disables all.
+//
+// Returns:
+//   - error: The error if a name is not registered.
+func EnableExpansionFuncs(names ...string) error {
+	return expand.EnableFuncs(names...)
+}
+
+// ExpandMap expands ${NAME} references, including the :-, :+, and :?
+// operators, in the values of in, using keys of in first and then the
+// process environment. Returns a new map.
+//
+// Parameters:
+//   - in: The map to expand.
+//
+// Returns:
+//   - map[string]string: The expanded map.
+//   - error: The error of the first failed ${NAME:?message} reference.
+func ExpandMap(in map[string]string) (map[string]string, error) {
+	return expand.ExpandMapE(in)
+}
+
+// MustExpandMap is like ExpandMap but panics on failure.
+//
+// Parameters:
+//   - in: The map to expand.
+//
+// Returns:
+//   - map[string]string: The expanded map.
+func MustExpandMap(in map[string]string) map[string]string {
+	return expand.MustExpandMap(in)
+}
+
+// Decryptor decrypts "enc:<version>:<base64>" values.
+type Decryptor = decrypt.Decryptor
+
+// RegisterDecryptor installs d for encrypted values tagged with version.
+// Getters and Bind then transparently decrypt such values.
+//
+// Parameters:
+//   - version: The version tag, e.g. "v1".
+//   - d: The decryptor.
+func RegisterDecryptor(version string, d Decryptor) {
+	decrypt.Register(version, d)
+}
+
+// Resolver resolves "ref+<scheme>://..." reference values.
+type Resolver = refs.Resolver
+
+// RegisterResolver installs r for references with the given scheme,
+// e.g. "vault" for "ref+vault://secret/db#password". The Vault and SSM
+// sources implement Resolver.
+//
+// Parameters:
+//   - scheme: The scheme without the "ref+" prefix.
+//   - r: The resolver.
+func RegisterResolver(scheme string, r Resolver) {
+	refs.Register(scheme, r)
+}
+
 // MustLoadEnvVars loads variables from the first existing path in paths.
-// If paths is nil, it tries ".env" then "/env/.env". It panics on
+// If paths is nil, it tries DefaultPaths (".env", a per-user config
+// location, then "/env/.env" on Unix). It panics on
 // read/parse error. Re-entrant calls are no-ops.
 //
 // Parameters:
@@ -38,6 +362,164 @@ func MustLoadEnvVars(paths []string) {
 	}
 }
 
+// SetDefaultPaths replaces the paths tried when MustLoadEnvVars is
+// called with nil. Passing an empty slice restores the platform
+// defaults.
+//
+// Parameters:
+//   - paths: The paths, in order of preference.
+func SetDefaultPaths(paths []string) {
+	loaders.SetDefaultPaths(paths)
+}
+
+// DefaultPaths returns the paths tried when MustLoadEnvVars is called
+// with nil.
+//
+// Returns:
+//   - []string: The paths, in order of preference.
+func DefaultPaths() []string {
+	return loaders.DefaultPaths()
+}
+
+// LoadProfile loads .env, .env.<profile>, .env.local, and
+// .env.<profile>.local from the working directory, later files
+// overriding earlier ones. An empty profile is read from APP_ENV, then
+// GO_ENV.
+//
+// Parameters:
+//   - profile: The profile, or "" to detect it.
+//   - opts: The loading options.
+//
+// Returns:
+//   - error: The error if loading fails.
+func LoadProfile(profile string, opts ...loaders.Option) error {
+	return loaders.LoadProfile(profile, opts...)
+}
+
+// LoadEnvFile loads a single file into the process environment. Pass
+// loaders.WithVerifier to refuse files whose detached checksum or
+// signature (".env.sig") does not match.
+//
+// Parameters:
+//   - path: The path to load.
+//   - opts: The loading options.
+//
+// Returns:
+//   - error: The error if loading or verification fails.
+func LoadEnvFile(path string, opts ...loaders.Option) error {
+	return loaders.Load(path, opts...)
+}
+
+// ValidName reports whether key is a portable environment variable
+// name: ASCII letters, digits, and underscores, not starting with a
+// digit.
+//
+// Parameters:
+//   - key: The name.
+//
+// Returns:
+//   - error: The error describing why the name is invalid, or nil.
+func ValidName(key string) error {
+	return types.ValidName(key)
+}
+
+// NormalizeName turns s into a valid variable name, e.g. "db.host-name"
+// becomes "DB_HOST_NAME". Use NormalizeNames to detect collisions.
+//
+// Parameters:
+//   - s: The raw name.
+//
+// Returns:
+//   - string: The normalized name.
+func NormalizeName(s string) string {
+	return types.NormalizeName(s)
+}
+
+// NormalizeNames normalizes each name and reports distinct inputs that
+// map to the same variable.
+//
+// Parameters:
+//   - names: The raw names.
+//
+// Returns:
+//   - map[string]string: The normalized name of each input.
+//   - error: The error listing colliding inputs, or nil.
+func NormalizeNames(names []string) (map[string]string, error) {
+	return types.NormalizeNames(names)
+}
+
+// Policy is a named rule evaluated over the whole environment.
+type Policy = policy.Policy
+
+// PinPolicy selects what a pin mismatch does: PinAlert reports it to
+// ErrorHook, PinFail also fails reads, binds, and VerifyPins.
+type PinPolicy = types.PinPolicy
+
+// Pin policies.
+const (
+	PinAlert = types.PinAlert
+	PinFail  = types.PinFail
+)
+
+// ErrPinMismatch is matched by errors for pinned variables whose value
+// does not hash to the pinned digest.
+var ErrPinMismatch = types.ErrPinMismatch
+
+// Pin pins a security-sensitive variable, such as an OIDC issuer URL,
+// to the SHA-256 digest of its expected value (see PinHash). Resolved
+// values are compared on every read and bind.
+//
+// Parameters:
+//   - key: The variable to pin.
+//   - expectedHash: The digest, "sha256:<hex>" or bare hex.
+//
+// Returns:
+//   - error: The error if expectedHash is not a SHA-256 digest.
+func Pin(key, expectedHash string) error {
+	return types.Pin(key, expectedHash)
+}
+
+// PinHash returns the digest of value for Pin.
+//
+// Parameters:
+//   - value: The value.
+//
+// Returns:
+//   - string: The digest, "sha256:<hex>".
+func PinHash(value string) string {
+	return types.PinHash(value)
+}
+
+// SetPinPolicy sets what a pin mismatch does. The default is PinAlert.
+//
+// Parameters:
+//   - p: The policy.
+func SetPinPolicy(p PinPolicy) {
+	types.SetPinPolicy(p)
+}
+
+// VerifyPins checks every pinned variable, including unset ones, at
+// startup. Mismatches are reported to ErrorHook and, under PinFail,
+// returned.
+//
+// Returns:
+//   - error: The mismatches under PinFail, or nil.
+func VerifyPins() error {
+	return getters.VerifyPins()
+}
+
+// CheckPolicies evaluates policies against the default source and
+// returns all violations aggregated as policy.Violations.
+//
+// Parameters:
+//   - policies: The policies.
+//
+// Returns:
+//   - error: The violations, or nil.
+func CheckPolicies(policies ...Policy) error {
+	return policy.Evaluate(sources.Default(), policies...)
+}
+
 // Get returns the raw value and a boolean indicating presence.
 //
 // Parameters:
@@ -50,6 +532,21 @@ func Get(key string) (string, bool) {
 	return getters.Get(key)
 }
 
+// GetCtx is like Get but lets ctx bound lookups against remote sources
+// and reference resolvers.
+//
+// Parameters:
+//   - ctx: The context.
+//   - key: The key to get.
+//
+// Returns:
+//   - string: The value.
+//   - bool: The boolean indicating presence.
+//   - error: The error if ctx is done or the value fails to resolve.
+func GetCtx(ctx context.Context, key string) (string, bool, error) {
+	return getters.GetCtx(ctx, key)
+}
+
 // GetOr returns the value or a default if not present.
 //
 // Parameters:
@@ -62,6 +559,31 @@ func GetOr(key, def string) string {
 	return getters.GetOr(key, def)
 }
 
+// GetFirst returns the first present key and its value, so a variable
+// can be renamed while older deployments still set the old name.
+//
+// Parameters:
+//   - keys: The keys to try in order, e.g. "HTTP_PORT", "PORT".
+//
+// Returns:
+//   - string: The key that held the value, or "".
+//   - string: The value.
+//   - bool: The boolean indicating presence of any key.
+func GetFirst(keys ...string) (string, string, bool) {
+	return getters.GetFirst(keys...)
+}
+
+// RequireAny checks that at least one of keys is present.
+//
+// Parameters:
+//   - keys: The keys, e.g. "REDIS_URL", "REDIS_HOST".
+//
+// Returns:
+//   - error: A missing error naming all keys if none is present.
+func RequireAny(keys ...string) error {
+	return getters.RequireAny(keys...)
+}
+
 // MustGet returns the value or panics if not present.
 //
 // Parameters:
@@ -73,6 +595,73 @@ func MustGet(key string) string {
 	return getters.MustGet(key)
 }
 
+// CIInfo describes the CI run the process executes in, normalized
+// across providers.
+type CIInfo = getters.CIInfo
+
+// CI providers reported by CIInfo.Provider.
+const (
+	CIGitHubActions = getters.CIGitHubActions
+	CIGitLab        = getters.CIGitLab
+	CICircleCI      = getters.CICircleCI
+	CIJenkins       = getters.CIJenkins
+	CIGeneric       = getters.CIGeneric
+)
+
+// CI detects GitHub Actions, GitLab CI, CircleCI, and Jenkins and
+// normalizes the branch, tag, commit, and pull request number.
+//
+// Returns:
+//   - CIInfo: The run; Provider is "" outside CI.
+func CI() CIInfo {
+	return getters.CI()
+}
+
+// RuntimeInfo describes the container platform the process runs on.
+type RuntimeInfo = getters.RuntimeInfo
+
+// Platforms reported by RuntimeInfo.Platform.
+const (
+	RuntimeKubernetes = getters.RuntimeKubernetes
+	RuntimeECS        = getters.RuntimeECS
+	RuntimeCloudRun   = getters.RuntimeCloudRun
+	RuntimeLambda     = getters.RuntimeLambda
+)
+
+// Runtime detects Kubernetes, ECS, Cloud Run, and Lambda from their
+// well-known variables, for logging enrichment.
+//
+// Returns:
+//   - RuntimeInfo: The platform; Platform is "" when none is detected.
+func Runtime() RuntimeInfo {
+	return getters.Runtime()
+}
+
+// GetCanary returns the value of key, or of key_CANARY for the share of
+// instances given by key_CANARY_PERCENT, choosing deterministically per
+// instance by hashing the hostname (see SetCanaryInstance).
+//
+// Parameters:
+//   - key: The key of the stable value.
+//
+// Returns:
+//   - string: The selected value.
+//   - bool: Whether the canary value was selected.
+//   - error: The error if the percentage is invalid or the selected
+//     value is missing.
+func GetCanary(key string) (string, bool, error) {
+	return getters.GetCanary(key)
+}
+
+// SetCanaryInstance sets the identity GetCanary hashes instead of the
+// hostname, e.g. a pod name; "" restores the default.
+//
+// Parameters:
+//   - id: The instance identity.
+func SetCanaryInstance(id string) {
+	getters.SetCanaryInstance(id)
+}
+
 // GetOrErr returns the value or an error if not present.
 //
 // Parameters:
@@ -120,6 +709,30 @@ func MustGetBool(key string) bool {
 	return getters.MustGetBool(key)
 }
 
+// TriBool is a boolean that distinguishes "not configured" from an
+// explicit true or false. Bind populates fields of this type too.
+type TriBool = types.TriBool
+
+// Tri-state boolean values.
+const (
+	TriUnset = types.TriUnset
+	TriFalse = types.TriFalse
+	TriTrue  = types.TriTrue
+)
+
+// GetBoolTri returns the value as a tri-state boolean: TriUnset when
+// the variable is absent or empty, otherwise TriTrue or TriFalse.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - TriBool: The value.
+//   - error: The error if the value is not a boolean.
+func GetBoolTri(key string) (TriBool, error) {
+	return getters.GetBoolTri(key)
+}
+
 // GetInt returns the value as an integer.
 //
 // Parameters:
@@ -202,214 +815,607 @@ func GetUint(key string) (uint, error) {
 	return getters.GetUint(key)
 }
 
-// GetUintOr returns the value as a uint or a default if not present.
+// GetUintOr returns the value as a uint or a default if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - uint: The value or the default.
+func GetUintOr(key string, def uint) uint {
+	return getters.GetUintOr(key, def)
+}
+
+// MustGetUint returns the value as a uint or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint: The value.
+func MustGetUint(key string) uint {
+	return getters.MustGetUint(key)
+}
+
+// GetUint64 returns the value as a uint64.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint64: The value.
+//   - error: The error if the value is not present.
+func GetUint64(key string) (uint64, error) {
+	return getters.GetUint64(key)
+}
+
+// GetUint64Or returns the value as a uint64 or a default if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - uint64: The value or the default.
+func GetUint64Or(key string, def uint64) uint64 {
+	return getters.GetUint64Or(key, def)
+}
+
+// MustGetUint64 returns the value as a uint64 or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint64: The value.
+func MustGetUint64(key string) uint64 {
+	return getters.MustGetUint64(key)
+}
+
+// GetFloat64 returns the value as a float64.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - float64: The value.
+//   - error: The error if the value is not present.
+func GetFloat64(key string) (float64, error) {
+	return getters.GetFloat64(key)
+}
+
+// GetFloat64Or returns the value as a float64 or a default if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - float64: The value or the default.
+func GetFloat64Or(key string, def float64) float64 {
+	return getters.GetFloat64Or(key, def)
+}
+
+// MustGetFloat64 returns the value as a float64 or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - float64: The value.
+func MustGetFloat64(key string) float64 {
+	return getters.MustGetFloat64(key)
+}
+
+// GetDuration returns the value as a duration.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - time.Duration: The value.
+//   - error: The error if the value is not present.
+func GetDuration(key string) (time.Duration, error) {
+	return getters.GetDuration(key)
+}
+
+// GetDurationOr returns the value as a duration or a default if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - time.Duration: The value or the default.
+func GetDurationOr(key string, def time.Duration) time.Duration {
+	return getters.GetDurationOr(key, def)
+}
+
+// MustGetDuration returns the value as a duration or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - time.Duration: The value.
+func MustGetDuration(key string) time.Duration {
+	return getters.MustGetDuration(key)
+}
+
+// Bytes is a byte size field type parsed from values such as "512MB"
+// or "2GiB".
+type Bytes = types.Bytes
+
+// GetBytes returns the value as a byte size, e.g. "512MB" or "2GiB".
+// SI suffixes (KB, MB) are powers of 1000, IEC suffixes (KiB, MiB)
+// powers of 1024.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint64: The size in bytes.
+//   - error: The error if the value is not present or invalid.
+func GetBytes(key string) (uint64, error) {
+	return getters.GetBytes(key)
+}
+
+// GetBytesOr returns the value as a byte size or a default if not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - uint64: The size in bytes or the default.
+func GetBytesOr(key string, def uint64) uint64 {
+	return getters.GetBytesOr(key, def)
+}
+
+// MustGetBytes returns the value as a byte size or panics if not present
+// or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint64: The size in bytes.
+func MustGetBytes(key string) uint64 {
+	return getters.MustGetBytes(key)
+}
+
+// GetTime returns the value as an RFC 3339 time.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - time.Time: The value.
+//   - error: The error if the value is not present or invalid.
+func GetTime(key string) (time.Time, error) {
+	return getters.GetTime(key)
+}
+
+// GetTimeLayout returns the value as a time parsed with layout.
+//
+// Parameters:
+//   - key: The key to get.
+//   - layout: The time layout, e.g. "2006-01-02".
+//
+// Returns:
+//   - time.Time: The value.
+//   - error: The error if the value is not present or invalid.
+func GetTimeLayout(key, layout string) (time.Time, error) {
+	return getters.GetTimeLayout(key, layout)
+}
+
+// GetTimeOr returns the value as an RFC 3339 time or a default if not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - time.Time: The value or the default.
+func GetTimeOr(key string, def time.Time) time.Time {
+	return getters.GetTimeOr(key, def)
+}
+
+// MustGetTime returns the value as an RFC 3339 time or panics if not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - time.Time: The value.
+func MustGetTime(key string) time.Time {
+	return getters.MustGetTime(key)
+}
+
+// GetURL returns the value as a URL.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - *url.URL: The value.
+//   - error: The error if the value is not present.
+func GetURL(key string) (*url.URL, error) {
+	return getters.GetURL(key)
+}
+
+// MustGetURL returns the value as a URL or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - *url.URL: The value.
+func MustGetURL(key string) *url.URL {
+	return getters.MustGetURL(key)
+}
+
+// GetIP returns the value as an IP.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - net.IP: The value.
+//   - error: The error if the value is not present.
+func GetIP(key string) (net.IP, error) {
+	return getters.GetIP(key)
+}
+
+// GetCIDR returns the value as a network in CIDR notation, e.g.
+// "10.0.0.0/8".
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - *net.IPNet: The network.
+//   - error: The error if the value is not present or invalid.
+func GetCIDR(key string) (*net.IPNet, error) {
+	return getters.GetCIDR(key)
+}
+
+// MustGetCIDR returns the value as a network or panics if not present
+// or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - *net.IPNet: The network.
+func MustGetCIDR(key string) *net.IPNet {
+	return getters.MustGetCIDR(key)
+}
+
+// MustGetIP returns the value as an IP or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - net.IP: The value.
+func MustGetIP(key string) net.IP {
+	return getters.MustGetIP(key)
+}
+
+// GetStringSlice returns the value as a slice of strings.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []string: The value.
+//   - error: The error if the value is not present.
+func GetStringSlice(key string) ([]string, error) {
+	return getters.GetStringSlice(key)
+}
+
+// MustGetStringSlice returns the value as a slice of strings or panics if not present.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []string: The value.
+func MustGetStringSlice(key string) []string {
+	return getters.MustGetStringSlice(key)
+}
+
+// GetStringSliceSep returns the value as a slice of strings with a custom separator.
+//
+// Parameters:
+//   - key: The key to get.
+//   - sep: The separator.
+//
+// Returns:
+//   - []string: The value.
+//   - error: The error if the value is not present.
+func GetStringSliceSep(key, sep string) ([]string, error) {
+	return getters.GetStringSliceSep(key, sep)
+}
+
+// GetDurationList returns a comma-separated list of non-negative
+// durations, such as a "1s,5s,30s" backoff schedule.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []time.Duration: The durations in order.
+//   - error: The error if the value is not present or an item is
+//     invalid.
+func GetDurationList(key string) ([]time.Duration, error) {
+	return getters.GetDurationList(key)
+}
+
+// MustGetDurationList returns the durations or panics if the value is
+// not present or invalid.
 //
 // Parameters:
 //   - key: The key to get.
-//   - def: The default value.
 //
 // Returns:
-//   - uint: The value or the default.
-func GetUintOr(key string, def uint) uint {
-	return getters.GetUintOr(key, def)
+//   - []time.Duration: The durations in order.
+func MustGetDurationList(key string) []time.Duration {
+	return getters.MustGetDurationList(key)
 }
 
-// MustGetUint returns the value as a uint or panics if not present.
+// IntRange is an inclusive range of non-negative integers.
+type IntRange = getters.IntRange
+
+// GetIntRangeList returns a comma-separated list of integer ranges,
+// such as "8000-8010,9000", as (lo, hi) pairs.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - uint: The value.
-func MustGetUint(key string) uint {
-	return getters.MustGetUint(key)
+//   - []IntRange: The ranges in order.
+//   - error: The error if the value is not present or an item is
+//     invalid.
+func GetIntRangeList(key string) ([]IntRange, error) {
+	return getters.GetIntRangeList(key)
 }
 
-// GetUint64 returns the value as a uint64.
+// GetIntRangeValues is like GetIntRangeList but expands the ranges to
+// the integers they contain.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - uint64: The value.
-//   - error: The error if the value is not present.
-func GetUint64(key string) (uint64, error) {
-	return getters.GetUint64(key)
+//   - []int: The integers.
+//   - error: The error if the value is not present, invalid, or too
+//     large.
+func GetIntRangeValues(key string) ([]int, error) {
+	return getters.GetIntRangeValues(key)
 }
 
-// GetUint64Or returns the value as a uint64 or a default if not present.
+// MustGetIntRangeList returns the ranges or panics if the value is not
+// present or invalid.
 //
 // Parameters:
 //   - key: The key to get.
-//   - def: The default value.
 //
 // Returns:
-//   - uint64: The value or the default.
-func GetUint64Or(key string, def uint64) uint64 {
-	return getters.GetUint64Or(key, def)
+//   - []IntRange: The ranges in order.
+func MustGetIntRangeList(key string) []IntRange {
+	return getters.MustGetIntRangeList(key)
 }
 
-// MustGetUint64 returns the value as a uint64 or panics if not present.
+// GetPortRange returns a "min-max" port range of valid TCP ports.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - uint64: The value.
-func MustGetUint64(key string) uint64 {
-	return getters.MustGetUint64(key)
+//   - int: The first port.
+//   - int: The last port.
+//   - error: The error if the value is not present or invalid.
+func GetPortRange(key string) (int, int, error) {
+	return getters.GetPortRange(key)
 }
 
-// GetFloat64 returns the value as a float64.
+// PickFreePort listens on a free TCP port in the range held by
+// rangeKey.
 //
 // Parameters:
-//   - key: The key to get.
+//   - rangeKey: The key holding the range, see GetPortRange.
 //
 // Returns:
-//   - float64: The value.
-//   - error: The error if the value is not present.
-func GetFloat64(key string) (float64, error) {
-	return getters.GetFloat64(key)
+//   - net.Listener: The listener on all interfaces.
+//   - error: The error if the range is invalid or no port in it could
+//     be bound.
+func PickFreePort(rangeKey string) (net.Listener, error) {
+	return getters.PickFreePort(rangeKey)
 }
 
-// GetFloat64Or returns the value as a float64 or a default if not present.
+// StringSet is a deduplicated set of strings with a sorted listing.
+type StringSet = getters.StringSet
+
+// GetStringSet returns a comma-separated value as a deduplicated set.
 //
 // Parameters:
 //   - key: The key to get.
-//   - def: The default value.
 //
 // Returns:
-//   - float64: The value or the default.
-func GetFloat64Or(key string, def float64) float64 {
-	return getters.GetFloat64Or(key, def)
+//   - StringSet: The value.
+//   - error: The error if the value is not present.
+func GetStringSet(key string) (StringSet, error) {
+	return getters.GetStringSet(key)
 }
 
-// MustGetFloat64 returns the value as a float64 or panics if not present.
+// GetStringSetStrict is like GetStringSet but returns an error when an
+// item repeats.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - float64: The value.
-func MustGetFloat64(key string) float64 {
-	return getters.MustGetFloat64(key)
+//   - StringSet: The value.
+//   - error: The error if the value is not present or has duplicates.
+func GetStringSetStrict(key string) (StringSet, error) {
+	return getters.GetStringSetStrict(key)
 }
 
-// GetDuration returns the value as a duration.
+// GetStringMap returns a "k1=v1,k2=v2" value as a map.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - time.Duration: The value.
-//   - error: The error if the value is not present.
-func GetDuration(key string) (time.Duration, error) {
-	return getters.GetDuration(key)
+//   - map[string]string: The value.
+//   - error: The error if the value is not present or malformed.
+func GetStringMap(key string) (map[string]string, error) {
+	return getters.GetStringMap(key)
 }
 
-// GetDurationOr returns the value as a duration or a default if not present.
+// GetStringMapSep is like GetStringMap with custom pair and key-value
+// separators.
 //
 // Parameters:
 //   - key: The key to get.
-//   - def: The default value.
+//   - pairSep: The separator between pairs.
+//   - kvSep: The separator between a key and its value.
 //
 // Returns:
-//   - time.Duration: The value or the default.
-func GetDurationOr(key string, def time.Duration) time.Duration {
-	return getters.GetDurationOr(key, def)
+//   - map[string]string: The value.
+//   - error: The error if the value is not present or malformed.
+func GetStringMapSep(key, pairSep, kvSep string) (map[string]string, error) {
+	return getters.GetStringMapSep(key, pairSep, kvSep)
 }
 
-// MustGetDuration returns the value as a duration or panics if not present.
+// MustGetStringMap returns the value as a map or panics if not present
+// or malformed.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - time.Duration: The value.
-func MustGetDuration(key string) time.Duration {
-	return getters.MustGetDuration(key)
+//   - map[string]string: The value.
+func MustGetStringMap(key string) map[string]string {
+	return getters.MustGetStringMap(key)
 }
 
-// GetURL returns the value as a URL.
+// GetStringSliceRe returns the value split on matches of the regular
+// expression pattern, e.g. `[,;\s]+` for lists with mixed delimiters.
 //
 // Parameters:
 //   - key: The key to get.
+//   - pattern: The separator pattern.
 //
 // Returns:
-//   - *url.URL: The value.
-//   - error: The error if the value is not present.
-func GetURL(key string) (*url.URL, error) {
-	return getters.GetURL(key)
+//   - []string: The value.
+//   - error: The error if the pattern is invalid or the value is not
+//     present.
+func GetStringSliceRe(key, pattern string) ([]string, error) {
+	return getters.GetStringSliceRe(key, pattern)
 }
 
-// MustGetURL returns the value as a URL or panics if not present.
+// GetAs returns the value parsed as T, inferring the parser from the
+// type the way Bind does: bool, integers, floats, time.Duration,
+// time.Time (RFC 3339), Bytes, CIDRs, *url.URL, []string, and types
+// with a registered converter.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - *url.URL: The value.
-func MustGetURL(key string) *url.URL {
-	return getters.MustGetURL(key)
+//   - T: The value.
+//   - error: The error if the value is not present or invalid.
+func GetAs[T any](key string) (T, error) {
+	return getters.GetTyped(key, parseAs[T](key))
 }
 
-// GetIP returns the value as an IP.
+// GetAsOr returns the value parsed as T or def if not present or
+// invalid. Like the other Or getters, an invalid value panics under
+// ProfileProd and is reported through ErrorHook under ProfileStaging.
 //
 // Parameters:
 //   - key: The key to get.
+//   - def: The default value.
 //
 // Returns:
-//   - net.IP: The value.
-//   - error: The error if the value is not present.
-func GetIP(key string) (net.IP, error) {
-	return getters.GetIP(key)
+//   - T: The value or the default.
+func GetAsOr[T any](key string, def T) T {
+	return getters.GetTypedOr(key, def, parseAs[T](key))
 }
 
-// MustGetIP returns the value as an IP or panics if not present.
+// parseAs returns a converter parsing values of key as T like Bind.
+func parseAs[T any](key string) func(string) (T, error) {
+	return func(v string) (T, error) {
+		out, err := binders.Parse[T](v)
+		if err != nil {
+			var zero T
+			return zero, &getters.KeyError{Key: key, Kind: getters.ErrType,
+				Msg: err.Error()}
+		}
+		return out, nil
+	}
+}
+
+// MustGetAs returns the value parsed as T or panics if not present or
+// invalid.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - net.IP: The value.
-func MustGetIP(key string) net.IP {
-	return getters.MustGetIP(key)
+//   - T: The value.
+func MustGetAs[T any](key string) T {
+	v, err := GetAs[T](key)
+	if err != nil {
+		panic(err)
+	}
+	return v
 }
 
-// GetStringSlice returns the value as a slice of strings.
+// GetJSON returns the value unmarshaled as JSON into T, e.g.
+// GetJSON[map[string]int]("LIMITS") for LIMITS={"api":10}.
 //
 // Parameters:
 //   - key: The key to get.
 //
 // Returns:
-//   - []string: The value.
-//   - error: The error if the value is not present.
-func GetStringSlice(key string) ([]string, error) {
-	return getters.GetStringSlice(key)
+//   - T: The value.
+//   - error: The error if the value is not present or not valid JSON
+//     for T.
+func GetJSON[T any](key string) (T, error) {
+	return getters.GetJSON[T](key)
 }
 
-// MustGetStringSlice returns the value as a slice of strings or panics if not present.
+// GetJSONOr returns the value unmarshaled as JSON into T or a default
+// if not present or invalid.
 //
 // Parameters:
 //   - key: The key to get.
+//   - def: The default value.
 //
 // Returns:
-//   - []string: The value.
-func MustGetStringSlice(key string) []string {
-	return getters.MustGetStringSlice(key)
+//   - T: The value or the default.
+func GetJSONOr[T any](key string, def T) T {
+	return getters.GetJSONOr(key, def)
 }
 
-// GetStringSliceSep returns the value as a slice of strings with a custom separator.
+// MustGetJSON returns the value unmarshaled as JSON into T or panics if
+// not present or invalid.
 //
 // Parameters:
 //   - key: The key to get.
-//   - sep: The separator.
 //
 // Returns:
-//   - []string: The value.
-//   - error: The error if the value is not present.
-func GetStringSliceSep(key, sep string) ([]string, error) {
-	return getters.GetStringSliceSep(key, sep)
+//   - T: The value.
+func MustGetJSON[T any](key string) T {
+	return getters.MustGetJSON[T](key)
 }
 
 // GetTyped returns the value as a typed value using a converter.
@@ -442,33 +1448,150 @@ func MustGetTyped[T any](key string, conv func(string) (T, error)) T {
 //
 // Parameters:
 //   - dst: The destination.
+//   - opts: The options, e.g. binders.WithReport.
+//
+// Returns:
+//   - error: The error if the binding fails.
+func Bind(dst any, opts ...binders.Option) error {
+	return binders.Bind(dst, opts...)
+}
+
+// BindCtx is like Bind but lets ctx bound lookups against remote
+// sources and reference resolvers.
+//
+// Parameters:
+//   - ctx: The context.
+//   - dst: The destination.
+//   - opts: The options.
+//
+// Returns:
+//   - error: The error if the binding fails or ctx is done.
+func BindCtx(ctx context.Context, dst any, opts ...binders.Option) error {
+	return binders.BindCtx(ctx, dst, opts...)
+}
+
+// BindWith is like Bind but also returns soft issues, such as fallback
+// names or lenient booleans, that do not fail binding; see
+// binders.WithWarnings.
+//
+// Parameters:
+//   - dst: The destination.
+//   - opts: The options.
 //
 // Returns:
+//   - binders.Warnings: The warnings, even when binding fails.
 //   - error: The error if the binding fails.
-func Bind(dst any) error {
-	return binders.Bind(dst)
+func BindWith(dst any, opts ...binders.Option) (binders.Warnings, error) {
+	return binders.BindWith(dst, opts...)
 }
 
 // BindWithPrefix is like Bind but first tries variables with the given
 // prefix. For example with prefix "MYAPP_", field `env:"PORT"` resolves
-// "MYAPP_PORT" if present, else falls back to "PORT".
+// "MYAPP_PORT" if present, else falls back to "PORT". Pass
+// binders.WithStrict() to reject prefixed variables that map to no
+// field, such as the typo MYAPP_PROT.
 //
 // Parameters:
 //   - dst: The destination.
 //   - prefix: The prefix.
+//   - opts: The options.
 //
 // Returns:
 //   - error: The error if the binding fails.
-func BindWithPrefix(dst any, prefix string) error {
-	return binders.BindWithPrefix(dst, prefix)
+func BindWithPrefix(dst any, prefix string, opts ...binders.Option) error {
+	return binders.BindWithPrefix(dst, prefix, opts...)
+}
+
+// Compile builds the binding plan of T once for repeated binds. See
+// binders.Compile.
+//
+// Returns:
+//   - *binders.Compiled[T]: The binder.
+//   - error: The error if T is not a struct.
+func Compile[T any]() (*binders.Compiled[T], error) {
+	return binders.Compile[T]()
+}
+
+// MustCompile is like Compile but panics if T is not a struct.
+//
+// Returns:
+//   - *binders.Compiled[T]: The binder.
+func MustCompile[T any]() *binders.Compiled[T] {
+	return binders.MustCompile[T]()
+}
+
+// BindAndWatch binds dst and keeps re-binding it when the underlying
+// values or watched files change. See binders.BindAndWatch.
+//
+// Parameters:
+//   - dst: The destination struct.
+//   - opts: The options.
+//
+// Returns:
+//   - *binders.Watched[T]: The running watch.
+//   - error: The error if the initial bind fails.
+func BindAndWatch[T any](dst *T, opts binders.WatchOptions[T]) (*binders.Watched[T], error) {
+	return binders.BindAndWatch(dst, opts)
+}
+
+// SchemaFingerprint returns a stable hash of the variables cfg binds:
+// names, types, and binding and validation tags.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct.
+//
+// Returns:
+//   - string: The fingerprint, "sha256:<hex>".
+func SchemaFingerprint(cfg any) string {
+	return binders.SchemaFingerprint(cfg)
+}
+
+// PrintConfig writes the bound fields of cfg, redacted, as a "table"
+// (the default for ""), "json", or "yaml", to back a --print-config
+// flag.
+//
+// Parameters:
+//   - w: The writer.
+//   - cfg: A struct or pointer to struct with env tags.
+//   - format: "table", "json", or "yaml".
+//
+// Returns:
+//   - error: The error if cfg is not a struct, format is unknown, or
+//     writing fails.
+func PrintConfig(w io.Writer, cfg any, format string) error {
+	return binders.PrintConfig(w, cfg, format)
+}
+
+// CheckDeployedSchema compares ENVVAR_SCHEMA_FINGERPRINT, when set,
+// with the fingerprint of cfg, detecting drift between deployed env
+// templates and the code.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct.
+//
+// Returns:
+//   - error: An error wrapping binders.ErrSchemaMismatch on drift.
+func CheckDeployedSchema(cfg any) error {
+	return binders.CheckDeployedSchema(cfg)
+}
+
+// RegisterConverter teaches Bind to populate fields of type t with
+// conv, e.g. decimal.Decimal or log levels.
+//
+// Parameters:
+//   - t: The field type.
+//   - conv: The converter.
+func RegisterConverter(t reflect.Type, conv func(string) (any, error)) {
+	binders.RegisterConverter(t, conv)
 }
 
 // MustBind panics on binding errors.
 //
 // Parameters:
 //   - dst: The destination.
-func MustBind(dst any) {
-	binders.MustBind(dst)
+//   - opts: The options.
+func MustBind(dst any, opts ...binders.Option) {
+	binders.MustBind(dst, opts...)
 }
 
 // MustBindWithPrefix panics on binding errors.
@@ -476,8 +1599,9 @@ func MustBind(dst any) {
 // Parameters:
 //   - dst: The destination.
 //   - prefix: The prefix.
-func MustBindWithPrefix(dst any, prefix string) {
-	binders.MustBindWithPrefix(dst, prefix)
+//   - opts: The options.
+func MustBindWithPrefix(dst any, prefix string, opts ...binders.Option) {
+	binders.MustBindWithPrefix(dst, prefix, opts...)
 }
 
 // LazyString returns a function that returns the value of the environment
@@ -612,8 +1736,10 @@ func LazyTyped[T any](key string, conv func(string) (T, error)) func() T {
 }
 
 // DumpRedacted returns environment as a map with secret-like values
-// redacted. Redaction is heuristic: keys containing "SECRET", "TOKEN",
-// "KEY", or "PASSWORD" are masked.
+// redacted. Keys of fields tagged `secret:"true"` are masked once bound;
+// otherwise redaction is heuristic: keys containing "SECRET", "TOKEN",
+// "KEY", or "PASSWORD" are masked. fmt and encoding/json print maps in
+// key order; use SortedKeys to iterate deterministically.
 //
 // Returns:
 //   - map[string]string: The environment as a map with secret-like values redacted.
@@ -625,15 +1751,333 @@ func DumpRedacted() map[string]string {
 		if !ok {
 			continue
 		}
-		upper := strings.ToUpper(k)
-		if strings.Contains(upper, "SECRET") ||
-			strings.Contains(upper, "TOKEN") ||
-			strings.Contains(upper, "PASSWORD") ||
-			strings.HasSuffix(upper, "_KEY") {
-			out[k] = "***"
-		} else {
-			out[k] = v
+		out[k] = redact.Value(k, v)
+	}
+	return out
+}
+
+// DumpFormat selects the output of Dump.
+type DumpFormat string
+
+// Dump formats.
+const (
+	// DumpJSON writes an indented JSON object.
+	DumpJSON DumpFormat = "json"
+	// DumpDotenv writes KEY=value lines that LoadEnvFile reads back.
+	DumpDotenv DumpFormat = "dotenv"
+	// DumpLogfmt writes one line of KEY=value pairs for log lines.
+	DumpLogfmt DumpFormat = "logfmt"
+)
+
+// DumpPolicy selects which values Dump masks.
+type DumpPolicy int
+
+// Dump policies.
+const (
+	// RedactSecrets masks secret-like and secret-tagged keys, like
+	// DumpRedacted.
+	RedactSecrets DumpPolicy = iota
+	// RedactAll masks every value, listing only which keys are set.
+	RedactAll
+	// RedactNone writes values verbatim; use it for local debugging
+	// only.
+	RedactNone
+)
+
+// Dump writes the process environment to w in format, sorted by key
+// so output can be diffed across deploys.
+//
+// Parameters:
+//   - w: The writer.
+//   - format: The output format.
+//   - policy: Which values to mask.
+//
+// Returns:
+//   - error: The error if format is unknown or writing fails.
+func Dump(w io.Writer, format DumpFormat, policy DumpPolicy) error {
+	env := os.Environ()
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			m[k] = v
+		}
+	}
+	return dumpMap(w, m, format, policy)
+}
+
+// DumpRedactedJSON returns DumpRedacted as indented JSON sorted by key.
+//
+// Returns:
+//   - []byte: The JSON document.
+func DumpRedactedJSON() []byte {
+	var b bytes.Buffer
+	_ = Dump(&b, DumpJSON, RedactSecrets)
+	return b.Bytes()
+}
+
+// DumpRedactedDotenv returns DumpRedacted as KEY=value lines sorted by
+// key.
+//
+// Returns:
+//   - []byte: The dotenv document.
+func DumpRedactedDotenv() []byte {
+	var b bytes.Buffer
+	_ = Dump(&b, DumpDotenv, RedactSecrets)
+	return b.Bytes()
+}
+
+// dumpMap writes m to w in format after masking values per policy.
+func dumpMap(w io.Writer, m map[string]string, format DumpFormat, policy DumpPolicy) error {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		switch policy {
+		case RedactSecrets:
+			v = redact.Value(k, v)
+		case RedactAll:
+			v = redact.Mask
+		}
+		out[k] = v
+	}
+	var data []byte
+	switch format {
+	case DumpJSON:
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(b, '\n')
+	case DumpDotenv:
+		data = loaders.Marshal(out)
+	case DumpLogfmt:
+		data = logfmt(out)
+	default:
+		return fmt.Errorf("envvar: unknown dump format %q", format)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// logfmt renders m as one line of sorted KEY=value pairs, quoting
+// values that are empty or contain spaces, quotes, or '='.
+func logfmt(m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		v := m[k]
+		if v == "" || strings.ContainsAny(v, " =\"\\") || strconv.Quote(v) != `"`+v+`"` {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(v)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// Change describes a single variable change.
+type Change = types.Change
+
+// Change kinds.
+const (
+	Added    = types.Added
+	Removed  = types.Removed
+	Modified = types.Modified
+)
+
+// Diff returns the changes that turn a into b, sorted by key, with
+// secret-like values replaced by the redaction mask. A changed secret
+// is still reported, as "*** -> ***". A masked value on either side,
+// as in a redacted dump, matches any value.
+//
+// Parameters:
+//   - a: The baseline variables.
+//   - b: The compared variables.
+//
+// Returns:
+//   - []Change: The redacted changes.
+func Diff(a, b map[string]string) []Change {
+	var out []Change
+	for _, c := range loaders.Diff(a, b) {
+		if c.Kind == Modified && (c.Old == redact.Mask || c.New == redact.Mask) &&
+			redact.IsSecretKey(c.Key) {
+			continue
+		}
+		if c.Old != "" {
+			c.Old = redact.Value(c.Key, c.Old)
+		}
+		if c.New != "" {
+			c.New = redact.Value(c.Key, c.New)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// DiffEnvFile compares the process environment with the env file at
+// path, for "works locally, not in prod" debugging. Only keys the file
+// defines are compared: Removed means the file sets a key the
+// environment lacks, Modified that the values differ.
+//
+// Parameters:
+//   - path: The env file.
+//   - opts: The loading options.
+//
+// Returns:
+//   - []Change: The redacted changes from the file to the environment.
+//   - error: The error if the file cannot be read.
+func DiffEnvFile(path string, opts ...loaders.Option) ([]Change, error) {
+	file, err := loaders.ReadFile(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cur := map[string]string{}
+	for k := range file {
+		if v, ok := os.LookupEnv(k); ok {
+			cur[k] = v
+		}
+	}
+	return Diff(file, cur), nil
+}
+
+// DiffDump compares the process environment with a previous dump
+// written by DumpRedactedDotenv, DumpRedactedJSON, or Dump in the
+// dotenv or JSON format, e.g. from another deploy.
+//
+// Parameters:
+//   - r: The dump.
+//
+// Returns:
+//   - []Change: The redacted changes from the dump to the environment.
+//   - error: The error if the dump cannot be parsed.
+func DiffDump(r io.Reader) ([]Change, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var old map[string]string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &old)
+	} else {
+		old, err = loaders.ReadFrom(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("envvar: parse dump: %w", err)
+	}
+	cur := map[string]string{}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			cur[k] = v
+		}
+	}
+	return Diff(old, cur), nil
+}
+
+// Access summarizes the reads of one key through getters and Bind.
+type Access = types.Access
+
+// Load records one load of a file or source.
+type Load = types.Load
+
+// SetRecordAccess turns on the per-key read counters behind
+// AccessReport, DumpUsed, and SupportBundle. It is off by default.
+//
+// Parameters:
+//   - on: Whether reads are recorded.
+func SetRecordAccess(on bool) {
+	types.SetRecordAccess(on)
+}
+
+// AccessReport returns the keys read through getters and Bind since
+// SetRecordAccess turned recording on, sorted by key. Values are never
+// recorded.
+//
+// Returns:
+//   - []Access: The per-key counters.
+func AccessReport() []Access {
+	return types.AccessReport()
+}
+
+// DumpUsed is like DumpRedacted but limited to the set variables the
+// process has read through getters and Bind while SetRecordAccess was
+// on, so support bundles do not include the unrelated environment.
+// Values come from the default source.
+//
+// Returns:
+//   - map[string]string: The used variables with secret-like values
+//     redacted.
+func DumpUsed() map[string]string {
+	out := map[string]string{}
+	for _, a := range types.AccessReport() {
+		if v, ok := sources.Lookup(a.Key); ok {
+			out[a.Key] = redact.Value(a.Key, v)
 		}
 	}
 	return out
 }
+
+// LoadReport returns the most recent file and source loads, oldest
+// first.
+//
+// Returns:
+//   - []Load: The loads.
+func LoadReport() []Load {
+	return types.LoadReport()
+}
+
+// bundle is the document written by SupportBundle.
+type bundle struct {
+	Generated         time.Time             `json:"generated"`
+	GoVersion         string                `json:"go_version"`
+	Platform          string                `json:"platform"`
+	SchemaFingerprint string                `json:"schema_fingerprint"`
+	Config            json.RawMessage       `json:"config"`
+	Provenance        []binders.FieldReport `json:"provenance"`
+	Env               map[string]string     `json:"env"`
+	Accesses          []Access              `json:"accesses"`
+	Loads             []Load                `json:"loads"`
+}
+
+// SupportBundle packages what is needed to debug configuration issues
+// into one JSON document that is safe to attach to bug reports: the
+// redacted config and the variables the process read (see DumpUsed),
+// where each field's value comes from (see binders.Provenance), which
+// keys were read and which files were loaded, and the schema
+// fingerprint. It has no side effects: nothing is bound, and no hooks
+// or events fire.
+//
+// Parameters:
+//   - cfg: A struct or pointer to struct with env tags.
+//
+// Returns:
+//   - []byte: The bundle.
+//   - error: The error if cfg is not a struct.
+func SupportBundle(cfg any) ([]byte, error) {
+	conf, err := binders.MarshalJSON(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	prov, err := binders.Provenance(cfg)
+	if err != nil {
+		return nil, err
+	}
+	b := bundle{
+		Generated:         types.Now().UTC(),
+		GoVersion:         runtime.Version(),
+		Platform:          runtime.GOOS + "/" + runtime.GOARCH,
+		SchemaFingerprint: binders.SchemaFingerprint(cfg),
+		Config:            conf,
+		Provenance:        prov,
+		Env:               DumpUsed(),
+		Accesses:          AccessReport(),
+		Loads:             LoadReport(),
+	}
+	return json.MarshalIndent(b, "", "  ")
+}
@@ -0,0 +1,111 @@
+package envvartest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// Clock is a manually advanced types.Clock for hermetic tests of
+// watchers and timings.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+// NewClock returns a clock stopped at start.
+//
+// Parameters:
+//   - start: The initial time.
+//
+// Returns:
+//   - *Clock: The clock.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// UseClock installs c as the global clock and restores the system clock
+// via t.Cleanup. Install it before starting watchers.
+//
+// Parameters:
+//   - t: The test.
+//   - c: The clock.
+func UseClock(t testing.TB, c *Clock) {
+	t.Helper()
+	types.SetClock(c)
+	t.Cleanup(func() { types.SetClock(nil) })
+}
+
+// Now returns the simulated time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker driven by Advance.
+func (c *Clock) NewTicker(d time.Duration) types.Ticker {
+	if d <= 0 {
+		panic("envvartest: non-positive ticker interval")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tk := &ticker{c: make(chan time.Time, 1), every: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, tk)
+	return tk
+}
+
+// Advance moves the clock forward by d and fires due tickers. Like
+// time.Ticker, a ticker whose previous tick was not received drops
+// further ticks.
+//
+// Parameters:
+//   - d: The duration.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, tk := range c.tickers {
+		tk.fire(c.now)
+	}
+}
+
+// ticker is a Clock ticker.
+type ticker struct {
+	c       chan time.Time
+	every   time.Duration
+	mu      sync.Mutex
+	next    time.Time
+	stopped bool
+}
+
+// C returns the tick channel.
+func (t *ticker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop stops further ticks.
+func (t *ticker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+}
+
+// fire delivers a tick if one is due at now.
+func (t *ticker) fire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped || now.Before(t.next) {
+		return
+	}
+	for !now.Before(t.next) {
+		t.next = t.next.Add(t.every)
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
@@ -0,0 +1,85 @@
+package envvartest
+
+// EnvFileCorpus returns seed inputs for fuzzing env file parsers, such
+// as loaders.ReadFile: quoting, escapes, comments, exports, multiline
+// values, expansion, and malformed lines.
+//
+// Returns:
+//   - []string: The env file contents.
+func EnvFileCorpus() []string {
+	return []string{
+		"",
+		"A=1\n",
+		"export A=1\nB = two \n",
+		"# comment\nA=1 # trailing\n",
+		"A=\"quoted # not a comment\"\n",
+		"A='single $NOT_EXPANDED'\n",
+		"A=\"esc \\\"q\\\" \\n \\t \\\\\"\n",
+		"A=\"multi\nline\"\n",
+		"A=${B:-def}\nB=${A}\n",
+		"A=\r\nB=crlf\r\n",
+		"=novalue\n",
+		"A\n",
+		"A=\"unterminated\n",
+		"A=`back`\n",
+		"\ufeffA=bom\n",
+		"A=1\nA=2\n",
+	}
+}
+
+// BoolCorpus returns seed inputs for fuzzing boolean parsers, such as
+// binders.ParseBoolValue.
+//
+// Returns:
+//   - []string: The values.
+func BoolCorpus() []string {
+	return []string{"", "1", "0", "true", "FALSE", " yes ", "no", "on", "Off",
+		"t", "f", "y", "n", "2", "truee", "\ttrue\n"}
+}
+
+// ListCorpus returns seed input and separator pairs for fuzzing list
+// splitters, such as binders.SplitAndTrim.
+//
+// Returns:
+//   - [][2]string: The input and separator pairs.
+func ListCorpus() [][2]string {
+	return [][2]string{
+		{"", ","},
+		{"a,b,c", ","},
+		{" a , , b ,", ","},
+		{"a;b", ";"},
+		{"a::b::", "::"},
+		{"a b", " "},
+		{"abc", ""},
+	}
+}
+
+// ExpandCorpus returns seed inputs for fuzzing the expander, such as
+// expand.ExpandWith: operators, nesting, cycles, functions, and
+// unbalanced braces.
+//
+// Returns:
+//   - []string: The values.
+func ExpandCorpus() []string {
+	return []string{
+		"",
+		"plain",
+		"${A}",
+		"$A/$B",
+		"${A:-def}",
+		"${A:+alt}",
+		"${A:?must be set}",
+		"${P_${A}}",
+		"${A:-${B:-${C}}}",
+		"${SELF}",
+		"${",
+		"${A",
+		"${ then } later",
+		"${A}${",
+		"${}",
+		"}{$}",
+		"$${A}}",
+		"${b64:aGk=}",
+		"${urlencode:${A}}",
+	}
+}
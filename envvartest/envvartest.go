@@ -0,0 +1,44 @@
+// Package envvartest provides test helpers that load env files through
+// the real loader and restore the process environment when the test
+// ends.
+package envvartest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aatuh/envvar/v2/loaders"
+)
+
+// LoadFile loads path into the process env like loaders.Load and
+// restores every variable it defines to its prior value (or unsets it)
+// via t.Cleanup. Like t.Setenv, it cannot be used in parallel tests.
+// It fails the test if the file cannot be loaded.
+//
+// Parameters:
+//   - t: The test.
+//   - path: The env file.
+//   - opts: The loading options.
+func LoadFile(t testing.TB, path string, opts ...loaders.Option) {
+	t.Helper()
+	m, err := loaders.ReadFile(path, opts...)
+	if err != nil {
+		t.Fatalf("envvartest: %v", err)
+	}
+	for k := range m {
+		restoreOnCleanup(t, k)
+	}
+	if err := loaders.Load(path, opts...); err != nil {
+		t.Fatalf("envvartest: %v", err)
+	}
+}
+
+// restoreOnCleanup registers the restoration of key through t.Setenv,
+// which also guards against parallel use.
+func restoreOnCleanup(t testing.TB, key string) {
+	prev, ok := os.LookupEnv(key)
+	t.Setenv(key, prev)
+	if !ok {
+		os.Unsetenv(key)
+	}
+}
@@ -0,0 +1,76 @@
+package envvartest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aatuh/envvar/v2/loaders"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+func TestLoadFileRestores(t *testing.T) {
+	p := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(p, []byte("ENVVARTEST_SET=file\nENVVARTEST_NEW=file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("ENVVARTEST_SET", "before")
+	os.Unsetenv("ENVVARTEST_NEW")
+	defer os.Unsetenv("ENVVARTEST_SET")
+
+	t.Run("load", func(t *testing.T) {
+		LoadFile(t, p)
+		if os.Getenv("ENVVARTEST_SET") != "file" || os.Getenv("ENVVARTEST_NEW") != "file" {
+			t.Fatalf("file not loaded")
+		}
+	})
+	if got := os.Getenv("ENVVARTEST_SET"); got != "before" {
+		t.Fatalf("ENVVARTEST_SET = %q, want restored", got)
+	}
+	if _, ok := os.LookupEnv("ENVVARTEST_NEW"); ok {
+		t.Fatalf("ENVVARTEST_NEW not unset")
+	}
+}
+
+func TestClockDrivesWatch(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewClock(start)
+	UseClock(t, clk)
+	fsys := fstest.MapFS{"app.env": {Data: []byte("MODE=a\n"), ModTime: start}}
+
+	applied := make(chan []types.Change, 1)
+	w, err := loaders.Watch([]string{"app.env"}, loaders.WatchOptions{
+		Interval: time.Minute,
+		Load:     []loaders.Option{loaders.WithFS(fsys)},
+		Apply: func(c []types.Change) error {
+			applied <- c
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	fsys["app.env"] = &fstest.MapFile{Data: []byte("MODE=b\n"), ModTime: start.Add(time.Second)}
+	clk.Advance(30 * time.Second)
+	select {
+	case c := <-applied:
+		t.Fatalf("polled before the interval elapsed: %v", c)
+	case <-time.After(20 * time.Millisecond):
+	}
+	clk.Advance(30 * time.Second)
+	select {
+	case c := <-applied:
+		if len(c) != 1 || c[0].New != "b" {
+			t.Fatalf("changes = %v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no poll after advancing the clock")
+	}
+	if got := types.Now(); !got.Equal(start.Add(time.Minute)) {
+		t.Fatalf("types.Now() = %v", got)
+	}
+}
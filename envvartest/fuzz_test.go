@@ -0,0 +1,86 @@
+package envvartest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aatuh/envvar/v2/binders"
+	"github.com/aatuh/envvar/v2/expand"
+	"github.com/aatuh/envvar/v2/loaders"
+)
+
+// FuzzReadFile fuzzes the env file parser through ReadFrom, which
+// shares it with ReadFile without touching the filesystem.
+func FuzzReadFile(f *testing.F) {
+	for _, s := range EnvFileCorpus() {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, data string) {
+		m, err := loaders.ReadFrom(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		// Whatever parses must survive a write and re-read unchanged.
+		again, err := loaders.ReadFrom(bytes.NewReader(loaders.Marshal(m)))
+		if err != nil {
+			t.Fatalf("re-read of %q: %v", loaders.Marshal(m), err)
+		}
+		if !reflect.DeepEqual(m, again) && !(len(m) == 0 && len(again) == 0) {
+			t.Fatalf("round trip: %q != %q", m, again)
+		}
+	})
+}
+
+func FuzzParseBoolValue(f *testing.F) {
+	for _, s := range BoolCorpus() {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		b, err := binders.ParseBoolValue(s)
+		if err != nil {
+			return
+		}
+		if b2, err := binders.ParseBoolValue(strings.ToUpper(" " + s + " ")); err != nil || b2 != b {
+			t.Fatalf("%q: case or space changed the result", s)
+		}
+	})
+}
+
+func FuzzSplitAndTrim(f *testing.F) {
+	for _, c := range ListCorpus() {
+		f.Add(c[0], c[1])
+	}
+	f.Fuzz(func(t *testing.T, s, sep string) {
+		for _, p := range binders.SplitAndTrim(s, sep) {
+			if p == "" || p != strings.TrimSpace(p) {
+				t.Fatalf("SplitAndTrim(%q, %q) element %q", s, sep, p)
+			}
+		}
+	})
+}
+
+func FuzzExpand(f *testing.F) {
+	for _, s := range ExpandCorpus() {
+		f.Add(s)
+	}
+	vals := map[string]string{
+		"A": "a", "B": "${A}", "P_a": "p", "SELF": "${SELF}", "E": "",
+	}
+	look := func(k string) (string, bool) {
+		v, ok := vals[k]
+		return v, ok
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		out, err := expand.ExpandWith(s, look)
+		if err == nil && !strings.Contains(s, "$") && out != s {
+			t.Fatalf("%q without references expanded to %q", s, out)
+		}
+		// Without any '}', every "${" is unterminated and kept.
+		if err == nil && !strings.Contains(s, "}") &&
+			strings.Count(out, "${") < strings.Count(s, "${") {
+			t.Fatalf("%q lost an unterminated reference: %q", s, out)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package envvartest
+
+import (
+	"testing"
+
+	"github.com/aatuh/envvar/v2/binders"
+	"github.com/aatuh/envvar/v2/decrypt"
+	"github.com/aatuh/envvar/v2/expand"
+	"github.com/aatuh/envvar/v2/getters"
+	"github.com/aatuh/envvar/v2/loaders"
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/sources"
+	"github.com/aatuh/envvar/v2/types"
+	"github.com/aatuh/envvar/v2/validate"
+)
+
+// ResetGlobalState returns envvar to its pristine state between tests.
+// It removes the hook, limits, the clock and profile overrides, pins,
+// canary identity, secret key and value registrations, the LoadOnce
+// guard, default paths, and the access and load records. It also drops
+// registered converters, validation rules, decryptors, resolvers, and
+// expansion functions, restoring the built-in ones, turns strict
+// expansion and strict validation off, and zeroes the counters of
+// metered sources before removing the default source. It panics
+// outside test binaries, since resetting a running service would
+// silently drop its configuration.
+func ResetGlobalState() {
+	if !testing.Testing() {
+		panic("envvartest: ResetGlobalState called outside a test binary")
+	}
+	types.Reset()
+	sources.ResetStats(sources.Default())
+	sources.SetDefault(nil)
+	getters.SetCanaryInstance("")
+	expand.Reset()
+	redact.Reset()
+	loaders.ResetLoadOnce()
+	loaders.SetDefaultPaths(nil)
+	binders.ResetConverters()
+	validate.Reset()
+	decrypt.Reset()
+	refs.Reset()
+}
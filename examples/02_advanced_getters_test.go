@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aatuh/envvar/v2"
 )
@@ -121,3 +122,27 @@ func TestLazyGetters(t *testing.T) {
 		t.Fatalf("LazyBool caching failed: %v, %v", debug1, debug2)
 	}
 }
+
+// Generic getter inferring the parser from the type
+func TestGetAs(t *testing.T) {
+	t.Setenv("AS_PORT", "8080")
+	t.Setenv("AS_TIMEOUT", "1500ms")
+	t.Setenv("AS_HOSTS", "a, b")
+	t.Setenv("AS_BAD", "eighty")
+
+	if port, err := envvar.GetAs[uint16]("AS_PORT"); err != nil || port != 8080 {
+		t.Fatalf("GetAs[uint16] = %v, %v", port, err)
+	}
+	if d := envvar.MustGetAs[time.Duration]("AS_TIMEOUT"); d != 1500*time.Millisecond {
+		t.Fatalf("MustGetAs[time.Duration] = %v", d)
+	}
+	if hosts, err := envvar.GetAs[[]string]("AS_HOSTS"); err != nil || len(hosts) != 2 {
+		t.Fatalf("GetAs[[]string] = %v, %v", hosts, err)
+	}
+	if _, err := envvar.GetAs[int]("AS_BAD"); err == nil {
+		t.Fatalf("GetAs[int] should fail on invalid input")
+	}
+	if n := envvar.GetAsOr("AS_MISSING", 3); n != 3 {
+		t.Fatalf("GetAsOr = %v", n)
+	}
+}
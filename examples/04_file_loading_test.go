@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/aatuh/envvar/v2"
+	"github.com/aatuh/envvar/v2/envvartest"
 )
 
 // Loading environment variables from files
@@ -23,8 +24,8 @@ API_KEY=secret123
 		t.Fatalf("Failed to create .env file: %v", err)
 	}
 
-	// Load environment variables from file
-	envvar.MustLoadEnvVars([]string{envFile})
+	// Load environment variables from file; restored when the test ends
+	envvartest.LoadFile(t, envFile)
 
 	// Verify loaded values
 	if port := envvar.MustGetInt("PORT"); port != 8080 {
@@ -51,11 +52,11 @@ DEBUG=false
 	defer os.Remove(envFile)
 
 	// Load using specific file path
-	envvar.MustLoadEnvVars([]string{envFile})
+	envvartest.LoadFile(t, envFile)
 
-	// Note: File loading sets environment variables, but they may not be
-	// immediately available due to test isolation. This is a demonstration
-	// of how to use the file loading feature.
+	if port := envvar.MustGetInt("PORT"); port != 9090 {
+		t.Fatalf("PORT not loaded correctly: %v", port)
+	}
 	t.Logf("File loading completed successfully")
 }
 
@@ -76,7 +77,7 @@ DATABASE_URL=postgres://localhost:5432/mydb
 		t.Fatalf("Failed to create .env file: %v", err)
 	}
 
-	envvar.MustLoadEnvVars([]string{envFile})
+	envvartest.LoadFile(t, envFile)
 
 	// Verify that comments and empty lines are ignored
 	if port := envvar.MustGetInt("PORT"); port != 8080 {
@@ -1,12 +1,21 @@
 package examples
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aatuh/envvar/v2"
+	"github.com/aatuh/envvar/v2/envvartest"
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/sources"
 )
 
 // Variable expansion in environment variables
@@ -163,3 +172,317 @@ func contains(s, substr string) bool {
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
 			contains(s[1:], substr)))
 }
+
+// Support bundle for bug reports
+func TestSupportBundle(t *testing.T) {
+	t.Setenv("BUNDLE_PORT", "8080")
+	t.Setenv("BUNDLE_API_TOKEN", "s3cr3t")
+	envvar.SetRecordAccess(true)
+	defer envvar.SetRecordAccess(false)
+
+	type Config struct {
+		Port  int    `env:"BUNDLE_PORT"`
+		Token string `env:"BUNDLE_API_TOKEN"`
+	}
+	var cfg Config
+	envvar.MustBind(&cfg)
+	_ = envvar.GetIntOr("BUNDLE_PORT", 0)
+
+	blob, err := envvar.SupportBundle(&cfg)
+	if err != nil {
+		t.Fatalf("SupportBundle failed: %v", err)
+	}
+	if strings.Contains(string(blob), "s3cr3t") {
+		t.Fatalf("Bundle leaks a secret:\n%s", blob)
+	}
+	var doc struct {
+		SchemaFingerprint string            `json:"schema_fingerprint"`
+		Config            map[string]any    `json:"config"`
+		Provenance        []map[string]any  `json:"provenance"`
+		Accesses          []envvar.Access   `json:"accesses"`
+		Env               map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(blob, &doc); err != nil {
+		t.Fatalf("Bundle is not JSON: %v", err)
+	}
+	if doc.SchemaFingerprint != envvar.SchemaFingerprint(&cfg) {
+		t.Fatalf("Fingerprint mismatch: %s", doc.SchemaFingerprint)
+	}
+	if doc.Config["BUNDLE_PORT"] != 8080.0 || len(doc.Provenance) != 2 {
+		t.Fatalf("Unexpected config or provenance: %v %v", doc.Config, doc.Provenance)
+	}
+	found := false
+	for _, a := range doc.Accesses {
+		found = found || a.Key == "BUNDLE_PORT"
+	}
+	if !found || doc.Env["BUNDLE_API_TOKEN"] != "***" {
+		t.Fatalf("Missing access record or unredacted env: %v", doc.Accesses)
+	}
+}
+
+func TestProdProfile(t *testing.T) {
+	envvar.SetProfile(envvar.ProfileProd)
+	defer envvar.SetProfile("")
+	t.Setenv("PROF_WORKERS", "four")
+	t.Setenv("PROF_DEBUG", "yes")
+	t.Setenv("PROF_PROT", "8080") // typo of PROF_PORT
+
+	if _, err := envvar.GetBool("PROF_DEBUG"); err == nil {
+		t.Fatal("prod accepts only true, false, 1, and 0")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("prod must not silently fall back on a bad value")
+			}
+		}()
+		envvar.GetIntOr("PROF_WORKERS", 4)
+	}()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("prod must not silently fall back in GetAsOr")
+			}
+		}()
+		envvar.GetAsOr("PROF_WORKERS", 4)
+	}()
+	if got := envvar.GetIntOr("PROF_UNSET", 4); got != 4 {
+		t.Fatalf("missing keys still fall back: %d", got)
+	}
+	if got := envvar.GetAsOr("PROF_UNSET", 4); got != 4 {
+		t.Fatalf("GetAsOr: missing keys still fall back: %d", got)
+	}
+	var cfg struct {
+		Port int `env:"PORT" envdef:"80"`
+	}
+	if err := envvar.BindWithPrefix(&cfg, "PROF_"); err == nil ||
+		!strings.Contains(err.Error(), "unknown variable PROF_PROT") {
+		t.Fatalf("prod rejects unknown prefixed keys: %v", err)
+	}
+
+	h := &profileHook{}
+	envvar.SetHook(h)
+	defer envvar.SetHook(nil)
+	envvar.SetProfile(envvar.ProfileStaging)
+	if got := envvar.GetAsOr("PROF_WORKERS", 4); got != 4 || h.errs != 1 {
+		t.Fatalf("staging falls back and reports: %d, %d errors", got, h.errs)
+	}
+
+	envvar.SetProfile(envvar.ProfileDev)
+	if got := envvar.GetIntOr("PROF_WORKERS", 4); got != 4 {
+		t.Fatalf("dev falls back: %d", got)
+	}
+}
+
+// profileHook counts the errors reported through ErrorHook.
+type profileHook struct {
+	errs int
+}
+
+func (h *profileHook) OnLoad(string, int)                       {}
+func (h *profileHook) OnGet(string, bool, error, time.Duration) {}
+func (h *profileHook) OnError(string, error)                    { h.errs++ }
+
+func TestEventStream(t *testing.T) {
+	events := envvar.Events()
+	defer envvar.StopEvents(events)
+	t.Setenv("EVT_PORT", "0")
+	var cfg struct {
+		Port int `env:"EVT_PORT" validate:"min=1"`
+	}
+	if err := envvar.Bind(&cfg); err == nil {
+		t.Fatal("want validation error")
+	}
+	select {
+	case e := <-events:
+		if e.Kind != envvar.EventValidationFailed || e.Key != "EVT_PORT" {
+			t.Fatalf("event = %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+}
+
+// Pinned security-sensitive settings
+func TestPinnedValue(t *testing.T) {
+	t.Setenv("PIN_ISSUER", "https://evil.example.com")
+	if err := envvar.Pin("PIN_ISSUER", envvar.PinHash("https://auth.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	defer envvar.Pin("PIN_ISSUER", "")
+
+	// The default policy only alerts.
+	if v, ok := envvar.Get("PIN_ISSUER"); !ok || v != "https://evil.example.com" {
+		t.Fatalf("alert policy must keep the value: %q", v)
+	}
+	if err := envvar.VerifyPins(); err != nil {
+		t.Fatalf("alert policy: %v", err)
+	}
+
+	envvar.SetPinPolicy(envvar.PinFail)
+	defer envvar.SetPinPolicy(envvar.PinAlert)
+	if err := envvar.VerifyPins(); !errors.Is(err, envvar.ErrPinMismatch) {
+		t.Fatalf("VerifyPins: %v", err)
+	}
+	var cfg struct {
+		Issuer string `env:"PIN_ISSUER"`
+	}
+	if err := envvar.Bind(&cfg); !errors.Is(err, envvar.ErrPinMismatch) {
+		t.Fatalf("Bind: %v", err)
+	}
+	t.Setenv("PIN_ISSUER", "https://auth.example.com")
+	if err := envvar.Bind(&cfg); err != nil || envvar.VerifyPins() != nil {
+		t.Fatalf("matching value: %v", err)
+	}
+	if err := envvar.Pin("PIN_ISSUER", "nope"); err == nil {
+		t.Fatal("invalid digest must fail")
+	}
+}
+
+// Redacted dumps in several formats
+func TestDumpFormats(t *testing.T) {
+	t.Setenv("DF_HOST", "db local")
+	t.Setenv("DF_API_TOKEN", "s3cret")
+	dotenv := string(envvar.DumpRedactedDotenv())
+	if !strings.Contains(dotenv, "DF_API_TOKEN=***\nDF_HOST=\"db local\"\n") {
+		t.Fatalf("dotenv:\n%s", dotenv)
+	}
+	var m map[string]string
+	if err := json.Unmarshal(envvar.DumpRedactedJSON(), &m); err != nil ||
+		m["DF_API_TOKEN"] != "***" || m["DF_HOST"] != "db local" {
+		t.Fatalf("json: %v, %v", m, err)
+	}
+	var b strings.Builder
+	if err := envvar.Dump(&b, envvar.DumpLogfmt, envvar.RedactAll); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "DF_API_TOKEN=*** DF_HOST=***") {
+		t.Fatalf("logfmt: %s", b.String())
+	}
+	if err := envvar.Dump(&b, "xml", envvar.RedactSecrets); err == nil {
+		t.Fatal("unknown format must fail")
+	}
+}
+
+// Dumping only the variables the application read
+func TestDumpUsed(t *testing.T) {
+	t.Setenv("DU_READ", "1")
+	t.Setenv("DU_BOUND_SECRET", "s3cret")
+	t.Setenv("DU_UNRELATED", "x")
+	envvar.SetRecordAccess(true)
+	defer envvar.SetRecordAccess(false)
+	_ = envvar.GetOr("DU_READ", "")
+	var cfg struct {
+		Secret string `env:"DU_BOUND_SECRET"`
+		Unset  string `env:"DU_UNSET"`
+	}
+	if err := envvar.Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	used := envvar.DumpUsed()
+	if used["DU_READ"] != "1" || used["DU_BOUND_SECRET"] != "***" {
+		t.Fatalf("used = %v", used)
+	}
+	if _, ok := used["DU_UNRELATED"]; ok {
+		t.Fatal("unread variables must be omitted")
+	}
+	if _, ok := used["DU_UNSET"]; ok {
+		t.Fatal("unset variables must be omitted")
+	}
+}
+
+// Diffing environments
+func TestDiffEnvironments(t *testing.T) {
+	changes := envvar.Diff(
+		map[string]string{"HOST": "a", "API_TOKEN": "old", "GONE": "1"},
+		map[string]string{"HOST": "b", "API_TOKEN": "new", "NEW": "2"},
+	)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	want := "~API_TOKEN: *** -> ***,-GONE=1,~HOST: a -> b,+NEW=2"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("Diff = %v", got)
+	}
+
+	t.Setenv("DD_HOST", "prod-db")
+	t.Setenv("DD_PASSWORD", "s3cret")
+	dump := bytes.NewReader(envvar.DumpRedactedDotenv())
+	t.Setenv("DD_HOST", "other-db")
+	t.Setenv("DD_PASSWORD", "rotated")
+	changes, err := envvar.DiffDump(dump)
+	if err != nil || len(changes) != 1 || changes[0].String() != "~DD_HOST: prod-db -> other-db" {
+		t.Fatalf("DiffDump = %v, %v", changes, err)
+	}
+
+	p := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(p, []byte("DD_HOST=local-db\nDD_MISSING=x\n"), 0o600)
+	changes, err = envvar.DiffEnvFile(p)
+	if err != nil || len(changes) != 2 || changes[0].Kind != envvar.Modified ||
+		changes[1].String() != "-DD_MISSING=x" {
+		t.Fatalf("DiffEnvFile = %v, %v", changes, err)
+	}
+}
+
+// Returning the package to a pristine state between tests
+func TestResetGlobalState(t *testing.T) {
+	t.Setenv("RGS_HOST", "db.internal")
+	t.Setenv("RGS_URL", "${RGS_UNSET}/app")
+	t.Setenv("RGS_REF", "ref+rgs://token")
+	metered := sources.WithMetrics(sources.Map{"RGS_HOST": "override"})
+	envvar.SetSource(metered)
+	envvar.RegisterResolver("rgs", refs.ResolverFunc(func(*url.URL) (string, error) {
+		return "resolved", nil
+	}))
+	envvar.SetLimits(envvar.Limits{MaxValueSize: 4})
+	envvar.SetStrictExpansion(true)
+	envvar.SetPinPolicy(envvar.PinFail)
+	envvar.Pin("RGS_HOST", envvar.PinHash("other"))
+	envvar.SetRecordAccess(true)
+	envvar.Get("RGS_HOST")
+
+	envvartest.ResetGlobalState()
+	if st := metered.Stats(); st.Lookups() != 0 {
+		t.Fatalf("metered stats survived reset: %+v", st)
+	}
+	if _, err := envvar.GetOrErr("RGS_REF"); err == nil {
+		t.Fatal("resolver survived reset")
+	}
+	if v, err := envvar.GetOrErr("RGS_HOST"); err != nil || v != "db.internal" {
+		t.Fatalf("RGS_HOST = %q, %v", v, err)
+	}
+	if v, err := envvar.GetOrErr("RGS_URL"); err != nil || v != "/app" {
+		t.Fatalf("RGS_URL = %q, %v", v, err)
+	}
+	for _, a := range envvar.AccessReport() {
+		if a.Key != "RGS_HOST" && a.Key != "RGS_URL" {
+			t.Fatalf("access records survived reset: %+v", a)
+		}
+	}
+}
+
+// Warming slow keys without freezing the rest of the environment
+func TestWarmup(t *testing.T) {
+	t.Cleanup(func() { envvar.SetSource(nil) })
+	t.Setenv("WU_TOKEN", "old")
+	t.Setenv("WU_OTHER", "x")
+	if err := envvar.Warmup(context.Background(), "WU_TOKEN"); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	envvar.Get("WU_OTHER")
+	os.Setenv("WU_OTHER", "y")
+	if v, _ := envvar.Get("WU_OTHER"); v != "y" {
+		t.Fatalf("unwarmed key cached: %q", v)
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("WU_TOKEN=new\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := envvar.LoadEnvFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := envvar.Get("WU_TOKEN"); v != "new" {
+		t.Fatalf("warmed key not invalidated by load: %q", v)
+	}
+}
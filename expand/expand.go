@@ -1,13 +1,93 @@
 package expand
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 )
 
-// ExpandMap expands ${NAME} and ${NAME:-def} in the provided map,
-// referencing keys in the same map first, then falling back to the
-// process environment. Returns a new map.
+// MaxDepth bounds how many levels of references are followed when a
+// referenced value itself contains references.
+const MaxDepth = 32
+
+// strict makes references to undefined variables fail.
+var strict atomic.Bool
+
+// SetStrict makes ${NAME} and $NAME references to undefined variables
+// fail with an *UnsetError instead of expanding to the empty string.
+// References with an operator, such as ${NAME:-def}, are unaffected.
+//
+// Parameters:
+//   - on: Whether strict expansion is enabled.
+func SetStrict(on bool) {
+	strict.Store(on)
+}
+
+// Strict reports whether strict expansion is enabled.
+//
+// Returns:
+//   - bool: Whether strict expansion is enabled.
+func Strict() bool {
+	return strict.Load()
+}
+
+// UnsetError reports a ${NAME:?message} reference to an unset or empty
+// variable.
+type UnsetError struct {
+	// Name is the referenced variable.
+	Name string
+	// Msg is the message from the reference, or a generic one.
+	Msg string
+}
+
+// Error returns the error message.
+//
+// Returns:
+//   - string: The error message.
+func (e *UnsetError) Error() string {
+	return "envvar: " + e.Name + ": " + e.Msg
+}
+
+// CycleError reports a chain of references that leads back to itself,
+// such as A=${B} and B=${A}.
+type CycleError struct {
+	// Chain lists the references from the first repeated name back to
+	// itself, e.g. [A B A].
+	Chain []string
+}
+
+// Error returns the error message.
+//
+// Returns:
+//   - string: The error message.
+func (e *CycleError) Error() string {
+	return "envvar: expansion cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// DepthError reports a chain of references deeper than MaxDepth.
+type DepthError struct {
+	// Chain lists the references followed before giving up.
+	Chain []string
+}
+
+// Error returns the error message.
+//
+// Returns:
+//   - string: The error message.
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("envvar: expansion deeper than %d levels: %s",
+		MaxDepth, strings.Join(e.Chain, " -> "))
+}
+
+// ExpandMap expands ${NAME} references and their :-, :+, and :?
+// operators in the provided map, referencing keys in the same map
+// first, then falling back to the process environment. Referenced
+// values are expanded recursively and names may nest, as in
+// ${PREFIX_${REGION}}. Returns a new map. References that cannot be
+// resolved, such as cycles, chains deeper than MaxDepth, or a failed
+// ${NAME:?message}, are kept literally; use ExpandMapE to get the
+// error instead.
 //
 // Parameters:
 //   - in: The map to expand.
@@ -15,41 +95,51 @@ import (
 // Returns:
 //   - map[string]string: The expanded map.
 func ExpandMap(in map[string]string) map[string]string {
+	out, _ := expandMap(in, true)
+	return out
+}
+
+// ExpandMapE is like ExpandMap but returns ${NAME:?message} failures as
+// an *UnsetError, and reference cycles or chains deeper than MaxDepth
+// as a *CycleError or *DepthError, instead of keeping them literally.
+//
+// Parameters:
+//   - in: The map to expand.
+//
+// Returns:
+//   - map[string]string: The expanded map.
+//   - error: The *UnsetError of the first failed :? reference.
+func ExpandMapE(in map[string]string) (map[string]string, error) {
+	return expandMap(in, false)
+}
+
+// expandMap implements ExpandMap and ExpandMapE; lenient keeps
+// unresolvable references literally.
+func expandMap(in map[string]string, lenient bool) (map[string]string, error) {
 	if len(in) == 0 {
-		return map[string]string{}
+		return map[string]string{}, nil
+	}
+	look := func(name string) (string, bool) {
+		if v, ok := in[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
 	}
 	out := make(map[string]string, len(in))
 	for k, v := range in {
-		out[k] = v
-	}
-	// Resolve with bounded iterations to avoid cycles.
-	for iter := 0; iter < 10; iter++ {
-		stable := true
-		for k, v := range out {
-			nv := expandWithLookup(v, func(name string) (string, bool) {
-				if vv, ok := out[name]; ok {
-					return vv, true
-				}
-				if vv, ok := os.LookupEnv(name); ok {
-					return vv, true
-				}
-				return os.LookupEnv(name)
-			})
-			if nv != v {
-				out[k] = nv
-				stable = false
-			}
-		}
-		if stable {
-			break
+		e := &expander{look: look, chain: []string{k}, lenient: lenient}
+		nv, err := e.expand(v)
+		if err != nil {
+			return nil, err
 		}
+		out[k] = nv
 	}
-	return out
+	return out, nil
 }
 
-// MustExpandMap is like ExpandMap but intended for init-time usage.
-// where expansion failure should abort. Currently expansion cannot
-// fail, so this mirrors ExpandMap and exists for API completeness.
+// MustExpandMap is like ExpandMapE but panics on failure, for
+// init-time usage where a failed ${NAME:?message} reference should
+// abort.
 //
 // Parameters:
 //   - in: The map to expand.
@@ -57,10 +147,19 @@ func ExpandMap(in map[string]string) map[string]string {
 // Returns:
 //   - map[string]string: The expanded map.
 func MustExpandMap(in map[string]string) map[string]string {
-	return ExpandMap(in)
+	out, err := ExpandMapE(in)
+	if err != nil {
+		panic(err)
+	}
+	return out
 }
 
-// Expand applies ${NAME} and ${NAME:-def} using process env first.
+// Expand applies ${NAME}, ${NAME:-def}, ${NAME:+alt}, and
+// ${NAME:?message} using process env. Referenced values are expanded
+// recursively and names may nest, as in ${PREFIX_${REGION}}.
+// References that cannot be resolved, such as cycles, chains deeper
+// than MaxDepth, a failed ${NAME:?message}, or undefined names under
+// SetStrict, are kept literally; use ExpandE to get the error instead.
 //
 // Parameters:
 //   - s: The string to expand.
@@ -68,41 +167,252 @@ func MustExpandMap(in map[string]string) map[string]string {
 // Returns:
 //   - string: The expanded string.
 func Expand(s string) string {
-	// First handle ${NAME} and ${NAME:-def} ourselves to preserve defaults,
-	// then allow $NAME and ${NAME} leftovers via os.ExpandEnv.
-	s = expandWithLookup(s, os.LookupEnv)
-	return os.ExpandEnv(s)
+	out, _ := (&expander{look: os.LookupEnv, bare: true, lenient: true}).expand(s)
+	return out
+}
+
+// ExpandE is like Expand but returns a failed ${NAME:?message}
+// reference, or an undefined reference under SetStrict, as an
+// *UnsetError, and reference cycles or chains deeper than MaxDepth as a
+// *CycleError or *DepthError.
+//
+// Parameters:
+//   - s: The string to expand.
+//
+// Returns:
+//   - string: The expanded string.
+//   - error: The expansion error, if any.
+func ExpandE(s string) (string, error) {
+	return ExpandWith(s, os.LookupEnv)
+}
+
+// ExpandWith is like ExpandE but resolves ${NAME} and $NAME references
+// with look instead of the process environment, e.g. against a source
+// chain. Both forms are handled in one pass, and the $NAME references
+// of referenced values are expanded in turn.
+//
+// Parameters:
+//   - s: The string to expand.
+//   - look: The lookup for referenced names.
+//
+// Returns:
+//   - string: The expanded string.
+//   - error: The expansion error, if any.
+func ExpandWith(s string, look func(string) (string, bool)) (string, error) {
+	return (&expander{look: look, bare: true}).expand(s)
+}
+
+// isNameByte reports whether c may appear in a $NAME reference, first
+// being whether it is the first byte.
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		!first && c >= '0' && c <= '9'
 }
 
-// expandWithLookup is a generic expander that resolves ${NAME:-def}
-// with a provided lookup function.
-func expandWithLookup(s string, look func(string) (string, bool)) string {
-	// Handle ${NAME:-default} segments. Keep this non-nesting for
-	// clarity and performance.
+// undefined returns the strict-mode error for name.
+func undefined(name string) error {
+	return &UnsetError{Name: name, Msg: "undefined variable in expansion"}
+}
+
+// expander resolves ${NAME}, ${NAME:-def}, ${NAME:+alt}, and
+// ${NAME:?message} with a lookup function. As in POSIX shells, :+ and
+// :? treat an empty value like an unset one.
+type expander struct {
+	look func(string) (string, bool)
+	// chain holds the names whose values are being expanded.
+	chain []string
+	// bare also resolves $NAME references, where NAME is a letter or
+	// '_' followed by letters, digits, or '_'.
+	bare bool
+	// lenient keeps a reference that fails to resolve literally
+	// instead of returning the error.
+	lenient bool
+}
+
+// expand replaces the references in s in a single pass. Strings
+// without '$' are returned as is, without allocating.
+func (e *expander) expand(s string) (string, error) {
+	i := strings.IndexByte(s, '$')
+	if i < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	b.WriteString(s[:i])
+	if err := e.expandTo(&b, s[i:]); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// expandTo writes s to b with its references replaced. A '$' that
+// starts no reference, including an unterminated "${", is kept
+// literally.
+func (e *expander) expandTo(b *strings.Builder, s string) error {
 	for {
-		i := strings.Index(s, "${")
+		i := strings.IndexByte(s, '$')
 		if i < 0 {
-			break
+			b.WriteString(s)
+			return nil
 		}
-		j := strings.Index(s[i:], "}")
-		if j < 0 {
-			break
+		b.WriteString(s[:i])
+		s = s[i:]
+		if len(s) > 1 && s[1] == '{' {
+			j := closing(s, 2)
+			if j < 0 {
+				b.WriteString("${")
+				s = s[2:]
+				continue
+			}
+			v, err := e.ref(s[2:j])
+			if err != nil && !e.lenient {
+				return err
+			}
+			if err != nil {
+				v = s[:j+1]
+			}
+			b.WriteString(v)
+			s = s[j+1:]
+			continue
+		}
+		j := 1
+		for e.bare && j < len(s) && isNameByte(s[j], j == 1) {
+			j++
 		}
-		j += i
-		inner := s[i+2 : j]
-		name, def, hasDef := strings.Cut(inner, ":-")
-		if name == "" {
-			s = s[:i] + s[j+1:]
+		if j == 1 {
+			b.WriteByte('$')
+			s = s[1:]
 			continue
 		}
-		if v, ok := look(name); ok {
-			s = s[:i] + v + s[j+1:]
-		} else if hasDef {
-			s = s[:i] + def + s[j+1:]
-		} else {
-			// Missing and no default -> drop to empty.
-			s = s[:i] + "" + s[j+1:]
+		name := s[1:j]
+		v, ok, err := e.value(name)
+		if err == nil && !ok && strict.Load() {
+			err = undefined(name)
+		}
+		if err != nil && !e.lenient {
+			return err
+		}
+		if err != nil {
+			v = s[:j]
+		}
+		b.WriteString(v)
+		s = s[j:]
+	}
+}
+
+// ref resolves the inside of one ${...} reference.
+func (e *expander) ref(inner string) (string, error) {
+	if fname, farg, fn, err := lookupFunc(inner); fname != "" {
+		if err != nil {
+			return "", err
+		}
+		if farg, err = e.expand(farg); err != nil {
+			return "", err
+		}
+		v, err := fn(farg)
+		if err != nil {
+			return "", fmt.Errorf("envvar: ${%s:...}: %w", fname, err)
+		}
+		return v, nil
+	}
+	name, op, arg := splitRef(inner)
+	name, err := e.expand(name)
+	if err != nil || name == "" {
+		return "", err
+	}
+	v, ok, err := e.value(name)
+	if err != nil {
+		return "", err
+	}
+	switch op {
+	case "":
+		if !ok && strict.Load() {
+			return "", undefined(name)
+		}
+	case "-":
+		if !ok {
+			return e.expand(arg)
+		}
+	case "+":
+		if ok && v != "" {
+			return e.expand(arg)
+		}
+		return "", nil
+	case "?":
+		if !ok || v == "" {
+			if arg == "" {
+				arg = "parameter null or not set"
+			}
+			return "", &UnsetError{Name: name, Msg: arg}
+		}
+	}
+	// Missing and no operator -> drop to empty unless strict.
+	return v, nil
+}
+
+// value looks up name and expands the references in its value.
+func (e *expander) value(name string) (string, bool, error) {
+	v, ok := e.look(name)
+	if !ok || !e.hasRef(v) {
+		return v, ok, nil
+	}
+	for i, n := range e.chain {
+		if n == name {
+			chain := append(append([]string(nil), e.chain[i:]...), name)
+			return "", false, &CycleError{Chain: chain}
+		}
+	}
+	if len(e.chain) >= MaxDepth {
+		chain := append(append([]string(nil), e.chain...), name)
+		return "", false, &DepthError{Chain: chain}
+	}
+	e.chain = append(e.chain, name)
+	v, err := e.expand(v)
+	e.chain = e.chain[:len(e.chain)-1]
+	return v, true, err
+}
+
+// hasRef reports whether v may contain a reference.
+func (e *expander) hasRef(v string) bool {
+	if e.bare {
+		return strings.IndexByte(v, '$') >= 0
+	}
+	return strings.Contains(v, "${")
+}
+
+// closing returns the index of the '}' that closes a reference whose
+// body starts at from, skipping nested references, or -1.
+func closing(s string, from int) int {
+	depth := 0
+	for k := from; k < len(s); k++ {
+		switch {
+		case s[k] == '$' && k+1 < len(s) && s[k+1] == '{':
+			depth++
+			k++
+		case s[k] == '}':
+			if depth == 0 {
+				return k
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// splitRef splits the inside of ${...} into the name, the operator
+// ("-", "+", "?", or "" for none), and its argument. Operators inside
+// nested references belong to those references.
+func splitRef(inner string) (name, op, arg string) {
+	for k := 0; k+1 < len(inner); k++ {
+		if inner[k] == '$' && inner[k+1] == '{' {
+			if j := closing(inner, k+2); j >= 0 {
+				k = j
+				continue
+			}
+		}
+		if inner[k] == ':' && strings.IndexByte("-+?", inner[k+1]) >= 0 {
+			return inner[:k], inner[k+1 : k+2], inner[k+2:]
 		}
 	}
-	return s
+	return inner, "", ""
 }
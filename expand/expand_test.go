@@ -0,0 +1,96 @@
+package expand
+
+import (
+	"errors"
+	"testing"
+)
+
+func testLook(name string) (string, bool) {
+	switch name {
+	case "HOST":
+		return "db.local", true
+	case "PORT":
+		return "5432", true
+	case "DSN":
+		return "postgres://$HOST:${PORT}/app", true
+	case "EMPTY":
+		return "", true
+	}
+	return "", false
+}
+
+func TestExpandWith(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain value", "plain value"},
+		{"${HOST}:$PORT", "db.local:5432"},
+		{"${DSN}?sslmode=${MODE:-disable}", "postgres://db.local:5432/app?sslmode=disable"},
+		{"${EMPTY:+set}${HOST:+set}", "set"},
+		{"cost: $5, ${unterminated $HOST", "cost: $5, ${unterminated db.local"},
+		{"$$HOST-$", "$db.local-$"},
+		{"${MISSING}$MISSING", ""},
+	}
+	for _, tt := range tests {
+		got, err := ExpandWith(tt.in, testLook)
+		if err != nil || got != tt.want {
+			t.Errorf("ExpandWith(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandWithNoAlloc(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = ExpandWith("no references here", testLook)
+	})
+	if allocs != 0 {
+		t.Fatalf("allocs = %v, want 0", allocs)
+	}
+}
+
+func BenchmarkExpandWith(b *testing.B) {
+	cases := []struct{ name, in string }{
+		{"plain", "https://example.com/api/v1"},
+		{"braced", "${HOST}:${PORT}"},
+		{"bare", "$HOST:$PORT"},
+		{"nested", "${DSN}?sslmode=${MODE:-disable}"},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ExpandWith(c.in, testLook); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandKeepsUnresolvable(t *testing.T) {
+	in := map[string]string{"A": "${B}", "B": "${A}", "C": "x${NEED:?set NEED}"}
+	out := ExpandMap(in)
+	if out["A"] != "${A}" || out["B"] != "${B}" || out["C"] != "x${NEED:?set NEED}" {
+		t.Fatalf("ExpandMap = %v", out)
+	}
+	var ce *CycleError
+	if _, err := ExpandMapE(map[string]string{"A": "${B}", "B": "${A}"}); !errors.As(err, &ce) {
+		t.Fatalf("ExpandMapE: %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("MustExpandMap must panic")
+			}
+		}()
+		MustExpandMap(map[string]string{"A": "${B}", "B": "${A}"})
+	}()
+
+	SetStrict(true)
+	defer SetStrict(false)
+	t.Setenv("EKU_SET", "v")
+	if got := Expand("$EKU_SET-${EKU_UNSET}-$EKU_UNSET"); got != "v-${EKU_UNSET}-$EKU_UNSET" {
+		t.Fatalf("Expand = %q", got)
+	}
+	if _, err := ExpandE("${EKU_UNSET}"); !errors.As(err, new(*UnsetError)) {
+		t.Fatalf("ExpandE: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package expand
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Func transforms the expanded argument of a ${name:arg} reference.
+type Func func(arg string) (string, error)
+
+var (
+	funcsMu sync.RWMutex
+	// funcs holds the registered functions.
+	funcs = builtinFuncs()
+	// enabled holds the names of the functions usable in values.
+	enabled = map[string]bool{}
+)
+
+// builtinFuncs returns the functions registered before any
+// RegisterFunc call.
+func builtinFuncs() map[string]Func {
+	return map[string]Func{
+		"file":      readFile,
+		"b64":       decodeBase64,
+		"urlencode": func(arg string) (string, error) { return url.QueryEscape(arg), nil },
+	}
+}
+
+// Reset removes registered functions, restoring the built-in ones,
+// disables all of them, and turns strict expansion off. Meant for
+// tests; see envvartest.ResetGlobalState.
+func Reset() {
+	funcsMu.Lock()
+	funcs = builtinFuncs()
+	enabled = map[string]bool{}
+	funcsMu.Unlock()
+	SetStrict(false)
+}
+
+// RegisterFunc registers fn as the expansion function name, replacing
+// any previous one. Registered functions stay disabled until enabled
+// with EnableFuncs.
+//
+// Parameters:
+//   - name: The function name used in ${name:arg}.
+//   - fn: The function.
+func RegisterFunc(name string, fn Func) {
+	funcsMu.Lock()
+	defer funcsMu.Unlock()
+	funcs[name] = fn
+}
+
+// EnableFuncs sets the expansion functions that values may call,
+// replacing the previous set. No function is enabled by default, since
+// functions such as file read local state on behalf of whoever controls
+// the environment. Built-in functions:
+//
+//   - ${file:/path}: the file contents without trailing newlines.
+//   - ${b64:data}: the standard base64 decoding of data.
+//   - ${urlencode:text}: text escaped for URL query strings.
+//
+// Arguments are expanded first, so ${urlencode:${PASSWORD}} works. A
+// call to a registered but disabled function fails instead of being
+// read as a variable name.
+//
+// Parameters:
+//   - names: The functions to enable; none disables all.
+//
+// Returns:
+//   - error: The error if a name is not registered.
+func EnableFuncs(names ...string) error {
+	funcsMu.Lock()
+	defer funcsMu.Unlock()
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		if _, ok := funcs[n]; !ok {
+			return fmt.Errorf("envvar: unknown expansion function %q", n)
+		}
+		set[n] = true
+	}
+	enabled = set
+	return nil
+}
+
+// EnabledFuncs returns the enabled expansion functions in sorted order.
+//
+// Returns:
+//   - []string: The enabled function names.
+func EnabledFuncs() []string {
+	funcsMu.RLock()
+	defer funcsMu.RUnlock()
+	out := make([]string, 0, len(enabled))
+	for n := range enabled {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// lookupFunc returns the registered function called by the inside of a
+// ${...} reference, its name, and its unexpanded argument. name is ""
+// when inner does not call a registered function.
+func lookupFunc(inner string) (name, arg string, fn Func, err error) {
+	k := strings.IndexByte(inner, ':')
+	if k <= 0 || (k+1 < len(inner) && strings.IndexByte("-+?", inner[k+1]) >= 0) {
+		return "", "", nil, nil
+	}
+	funcsMu.RLock()
+	defer funcsMu.RUnlock()
+	name = inner[:k]
+	if fn = funcs[name]; fn == nil {
+		return "", "", nil, nil
+	}
+	if !enabled[name] {
+		return name, "", nil,
+			fmt.Errorf("envvar: expansion function %q is not enabled", name)
+	}
+	return name, inner[k+1:], fn, nil
+}
+
+// readFile returns the contents of the file at path without trailing
+// newlines.
+func readFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// decodeBase64 decodes standard base64, padded or not.
+func decodeBase64(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		b, err = base64.RawStdEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,311 @@
+// Package gen generates typed accessors for a tagged config struct: a
+// block of key constants and one function per field, replacing
+// stringly-typed calls such as MustGetInt("PORT") scattered through a
+// codebase.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	envtypes "github.com/aatuh/envvar/v2/types"
+)
+
+// Options configures Generate.
+type Options struct {
+	// TypeName is the struct type to read tags from.
+	TypeName string
+	// Package is the package name of the generated file.
+	Package string
+}
+
+// field is a resolved accessor.
+type field struct {
+	Name     string
+	Key      string
+	GoType   string
+	Required bool
+	Default  string
+	HasDef   bool
+}
+
+// accessor describes how to read one supported Go type.
+type accessor struct {
+	get, getOr, mustGet string
+	literal             func(def string) (string, error)
+}
+
+// accessors maps field type expressions to envvar getters.
+var accessors = map[string]accessor{
+	"string":        {"", "GetOr", "MustGet", quoteLit},
+	"bool":          {"", "GetBoolOr", "MustGetBool", boolLit},
+	"int":           {"", "GetIntOr", "MustGetInt", intLit(64)},
+	"int64":         {"", "GetInt64Or", "MustGetInt64", intLit(64)},
+	"uint":          {"", "GetUintOr", "MustGetUint", uintLit},
+	"uint64":        {"", "GetUint64Or", "MustGetUint64", uintLit},
+	"float64":       {"", "GetFloat64Or", "MustGetFloat64", floatLit},
+	"time.Duration": {"", "GetDurationOr", "MustGetDuration", durationLit},
+	"[]string":      {"GetStringSlice", "", "MustGetStringSlice", sliceLit},
+	"*url.URL":      {"GetURL", "", "MustGetURL", nil},
+}
+
+// Generate parses Go source and returns a formatted file with key
+// constants and typed accessors for opts.TypeName.
+//
+// Parameters:
+//   - filename: The source file name, used in error messages.
+//   - src: The Go source containing the struct.
+//   - opts: The options.
+//
+// Returns:
+//   - []byte: The generated source.
+//   - error: The error if parsing or generation fails.
+func Generate(filename string, src []byte, opts Options) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	structs := map[string]*ast.StructType{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok {
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				structs[ts.Name.Name] = st
+			}
+		}
+		return true
+	})
+	st, ok := structs[opts.TypeName]
+	if !ok {
+		return nil, fmt.Errorf("envvar: type %s not found in %s",
+			opts.TypeName, filename)
+	}
+	var fields []field
+	if err := collect(st, structs, &fields); err != nil {
+		return nil, err
+	}
+	if opts.Package == "" {
+		opts.Package = file.Name.Name
+	}
+	return render(opts, fields)
+}
+
+// collect gathers tagged fields, descending into embedded structs
+// declared in the same file.
+func collect(st *ast.StructType, structs map[string]*ast.StructType, out *[]field) error {
+	for _, f := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(s)
+		}
+		ev, ok := tag.Lookup("env")
+		if !ok {
+			if len(f.Names) == 0 {
+				if emb, ok := structs[typeString(f.Type)]; ok {
+					if err := collect(emb, structs, out); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		key, opts, _ := strings.Cut(ev, ",")
+		// Accessors read the primary key of "NEW|OLD" alias lists.
+		key, _, _ = strings.Cut(key, "|")
+		if err := envtypes.ValidName(strings.TrimSpace(key)); err != nil {
+			return err
+		}
+		def, hasDef := tag.Lookup("envdef")
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			*out = append(*out, field{
+				Name:     n.Name,
+				Key:      strings.TrimSpace(key),
+				GoType:   typeString(f.Type),
+				Required: strings.Contains(","+opts+",", ",required,"),
+				Default:  def,
+				HasDef:   hasDef && def != "",
+			})
+		}
+	}
+	return nil
+}
+
+// render writes the generated file.
+func render(opts Options, fields []field) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by envvargen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", opts.Package)
+	imports := map[string]bool{}
+	var body bytes.Buffer
+
+	body.WriteString("// Environment variable keys of " + opts.TypeName + ".\nconst (\n")
+	for _, f := range fields {
+		fmt.Fprintf(&body, "\tKey%s = %q\n", f.Name, f.Key)
+	}
+	body.WriteString(")\n\n")
+
+	body.WriteString("// AllKeys lists every key of " + opts.TypeName + ".\nvar AllKeys = []string{\n")
+	for _, f := range fields {
+		fmt.Fprintf(&body, "\tKey%s,\n", f.Name)
+	}
+	body.WriteString("}\n")
+
+	for _, f := range fields {
+		acc, ok := accessors[f.GoType]
+		if !ok {
+			fmt.Fprintf(&body, "\n// %s: unsupported type %s, no accessor generated.\n",
+				f.Name, f.GoType)
+			continue
+		}
+		imports["github.com/aatuh/envvar/v2"] = true
+		if strings.Contains(f.GoType, "time.") {
+			imports["time"] = true
+		}
+		if strings.Contains(f.GoType, "url.") {
+			imports["net/url"] = true
+		}
+		fmt.Fprintf(&body, "\n// %s returns %s as %s.\nfunc %s() %s {\n",
+			f.Name, f.Key, f.GoType, f.Name, f.GoType)
+		switch {
+		case f.HasDef && acc.getOr != "":
+			lit, err := acc.literal(f.Default)
+			if err != nil {
+				return nil, fmt.Errorf("envvar: %s: envdef %q: %w", f.Name, f.Default, err)
+			}
+			fmt.Fprintf(&body, "\treturn envvar.%s(Key%s, %s)\n", acc.getOr, f.Name, lit)
+		case f.HasDef && acc.literal != nil:
+			lit, err := acc.literal(f.Default)
+			if err != nil {
+				return nil, fmt.Errorf("envvar: %s: envdef %q: %w", f.Name, f.Default, err)
+			}
+			fmt.Fprintf(&body, "\tif v, err := envvar.%s(Key%s); err == nil {\n\t\treturn v\n\t}\n\treturn %s\n",
+				acc.get, f.Name, lit)
+		case f.Required:
+			fmt.Fprintf(&body, "\treturn envvar.%s(Key%s)\n", acc.mustGet, f.Name)
+		case acc.getOr != "":
+			fmt.Fprintf(&body, "\tvar zero %s\n\treturn envvar.%s(Key%s, zero)\n",
+				f.GoType, acc.getOr, f.Name)
+		default:
+			fmt.Fprintf(&body, "\tv, _ := envvar.%s(Key%s)\n\treturn v\n", acc.get, f.Name)
+		}
+		body.WriteString("}\n")
+	}
+
+	// Without accessors nothing refers to envvar, and an unused
+	// import would not compile.
+	if imports["github.com/aatuh/envvar/v2"] {
+		b.WriteString("import (\n")
+		for _, p := range []string{"net/url", "time"} {
+			if imports[p] {
+				fmt.Fprintf(&b, "\t%q\n", p)
+			}
+		}
+		b.WriteString("\n\t\"github.com/aatuh/envvar/v2\"\n)\n\n")
+	}
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// typeString renders a field type expression.
+func typeString(e ast.Expr) string {
+	return types.ExprString(e)
+}
+
+// quoteLit renders a string literal.
+func quoteLit(def string) (string, error) {
+	return strconv.Quote(def), nil
+}
+
+// boolLit renders a boolean literal.
+func boolLit(def string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(def)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return "true", nil
+	case "0", "f", "false", "n", "no", "off":
+		return "false", nil
+	}
+	return "", fmt.Errorf("invalid boolean")
+}
+
+// intLit returns a renderer for signed integer literals.
+func intLit(bits int) func(string) (string, error) {
+	return func(def string) (string, error) {
+		i, err := strconv.ParseInt(strings.TrimSpace(def), 10, bits)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(i, 10), nil
+	}
+}
+
+// uintLit renders an unsigned integer literal.
+func uintLit(def string) (string, error) {
+	u, err := strconv.ParseUint(strings.TrimSpace(def), 10, 64)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(u, 10), nil
+}
+
+// floatLit renders a float literal.
+func floatLit(def string) (string, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(def), 64)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
+
+// sliceLit renders a []string literal from a comma-separated default.
+func sliceLit(def string) (string, error) {
+	var b strings.Builder
+	b.WriteString("[]string{")
+	for i, p := range strings.Split(def, ",") {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(strings.TrimSpace(p)))
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+// durationLit renders a duration as a multiple of its largest exact unit.
+func durationLit(def string) (string, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(def))
+	if err != nil {
+		return "", err
+	}
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d != 0 && d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name), nil
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d)), nil
+}
@@ -0,0 +1,79 @@
+package gen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+const src = `package app
+
+import (
+	"net/url"
+	"time"
+)
+
+type HTTP struct {
+	Timeout time.Duration ` + "`env:\"HTTP_TIMEOUT\" envdef:\"1500ms\"`" + `
+}
+
+type Config struct {
+	HTTP
+	Port  int      ` + "`env:\"PORT,required\"`" + `
+	Debug bool     ` + "`env:\"DEBUG\"`" + `
+	Name  string   ` + "`env:\"APP_NAME\" envdef:\"svc\"`" + `
+	Tags  []string ` + "`env:\"TAGS\" envdef:\"a,b\"`" + `
+	DSN   *url.URL ` + "`env:\"DSN\"`" + `
+	Ch    chan int ` + "`env:\"CH\"`" + `
+}
+`
+
+func TestGenerate(t *testing.T) {
+	out, err := Generate("config.go", []byte(src), Options{TypeName: "Config", Package: "cfgkeys"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(out)
+	for _, want := range []string{
+		"package cfgkeys",
+		`KeyTimeout = "HTTP_TIMEOUT"`,
+		"return envvar.GetDurationOr(KeyTimeout, 1500*time.Millisecond)",
+		"func Port() int {\n\treturn envvar.MustGetInt(KeyPort)",
+		"return envvar.GetBoolOr(KeyDebug, zero)",
+		`return envvar.GetOr(KeyName, "svc")`,
+		`return []string{"a", "b"}`,
+		"v, _ := envvar.GetURL(KeyDSN)",
+		"Ch: unsupported type chan int",
+		`"net/url"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("missing %q in:\n%s", want, code)
+		}
+	}
+
+	if _, err := Generate("config.go", []byte(src), Options{TypeName: "Nope"}); err == nil {
+		t.Fatalf("expected missing type error")
+	}
+}
+
+func TestGenerateNoAccessors(t *testing.T) {
+	const only = "package app\n\ntype Config struct {\n\tCh chan int `env:\"CH\"`\n}\n"
+	out, err := Generate("config.go", []byte(only), Options{TypeName: "Config", Package: "cfgkeys"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "import") {
+		t.Fatalf("unused import in:\n%s", out)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "keys.go", out, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&types.Config{}).Check("cfgkeys", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s", err, out)
+	}
+}
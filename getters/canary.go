@@ -0,0 +1,87 @@
+package getters
+
+import (
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// canaryMu protects canaryID.
+	canaryMu sync.RWMutex
+	// canaryID overrides the hostname as the instance identity.
+	canaryID string
+)
+
+// SetCanaryInstance sets the identity GetCanary hashes to place this
+// instance in or out of a rollout, e.g. a pod name. Defaults to the
+// hostname; "" restores the default.
+//
+// Parameters:
+//   - id: The instance identity.
+func SetCanaryInstance(id string) {
+	canaryMu.Lock()
+	defer canaryMu.Unlock()
+	canaryID = id
+}
+
+// GetCanary returns the value of key, or of key_CANARY for the share of
+// instances given by key_CANARY_PERCENT (0 to 100). An instance is
+// placed by hashing its identity (see SetCanaryInstance) with key, so
+// the choice is stable across restarts, raising the percentage only
+// moves more instances to the canary, and different keys roll out to
+// different instances.
+//
+// Parameters:
+//   - key: The key of the stable value.
+//
+// Returns:
+//   - string: The selected value.
+//   - bool: Whether the canary value was selected.
+//   - error: The error if key_CANARY_PERCENT is invalid, or if the stable
+//     value is selected and missing.
+func GetCanary(key string) (string, bool, error) {
+	canary, ok, err := getRaw(key + "_CANARY")
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		pct, err := canaryPercent(key + "_CANARY_PERCENT")
+		if err != nil {
+			return "", false, err
+		}
+		if canaryBucket(key) < pct {
+			return canary, true, nil
+		}
+	}
+	v, err := lookup(key)
+	return v, false, err
+}
+
+// canaryPercent returns the rollout percentage of key, 0 when unset.
+func canaryPercent(key string) (float64, error) {
+	v, ok, err := getRaw(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+	if err != nil || pct < 0 || pct > 100 {
+		return 0, &KeyError{Key: key, Kind: ErrType, Msg: "expected percentage 0-100"}
+	}
+	return pct, nil
+}
+
+// canaryBucket places this instance for key in [0, 100).
+func canaryBucket(key string) float64 {
+	canaryMu.RLock()
+	id := canaryID
+	canaryMu.RUnlock()
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id + "\x00" + key))
+	return float64(h.Sum32()%10000) / 100
+}
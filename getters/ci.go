@@ -0,0 +1,174 @@
+package getters
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+// CI providers reported by CIInfo.Provider.
+const (
+	CIGitHubActions = "github-actions"
+	CIGitLab        = "gitlab"
+	CICircleCI      = "circleci"
+	CIJenkins       = "jenkins"
+	// CIGeneric is reported when CI=true but no known provider is
+	// detected.
+	CIGeneric = "generic"
+)
+
+// CIInfo describes the CI run the process executes in, normalized
+// across providers. Fields a provider does not expose are empty.
+type CIInfo struct {
+	// Provider is one of the CI* constants, or "" outside CI.
+	Provider string
+	// Branch is the branch being built; for pull requests, the source
+	// branch. "refs/heads/" and "origin/" prefixes are removed.
+	Branch string
+	// Tag is the tag being built, if any.
+	Tag string
+	// Commit is the full commit SHA.
+	Commit string
+	// PR is the pull or merge request number, or 0.
+	PR int
+	// Repo is the repository, e.g. "owner/name".
+	Repo string
+	// BuildID identifies the run within the provider.
+	BuildID string
+	// BuildURL links to the run.
+	BuildURL string
+}
+
+// IsCI reports whether a CI provider was detected.
+//
+// Returns:
+//   - bool: True inside CI.
+func (c CIInfo) IsCI() bool {
+	return c.Provider != ""
+}
+
+// CI detects GitHub Actions, GitLab CI, CircleCI, and Jenkins from
+// their variables, read through the default source, and normalizes
+// the branch, tag, commit, and pull request number.
+//
+// Returns:
+//   - CIInfo: The run; Provider is "" outside CI.
+func CI() CIInfo {
+	switch {
+	case ciVar("GITHUB_ACTIONS") == "true":
+		return githubCI()
+	case ciVar("GITLAB_CI") != "":
+		return gitlabCI()
+	case ciVar("CIRCLECI") == "true":
+		return circleCI()
+	case ciVar("JENKINS_URL") != "":
+		return jenkinsCI()
+	}
+	if on, err := ParseBoolValue(ciVar("CI")); err == nil && on {
+		return CIInfo{Provider: CIGeneric}
+	}
+	return CIInfo{}
+}
+
+// githubCI reads GitHub Actions variables.
+func githubCI() CIInfo {
+	c := CIInfo{
+		Provider: CIGitHubActions,
+		Commit:   ciVar("GITHUB_SHA"),
+		Repo:     ciVar("GITHUB_REPOSITORY"),
+		BuildID:  ciVar("GITHUB_RUN_ID"),
+	}
+	ref := ciVar("GITHUB_REF")
+	switch {
+	case ciVar("GITHUB_HEAD_REF") != "":
+		c.Branch = ciVar("GITHUB_HEAD_REF")
+	case strings.HasPrefix(ref, "refs/tags/"):
+		c.Tag = strings.TrimPrefix(ref, "refs/tags/")
+	case strings.HasPrefix(ref, "refs/heads/"):
+		c.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if n, ok := strings.CutPrefix(ref, "refs/pull/"); ok {
+		c.PR = ciNumber(strings.TrimSuffix(strings.TrimSuffix(n, "/merge"), "/head"))
+	}
+	if srv := ciVar("GITHUB_SERVER_URL"); srv != "" && c.Repo != "" && c.BuildID != "" {
+		c.BuildURL = srv + "/" + c.Repo + "/actions/runs/" + c.BuildID
+	}
+	return c
+}
+
+// gitlabCI reads GitLab CI variables.
+func gitlabCI() CIInfo {
+	c := CIInfo{
+		Provider: CIGitLab,
+		Branch:   ciVar("CI_COMMIT_BRANCH"),
+		Tag:      ciVar("CI_COMMIT_TAG"),
+		Commit:   ciVar("CI_COMMIT_SHA"),
+		PR:       ciNumber(ciVar("CI_MERGE_REQUEST_IID")),
+		Repo:     ciVar("CI_PROJECT_PATH"),
+		BuildID:  ciVar("CI_PIPELINE_ID"),
+		BuildURL: ciVar("CI_PIPELINE_URL"),
+	}
+	if c.Branch == "" {
+		c.Branch = ciVar("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")
+	}
+	return c
+}
+
+// circleCI reads CircleCI variables.
+func circleCI() CIInfo {
+	c := CIInfo{
+		Provider: CICircleCI,
+		Branch:   ciVar("CIRCLE_BRANCH"),
+		Tag:      ciVar("CIRCLE_TAG"),
+		Commit:   ciVar("CIRCLE_SHA1"),
+		PR:       ciNumber(ciVar("CIRCLE_PR_NUMBER")),
+		BuildID:  ciVar("CIRCLE_BUILD_NUM"),
+		BuildURL: ciVar("CIRCLE_BUILD_URL"),
+	}
+	if c.PR == 0 {
+		// CIRCLE_PULL_REQUEST is the PR URL, ending in its number.
+		u := ciVar("CIRCLE_PULL_REQUEST")
+		c.PR = ciNumber(u[strings.LastIndex(u, "/")+1:])
+	}
+	if user, repo := ciVar("CIRCLE_PROJECT_USERNAME"), ciVar("CIRCLE_PROJECT_REPONAME"); user != "" && repo != "" {
+		c.Repo = user + "/" + repo
+	}
+	return c
+}
+
+// jenkinsCI reads Jenkins variables, including those set by the
+// multibranch pipeline plugin.
+func jenkinsCI() CIInfo {
+	c := CIInfo{
+		Provider: CIJenkins,
+		Tag:      ciVar("TAG_NAME"),
+		Commit:   ciVar("GIT_COMMIT"),
+		PR:       ciNumber(ciVar("CHANGE_ID")),
+		BuildID:  ciVar("BUILD_NUMBER"),
+		BuildURL: ciVar("BUILD_URL"),
+	}
+	for _, k := range []string{"CHANGE_BRANCH", "BRANCH_NAME", "GIT_BRANCH"} {
+		if c.Branch = ciVar(k); c.Branch != "" {
+			break
+		}
+	}
+	c.Branch = strings.TrimPrefix(strings.TrimPrefix(c.Branch, "refs/heads/"), "origin/")
+	return c
+}
+
+// ciVar returns the trimmed raw value of key, or "". Values are not
+// expanded: platform values such as Lambda's "$LATEST" contain '$'.
+func ciVar(key string) string {
+	v, _ := sources.Lookup(key)
+	return strings.TrimSpace(v)
+}
+
+// ciNumber parses a positive request number, or returns 0.
+func ciNumber(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
@@ -1,6 +1,10 @@
 package getters
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/aatuh/envvar/v2/types"
+)
 
 // ErrKind describes the class of error.
 type ErrKind int
@@ -9,6 +13,18 @@ const (
 	// ErrMissing is the error kind for missing values.
 	ErrMissing ErrKind = iota + 1
 	ErrType
+	// ErrDecrypt is the error kind for values that fail to decrypt.
+	ErrDecrypt
+	// ErrRef is the error kind for references that fail to resolve.
+	ErrRef
+	// ErrLimit is the error kind for values exceeding types.Limits.
+	ErrLimit
+)
+
+// Sentinels matched by KeyError via errors.Is; see the types package.
+var (
+	ErrMissingVar   = types.ErrMissingVar
+	ErrTypeMismatch = types.ErrTypeMismatch
 )
 
 // KeyError is an error for envvar key-related errors.
@@ -30,6 +46,12 @@ func (e *KeyError) Error() string {
 		b.WriteString("missing ")
 	case ErrType:
 		b.WriteString("type error for ")
+	case ErrDecrypt:
+		b.WriteString("decrypt error for ")
+	case ErrRef:
+		b.WriteString("reference error for ")
+	case ErrLimit:
+		b.WriteString("limit exceeded for ")
 	}
 	b.WriteString(e.Key)
 	if e.Msg != "" {
@@ -38,3 +60,24 @@ func (e *KeyError) Error() string {
 	}
 	return b.String()
 }
+
+// Is reports whether the error matches target: ErrMissingVar for
+// missing values, ErrTypeMismatch for type errors, and types.ErrLimit
+// for values exceeding limits.
+//
+// Parameters:
+//   - target: The sentinel to compare with.
+//
+// Returns:
+//   - bool: Whether the error matches.
+func (e *KeyError) Is(target error) bool {
+	switch target {
+	case types.ErrMissingVar:
+		return e.Kind == ErrMissing
+	case types.ErrTypeMismatch:
+		return e.Kind == ErrType
+	case types.ErrLimit:
+		return e.Kind == ErrLimit
+	}
+	return false
+}
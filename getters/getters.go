@@ -1,14 +1,23 @@
 package getters
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
 	"net/url"
-	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aatuh/envvar/v2/decrypt"
+	expandpkg "github.com/aatuh/envvar/v2/expand"
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/sources"
 	"github.com/aatuh/envvar/v2/types"
 )
 
@@ -36,7 +45,52 @@ func GetOr(key, def string) string {
 	if v, ok := Get(key); ok {
 		return v
 	}
-	return def
+	return defaulted(key, def)
+}
+
+// GetFirst returns the first present key and its value, so a variable
+// can be renamed while older deployments still set the old name, and
+// callers can log which name of the chain is in use. Struct fields get
+// the same chain with `env:"DATABASE_URL|DB_URL"`.
+//
+// Parameters:
+//   - keys: The keys to try in order, e.g. "DATABASE_URL", "DB_URL",
+//     "POSTGRES_URL".
+//
+// Returns:
+//   - string: The key that held the value, or "".
+//   - string: The value.
+//   - bool: The boolean indicating presence of any key.
+func GetFirst(keys ...string) (string, string, bool) {
+	for _, k := range keys {
+		if v, ok := Get(k); ok {
+			return k, v, true
+		}
+	}
+	return "", "", false
+}
+
+// RequireAny checks that at least one of keys is present, for configs
+// that accept either form of a setting, such as a URL or its parts.
+//
+// Parameters:
+//   - keys: The keys, e.g. "REDIS_URL", "REDIS_HOST".
+//
+// Returns:
+//   - error: A missing error naming all keys if none is present, or the
+//     error resolving a present value.
+func RequireAny(keys ...string) error {
+	for _, k := range keys {
+		_, ok, err := getRaw(k)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return &KeyError{Key: strings.Join(keys, "|"), Kind: ErrMissing,
+		Msg: "set at least one of " + strings.Join(keys, ", ")}
 }
 
 // MustGet returns the value or panics if not present.
@@ -63,9 +117,9 @@ func MustGet(key string) string {
 //   - string: The value.
 //   - error: The error if the value is not present.
 func GetOrErr(key string) (string, error) {
-	v, ok := Get(key)
-	if !ok {
-		return "", missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return "", err
 	}
 	return v, nil
 }
@@ -93,11 +147,12 @@ func GetBool(key string) (bool, error) {
 func GetBoolOr(key string, def bool) bool {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	b, err := ParseBoolValue(v)
 	if err != nil {
-		return def
+		fallback(typeErr(key, "bool", v))
+		return defaulted(key, def)
 	}
 	return b
 }
@@ -113,11 +168,36 @@ func GetBoolOr(key string, def bool) bool {
 func MustGetBool(key string) bool {
 	b, err := GetBool(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "boolean, e.g. \"true\""))
 	}
 	return b
 }
 
+// GetBoolTri returns the value as a tri-state boolean: TriUnset when
+// the variable is absent or empty, otherwise TriTrue or TriFalse. Use it
+// to tell "explicitly disabled" from "not configured".
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - types.TriBool: The value.
+//   - error: The error if the value is not a boolean.
+func GetBoolTri(key string) (types.TriBool, error) {
+	v, ok, err := getRaw(key)
+	if err != nil {
+		return types.TriUnset, err
+	}
+	if !ok || strings.TrimSpace(v) == "" {
+		return types.TriUnset, nil
+	}
+	b, err := ParseBoolValue(v)
+	if err != nil {
+		return types.TriUnset, typeErr(key, "bool", v)
+	}
+	return types.TriOf(b), nil
+}
+
 // GetInt returns the value as an integer.
 //
 // Parameters:
@@ -127,9 +207,9 @@ func MustGetBool(key string) bool {
 //   - int: The value.
 //   - error: The error if the value is not present.
 func GetInt(key string) (int, error) {
-	v, ok := Get(key)
-	if !ok {
-		return 0, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
 	}
 	i64, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
 	if err != nil {
@@ -150,11 +230,12 @@ func GetInt(key string) (int, error) {
 func GetIntOr(key string, def int) int {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	i64, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
 	if err != nil {
-		return def
+		fallback(typeErr(key, "int", v))
+		return defaulted(key, def)
 	}
 	return int(i64)
 }
@@ -170,7 +251,7 @@ func GetIntOr(key string, def int) int {
 func MustGetInt(key string) int {
 	v, err := GetInt(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "integer, e.g. \"42\""))
 	}
 	return v
 }
@@ -184,9 +265,9 @@ func MustGetInt(key string) int {
 //   - int64: The value.
 //   - error: The error if the value is not present.
 func GetInt64(key string) (int64, error) {
-	v, ok := Get(key)
-	if !ok {
-		return 0, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
 	}
 	i64, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
 	if err != nil {
@@ -206,11 +287,12 @@ func GetInt64(key string) (int64, error) {
 func GetInt64Or(key string, def int64) int64 {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	i64, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
 	if err != nil {
-		return def
+		fallback(typeErr(key, "int64", v))
+		return defaulted(key, def)
 	}
 	return i64
 }
@@ -225,7 +307,7 @@ func GetInt64Or(key string, def int64) int64 {
 func MustGetInt64(key string) int64 {
 	v, err := GetInt64(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "integer, e.g. \"42\""))
 	}
 	return v
 }
@@ -239,9 +321,9 @@ func MustGetInt64(key string) int64 {
 //   - uint: The value.
 //   - error: The error if the value is not present.
 func GetUint(key string) (uint, error) {
-	v, ok := Get(key)
-	if !ok {
-		return 0, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
 	}
 	u64, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
 	if err != nil {
@@ -261,11 +343,12 @@ func GetUint(key string) (uint, error) {
 func GetUintOr(key string, def uint) uint {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	u64, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
 	if err != nil {
-		return def
+		fallback(typeErr(key, "uint", v))
+		return defaulted(key, def)
 	}
 	return uint(u64)
 }
@@ -280,7 +363,7 @@ func GetUintOr(key string, def uint) uint {
 func MustGetUint(key string) uint {
 	v, err := GetUint(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "unsigned integer, e.g. \"42\""))
 	}
 	return v
 }
@@ -294,9 +377,9 @@ func MustGetUint(key string) uint {
 //   - uint64: The value.
 //   - error: The error if the value is not present.
 func GetUint64(key string) (uint64, error) {
-	v, ok := Get(key)
-	if !ok {
-		return 0, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
 	}
 	u64, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
 	if err != nil {
@@ -316,11 +399,12 @@ func GetUint64(key string) (uint64, error) {
 func GetUint64Or(key string, def uint64) uint64 {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	u64, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
 	if err != nil {
-		return def
+		fallback(typeErr(key, "uint64", v))
+		return defaulted(key, def)
 	}
 	return u64
 }
@@ -335,7 +419,7 @@ func GetUint64Or(key string, def uint64) uint64 {
 func MustGetUint64(key string) uint64 {
 	v, err := GetUint64(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "unsigned integer, e.g. \"42\""))
 	}
 	return v
 }
@@ -350,9 +434,9 @@ func MustGetUint64(key string) uint64 {
 //   - error: The error if the value is not present.
 
 func GetFloat64(key string) (float64, error) {
-	v, ok := Get(key)
-	if !ok {
-		return 0, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
 	}
 	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
 	if err != nil {
@@ -373,11 +457,12 @@ func GetFloat64(key string) (float64, error) {
 func GetFloat64Or(key string, def float64) float64 {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
 	if err != nil {
-		return def
+		fallback(typeErr(key, "float64", v))
+		return defaulted(key, def)
 	}
 	return f
 }
@@ -393,7 +478,7 @@ func GetFloat64Or(key string, def float64) float64 {
 func MustGetFloat64(key string) float64 {
 	v, err := GetFloat64(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "number, e.g. \"1.5\""))
 	}
 	return v
 }
@@ -407,9 +492,9 @@ func MustGetFloat64(key string) float64 {
 //   - time.Duration: The value.
 //   - error: The error if the value is not present.
 func GetDuration(key string) (time.Duration, error) {
-	v, ok := Get(key)
-	if !ok {
-		return 0, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
 	}
 	d, err := time.ParseDuration(strings.TrimSpace(v))
 	if err != nil {
@@ -430,11 +515,12 @@ func GetDuration(key string) (time.Duration, error) {
 func GetDurationOr(key string, def time.Duration) time.Duration {
 	v, ok := Get(key)
 	if !ok {
-		return def
+		return defaulted(key, def)
 	}
 	d, err := time.ParseDuration(strings.TrimSpace(v))
 	if err != nil {
-		return def
+		fallback(typeErr(key, "duration", v))
+		return defaulted(key, def)
 	}
 	return d
 }
@@ -450,11 +536,132 @@ func GetDurationOr(key string, def time.Duration) time.Duration {
 func MustGetDuration(key string) time.Duration {
 	v, err := GetDuration(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "duration, e.g. \"30s\""))
 	}
 	return v
 }
 
+// GetBytes returns the value as a byte size, e.g. "512MB" or "2GiB".
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint64: The size in bytes.
+//   - error: The error if the value is not present or invalid.
+func GetBytes(key string) (uint64, error) {
+	v, err := lookup(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := types.ParseBytes(v)
+	if err != nil {
+		return 0, typeErr(key, "byte size", v)
+	}
+	return n, nil
+}
+
+// GetBytesOr returns the value as a byte size or a default if not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - uint64: The size in bytes or the default.
+func GetBytesOr(key string, def uint64) uint64 {
+	n, err := GetBytes(key)
+	if err != nil {
+		fallback(err)
+		return defaulted(key, def)
+	}
+	return n
+}
+
+// MustGetBytes returns the value as a byte size or panics if not present
+// or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - uint64: The size in bytes.
+func MustGetBytes(key string) uint64 {
+	n, err := GetBytes(key)
+	if err != nil {
+		panic(expected(err, "byte size, e.g. \"512MB\""))
+	}
+	return n
+}
+
+// GetTime returns the value as an RFC 3339 time.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - time.Time: The value.
+//   - error: The error if the value is not present or invalid.
+func GetTime(key string) (time.Time, error) {
+	return GetTimeLayout(key, time.RFC3339)
+}
+
+// GetTimeLayout returns the value as a time parsed with layout.
+//
+// Parameters:
+//   - key: The key to get.
+//   - layout: The time layout, e.g. "2006-01-02".
+//
+// Returns:
+//   - time.Time: The value.
+//   - error: The error if the value is not present or invalid.
+func GetTimeLayout(key, layout string) (time.Time, error) {
+	v, err := lookup(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, strings.TrimSpace(v))
+	if err != nil {
+		return time.Time{}, typeErr(key, "time", v)
+	}
+	return t, nil
+}
+
+// GetTimeOr returns the value as an RFC 3339 time or a default if not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - time.Time: The value or the default.
+func GetTimeOr(key string, def time.Time) time.Time {
+	t, err := GetTime(key)
+	if err != nil {
+		fallback(err)
+		return defaulted(key, def)
+	}
+	return t
+}
+
+// MustGetTime returns the value as an RFC 3339 time or panics if not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - time.Time: The value.
+func MustGetTime(key string) time.Time {
+	t, err := GetTime(key)
+	if err != nil {
+		panic(expected(err, "RFC 3339 time, e.g. \"2024-01-31T15:04:05Z\""))
+	}
+	return t
+}
+
 // GetURL returns the value as a URL.
 //
 // Parameters:
@@ -464,9 +671,9 @@ func MustGetDuration(key string) time.Duration {
 //   - *url.URL: The value.
 //   - error: The error if the value is not present.
 func GetURL(key string) (*url.URL, error) {
-	v, ok := Get(key)
-	if !ok {
-		return nil, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return nil, err
 	}
 	u, err := url.Parse(strings.TrimSpace(v))
 	if err != nil || u.Scheme == "" {
@@ -485,7 +692,7 @@ func GetURL(key string) (*url.URL, error) {
 func MustGetURL(key string) *url.URL {
 	u, err := GetURL(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "absolute URL, e.g. \"https://example.com\""))
 	}
 	return u
 }
@@ -499,9 +706,9 @@ func MustGetURL(key string) *url.URL {
 //   - net.IP: The value.
 //   - error: The error if the value is not present.
 func GetIP(key string) (net.IP, error) {
-	v, ok := Get(key)
-	if !ok {
-		return nil, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return nil, err
 	}
 	ip := net.ParseIP(strings.TrimSpace(v))
 	if ip == nil {
@@ -510,6 +717,43 @@ func GetIP(key string) (net.IP, error) {
 	return ip, nil
 }
 
+// GetCIDR returns the value as a network in CIDR notation, e.g.
+// "10.0.0.0/8".
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - *net.IPNet: The network.
+//   - error: The error if the value is not present or invalid.
+func GetCIDR(key string) (*net.IPNet, error) {
+	v, err := lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	_, n, err := net.ParseCIDR(strings.TrimSpace(v))
+	if err != nil {
+		return nil, typeErr(key, "cidr", v)
+	}
+	return n, nil
+}
+
+// MustGetCIDR returns the value as a network or panics if not present
+// or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - *net.IPNet: The network.
+func MustGetCIDR(key string) *net.IPNet {
+	n, err := GetCIDR(key)
+	if err != nil {
+		panic(expected(err, "CIDR, e.g. \"10.0.0.0/8\""))
+	}
+	return n
+}
+
 // MustGetIP returns the value as an IP or panics if not present.
 //
 // Parameters:
@@ -521,7 +765,7 @@ func GetIP(key string) (net.IP, error) {
 func MustGetIP(key string) net.IP {
 	ip, err := GetIP(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "IP address, e.g. \"10.0.0.1\""))
 	}
 	return ip
 }
@@ -549,7 +793,7 @@ func GetStringSlice(key string) ([]string, error) {
 func MustGetStringSlice(key string) []string {
 	v, err := GetStringSlice(key)
 	if err != nil {
-		panic(err)
+		panic(expected(err, "comma-separated list, e.g. \"a,b\""))
 	}
 	return v
 }
@@ -564,9 +808,9 @@ func MustGetStringSlice(key string) []string {
 //   - []string: The value.
 //   - error: The error if the value is not present.
 func GetStringSliceSep(key, sep string) ([]string, error) {
-	v, ok := Get(key)
-	if !ok {
-		return nil, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return nil, err
 	}
 	s := strings.TrimSpace(v)
 	if s == "" {
@@ -576,6 +820,238 @@ func GetStringSliceSep(key, sep string) ([]string, error) {
 	return parts, nil
 }
 
+// StringSet is a deduplicated set of strings.
+type StringSet struct {
+	// Members holds each distinct item.
+	Members map[string]struct{}
+	// Sorted lists the distinct items in sorted order.
+	Sorted []string
+}
+
+// Has reports whether v is in the set.
+//
+// Parameters:
+//   - v: The item.
+//
+// Returns:
+//   - bool: True if v is a member.
+func (s StringSet) Has(v string) bool {
+	_, ok := s.Members[v]
+	return ok
+}
+
+// GetStringSet returns a comma-separated value as a deduplicated set.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - StringSet: The value.
+//   - error: The error if the value is not present.
+func GetStringSet(key string) (StringSet, error) {
+	return getStringSet(key, false)
+}
+
+// GetStringSetStrict is like GetStringSet but returns an error when an
+// item repeats, which in allowlists usually indicates a copy-paste
+// mistake.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - StringSet: The value.
+//   - error: The error if the value is not present or has duplicates.
+func GetStringSetStrict(key string) (StringSet, error) {
+	return getStringSet(key, true)
+}
+
+// getStringSet builds a set from the items of key.
+func getStringSet(key string, strict bool) (StringSet, error) {
+	items, err := GetStringSlice(key)
+	if err != nil {
+		return StringSet{}, err
+	}
+	set := StringSet{Members: make(map[string]struct{}, len(items))}
+	for _, it := range items {
+		if _, dup := set.Members[it]; dup {
+			if strict {
+				return StringSet{}, &KeyError{Key: key, Kind: ErrType,
+					Msg: fmt.Sprintf("duplicate entry %q", it)}
+			}
+			continue
+		}
+		set.Members[it] = struct{}{}
+		set.Sorted = append(set.Sorted, it)
+	}
+	sort.Strings(set.Sorted)
+	return set, nil
+}
+
+// GetStringMap returns a "k1=v1,k2=v2" value as a map. Keys and
+// values are trimmed, empty pairs are skipped, and later duplicates
+// win.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - map[string]string: The value.
+//   - error: The error if the value is not present or malformed.
+func GetStringMap(key string) (map[string]string, error) {
+	return GetStringMapSep(key, ",", "=")
+}
+
+// GetStringMapSep is like GetStringMap with custom pair and key-value
+// separators, e.g. ";" and ":" for "a:1;b:2".
+//
+// Parameters:
+//   - key: The key to get.
+//   - pairSep: The separator between pairs.
+//   - kvSep: The separator between a key and its value.
+//
+// Returns:
+//   - map[string]string: The value.
+//   - error: The error if the value is not present or malformed.
+func GetStringMapSep(key, pairSep, kvSep string) (map[string]string, error) {
+	v, err := lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseStringMap(v, pairSep, kvSep)
+	if err != nil {
+		return nil, &KeyError{Key: key, Kind: ErrType, Msg: err.Error()}
+	}
+	return m, nil
+}
+
+// MustGetStringMap returns the value as a map or panics if not present
+// or malformed.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - map[string]string: The value.
+func MustGetStringMap(key string) map[string]string {
+	m, err := GetStringMap(key)
+	if err != nil {
+		panic(expected(err, "comma-separated pairs, e.g. \"a=1,b=2\""))
+	}
+	return m
+}
+
+// ParseStringMap parses "k1=v1,k2=v2" style pairs.
+//
+// Parameters:
+//   - s: The value to parse.
+//   - pairSep: The separator between pairs.
+//   - kvSep: The separator between a key and its value.
+//
+// Returns:
+//   - map[string]string: The pairs.
+//   - error: The error if a pair has no separator or an empty key.
+func ParseStringMap(s, pairSep, kvSep string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, p := range SplitAndTrim(s, pairSep) {
+		k, v, ok := strings.Cut(p, kvSep)
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid pair %q", p)
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+// GetStringSliceRe returns the value split on matches of the regular
+// expression pattern, e.g. `[,;\s]+`. Items are trimmed and empty items
+// dropped.
+//
+// Parameters:
+//   - key: The key to get.
+//   - pattern: The separator pattern.
+//
+// Returns:
+//   - []string: The value.
+//   - error: The error if the pattern is invalid or the value is not
+//     present.
+func GetStringSliceRe(key, pattern string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("envvar: invalid separator pattern: %w", err)
+	}
+	v, err := lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	raw := re.Split(strings.TrimSpace(v), -1)
+	out := make([]string, 0, len(raw))
+	for _, p := range raw {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// GetJSON returns the value unmarshaled as JSON into T.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - T: The value.
+//   - error: The error if the value is not present or not valid JSON
+//     for T.
+func GetJSON[T any](key string) (T, error) {
+	var out T
+	v, err := lookup(key)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal([]byte(v), &out); err != nil {
+		var zero T
+		return zero, &KeyError{Key: key, Kind: ErrType,
+			Msg: "invalid JSON: " + err.Error()}
+	}
+	return out, nil
+}
+
+// GetJSONOr returns the value unmarshaled as JSON into T or a default
+// if not present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//
+// Returns:
+//   - T: The value or the default.
+func GetJSONOr[T any](key string, def T) T {
+	v, err := GetJSON[T](key)
+	if err != nil {
+		fallback(err)
+		return defaulted(key, def)
+	}
+	return v
+}
+
+// MustGetJSON returns the value unmarshaled as JSON into T or panics if
+// not present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - T: The value.
+func MustGetJSON[T any](key string) T {
+	v, err := GetJSON[T](key)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
 // Generic typed getter using a converter.
 //
 // Parameters:
@@ -587,13 +1063,34 @@ func GetStringSliceSep(key, sep string) ([]string, error) {
 //   - error: The error if the value is not present.
 func GetTyped[T any](key string, conv func(string) (T, error)) (T, error) {
 	var zero T
-	v, ok := Get(key)
-	if !ok {
-		return zero, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return zero, err
 	}
 	return conv(strings.TrimSpace(v))
 }
 
+// GetTypedOr returns the value converted by conv, or def if the key is
+// not present or conv fails. Like the other Or getters, a conversion
+// failure panics under ProfileProd and is reported through ErrorHook
+// under ProfileStaging.
+//
+// Parameters:
+//   - key: The key to get.
+//   - def: The default value.
+//   - conv: The converter function.
+//
+// Returns:
+//   - T: The value or the default.
+func GetTypedOr[T any](key string, def T, conv func(string) (T, error)) T {
+	v, err := GetTyped(key, conv)
+	if err != nil {
+		fallback(err)
+		return defaulted(key, def)
+	}
+	return v
+}
+
 // MustGetTyped returns the value as a typed value or panics if not present.
 //
 // Parameters:
@@ -611,8 +1108,12 @@ func MustGetTyped[T any](key string, conv func(string) (T, error)) T {
 	return v
 }
 
-// GetRaw returns a value with expansion applied. Expansion supports
-// "${NAME}" and "${NAME:-default}" using current process env.
+// GetRaw returns a value from the default source with expansion
+// applied. "${NAME}" and "${NAME:-default}" references are looked up
+// in the default source, falling back to the process env for keys it
+// does not have. References ("ref+...") are resolved and encrypted
+// values ("enc:...") are decrypted; a value that fails to resolve is
+// reported as absent.
 //
 // Parameters:
 //   - key: The key to get.
@@ -621,17 +1122,122 @@ func MustGetTyped[T any](key string, conv func(string) (T, error)) T {
 //   - string: The value.
 //   - bool: The boolean indicating presence.
 func GetRaw(key string) (string, bool) {
-	start := time.Now()
-	v, ok := os.LookupEnv(key)
-	var err error
-	if ok {
-		v = expand(v)
+	v, ok, err := getRaw(key)
+	if err != nil {
+		return "", false
 	}
-	types.CallOnGet(key, ok, err, time.Since(start))
 	return v, ok
 }
 
-// ParseBoolValue parses a boolean value.
+// GetCtx is like Get but passes ctx to sources implementing
+// sources.ContextSource and to reference resolvers implementing
+// refs.ContextResolver, so lookups against remote sources honor
+// deadlines and cancellation.
+//
+// Parameters:
+//   - ctx: The context.
+//   - key: The key to get.
+//
+// Returns:
+//   - string: The value.
+//   - bool: The boolean indicating presence.
+//   - error: The error if ctx is done (matching ctx.Err() with
+//     errors.Is) or the value fails to resolve.
+func GetCtx(ctx context.Context, key string) (string, bool, error) {
+	return getRawContext(ctx, key)
+}
+
+// getRaw looks up, expands, and decrypts key, reporting to the hook.
+func getRaw(key string) (string, bool, error) {
+	return getRawContext(context.Background(), key)
+}
+
+// getRawContext is getRaw honoring ctx.
+func getRawContext(ctx context.Context, key string) (string, bool, error) {
+	var start time.Time
+	timed := types.Observed()
+	if timed {
+		start = types.Now()
+	}
+	v, ok, err := sources.LookupContext(ctx, key)
+	if err != nil {
+		err = fmt.Errorf("envvar: %s: %w", key, err)
+	} else if ok {
+		if v, err = expand(key, v); err == nil {
+			v, err = resolveContext(ctx, key, v)
+		}
+	}
+	if err == nil && ok {
+		if msg := types.CurrentLimits().CheckValue(v); msg != "" {
+			v, err = "", &KeyError{Key: key, Kind: ErrLimit, Msg: msg}
+		}
+	}
+	if err == nil && ok {
+		if err = types.CheckPin(key, v, true); err != nil {
+			v = ""
+		}
+	}
+	var dur time.Duration
+	if timed {
+		dur = types.Since(start)
+	}
+	types.CallOnGet(key, ok, err, dur)
+	return v, ok, err
+}
+
+// VerifyPins checks every variable pinned with types.Pin, including
+// unset ones, for startup. Mismatches are reported to ErrorHook and,
+// under types.PinFail, returned.
+//
+// Returns:
+//   - error: The joined *types.PinError values under PinFail, or nil.
+func VerifyPins() error {
+	var errs []error
+	for _, k := range types.PinnedKeys() {
+		_, ok, err := getRaw(k)
+		if !ok {
+			err = types.CheckPin(k, "", false)
+		}
+		var pe *types.PinError
+		if errors.As(err, &pe) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveContext resolves references and then decrypts encrypted
+// values. A reference abandoned because ctx is done fails with an error
+// wrapping ctx.Err().
+func resolveContext(ctx context.Context, key, v string) (string, error) {
+	v, err := refs.ValueContext(ctx, v)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("envvar: %s: %w", key, ctx.Err())
+		}
+		return "", &KeyError{Key: key, Kind: ErrRef, Msg: err.Error()}
+	}
+	v, err = decrypt.Value(v)
+	if err != nil {
+		return "", &KeyError{Key: key, Kind: ErrDecrypt, Msg: err.Error()}
+	}
+	return v, nil
+}
+
+// lookup returns the value or a missing or decrypt error.
+func lookup(key string) (string, error) {
+	v, ok, err := getRaw(key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", missingErr(key)
+	}
+	return v, nil
+}
+
+// ParseBoolValue parses a boolean value. Under the prod profile only
+// true, false, 1, and 0 are accepted.
 //
 // Parameters:
 //   - v: The value to parse.
@@ -640,15 +1246,11 @@ func GetRaw(key string) (string, bool) {
 //   - bool: The boolean value.
 //   - error: The error if the parsing fails.
 func ParseBoolValue(v string) (bool, error) {
-	s := strings.TrimSpace(strings.ToLower(v))
-	switch s {
-	case "1", "t", "true", "y", "yes", "on":
-		return true, nil
-	case "0", "f", "false", "n", "no", "off":
-		return false, nil
-	default:
+	b, ok := types.ParseBool(v)
+	if !ok {
 		return false, errors.New("invalid boolean: " + v)
 	}
+	return b, nil
 }
 
 // SplitAndTrim splits a string into a slice of strings and trims each string.
@@ -676,61 +1278,78 @@ func missingErr(key string) error {
 	return &KeyError{Key: key, Kind: ErrMissing}
 }
 
-// typeErr returns a type error.
+// expected adds the expected format to a missing error so Must*
+// panics tell operators what to set. Other errors are returned as is.
+func expected(err error, format string) error {
+	var ke *KeyError
+	if errors.As(err, &ke) && ke.Kind == ErrMissing && ke.Msg == "" {
+		ke.Msg = "expected " + format
+	}
+	return err
+}
+
+// typeErr returns a type error and reports it to ValidationHook with
+// the rule "type".
 func typeErr(key, want, got string) error {
-	return &KeyError{
-		Key:  key,
-		Kind: ErrType,
-		Msg:  "want " + want + ", got " + got,
+	msg := "want " + want + ", got " + redact.Value(key, got)
+	types.CallOnValidationError(key, "type", msg)
+	return &KeyError{Key: key, Kind: ErrType, Msg: msg}
+}
+
+// fallback handles an Or getter falling back to its default after err.
+// Missing keys fall back silently; for unparsable values the profile
+// decides: ProfileProd panics, ProfileStaging reports err through
+// ErrorHook, and ProfileDev ignores it.
+func fallback(err error) {
+	var ke *KeyError
+	if errors.As(err, &ke) && ke.Kind == ErrMissing {
+		return
+	}
+	switch types.CurrentProfile() {
+	case types.ProfileProd:
+		panic(err)
+	case types.ProfileStaging:
+		types.CallOnError("get", err)
 	}
 }
 
+// defaulted reports def to DefaultHook as used for key and returns it.
+func defaulted[T any](key string, def T) T {
+	types.CallOnDefaultUsed(key, redact.Value(key, fmt.Sprint(def)))
+	return def
+}
+
 // parseBool parses a boolean value.
 func parseBool(key string) (bool, error) {
-	v, ok := Get(key)
-	if !ok {
-		return false, missingErr(key)
+	v, err := lookup(key)
+	if err != nil {
+		return false, err
 	}
 	return ParseBoolValue(v)
 }
 
-// expand applies ${NAME} and ${NAME:-def} using process env first.
-func expand(s string) string {
-	// First handle ${NAME} and ${NAME:-def} ourselves to preserve defaults,
-	// then allow $NAME and ${NAME} leftovers via os.ExpandEnv.
-	s = expandWithLookup(s, os.LookupEnv)
-	return os.ExpandEnv(s)
-}
-
-// expandWithLookup is a generic expander that resolves ${NAME:-def}
-// with a provided lookup function.
-func expandWithLookup(s string, look func(string) (string, bool)) string {
-	// Handle ${NAME:-default} segments. Keep this non-nesting for
-	// clarity and performance.
-	for {
-		i := strings.Index(s, "${")
-		if i < 0 {
-			break
-		}
-		j := strings.Index(s[i:], "}")
-		if j < 0 {
-			break
-		}
-		j += i
-		inner := s[i+2 : j]
-		name, def, hasDef := strings.Cut(inner, ":-")
-		if name == "" {
-			s = s[:i] + s[j+1:]
-			continue
-		}
-		if v, ok := look(name); ok {
-			s = s[:i] + v + s[j+1:]
-		} else if hasDef {
-			s = s[:i] + def + s[j+1:]
-		} else {
-			// Missing and no default -> drop to empty.
-			s = s[:i] + "" + s[j+1:]
-		}
+// expand applies the ${...} references in s, looking names up in the
+// default source and then the process env. A failed ${NAME:?message}
+// becomes a missing error for NAME; other expansion failures, such as
+// cycles, become reference errors for key.
+//
+// Parameters:
+//   - key: The key whose value s is.
+//   - s: The value to expand.
+//
+// Returns:
+//   - string: The expanded value.
+//   - error: The *KeyError of a failed expansion.
+func expand(key, s string) (string, error) {
+	out, err := expandpkg.ExpandWith(s, sources.LookupOrEnv)
+	var ue *expandpkg.UnsetError
+	if errors.As(err, &ue) {
+		return "", &KeyError{Key: ue.Name, Kind: ErrMissing,
+			Msg: ue.Msg + " (referenced by " + key + ")"}
+	}
+	if err != nil {
+		return "", &KeyError{Key: key, Kind: ErrRef,
+			Msg: strings.TrimPrefix(err.Error(), "envvar: ")}
 	}
-	return s
+	return out, nil
 }
@@ -0,0 +1,209 @@
+package getters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// MaxRangeValues caps how many integers GetIntRangeValues expands a
+// value to, so a typo such as "0-4000000000" fails instead of
+// exhausting memory.
+const MaxRangeValues = 1 << 16
+
+// GetDurationList returns a comma-separated list of durations, such as
+// a "1s,5s,30s" backoff schedule. Every item must parse and be
+// non-negative.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []time.Duration: The durations in order.
+//   - error: The error if the value is not present or an item is
+//     invalid.
+func GetDurationList(key string) ([]time.Duration, error) {
+	items, err := GetStringSlice(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]time.Duration, 0, len(items))
+	for i, it := range items {
+		d, err := time.ParseDuration(it)
+		if err != nil || d < 0 {
+			return nil, itemErr(key, i, "non-negative duration", it)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// MustGetDurationList returns the durations or panics if the value is
+// not present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []time.Duration: The durations in order.
+func MustGetDurationList(key string) []time.Duration {
+	v, err := GetDurationList(key)
+	if err != nil {
+		panic(expected(err, "comma-separated durations, e.g. \"1s,5s,30s\""))
+	}
+	return v
+}
+
+// IntRange is an inclusive range of non-negative integers.
+type IntRange struct {
+	Lo, Hi int
+}
+
+// Len returns the number of integers in the range.
+//
+// Returns:
+//   - int: Hi - Lo + 1.
+func (r IntRange) Len() int {
+	return r.Hi - r.Lo + 1
+}
+
+// Contains reports whether n is in the range.
+//
+// Parameters:
+//   - n: The integer.
+//
+// Returns:
+//   - bool: True if Lo <= n <= Hi.
+func (r IntRange) Contains(n int) bool {
+	return n >= r.Lo && n <= r.Hi
+}
+
+// String renders the range as "lo-hi", or "lo" for a single integer.
+//
+// Returns:
+//   - string: The range.
+func (r IntRange) String() string {
+	if r.Lo == r.Hi {
+		return strconv.Itoa(r.Lo)
+	}
+	return strconv.Itoa(r.Lo) + "-" + strconv.Itoa(r.Hi)
+}
+
+// ParseIntRange parses "lo-hi" or a single "n" into an IntRange. Both
+// bounds must be non-negative and lo must not exceed hi.
+//
+// Parameters:
+//   - s: The range.
+//
+// Returns:
+//   - IntRange: The range.
+//   - error: The error if s is malformed.
+func ParseIntRange(s string) (IntRange, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	if !isRange {
+		hi = lo
+	}
+	l, err1 := strconv.Atoi(strings.TrimSpace(lo))
+	h, err2 := strconv.Atoi(strings.TrimSpace(hi))
+	if err1 != nil || err2 != nil || l < 0 || h < 0 {
+		return IntRange{}, fmt.Errorf("invalid range: %s", s)
+	}
+	if l > h {
+		return IntRange{}, fmt.Errorf("invalid range: %s: start exceeds end", s)
+	}
+	return IntRange{Lo: l, Hi: h}, nil
+}
+
+// GetIntRangeList returns a comma-separated list of integer ranges,
+// such as "8000-8010,9000", as (lo, hi) pairs.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []IntRange: The ranges in order.
+//   - error: The error if the value is not present or an item is
+//     invalid.
+func GetIntRangeList(key string) ([]IntRange, error) {
+	items, err := GetStringSlice(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]IntRange, 0, len(items))
+	for i, it := range items {
+		r, err := ParseIntRange(it)
+		if err != nil {
+			return nil, itemErr(key, i, "range \"lo-hi\" with lo <= hi", it)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// GetIntRangeValues is like GetIntRangeList but expands the ranges to
+// the integers they contain, in order. Values expanding to more than
+// MaxRangeValues integers are rejected.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []int: The integers.
+//   - error: The error if the value is not present, invalid, or too
+//     large.
+func GetIntRangeValues(key string) ([]int, error) {
+	ranges, err := GetIntRangeList(key)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, r := range ranges {
+		// Compare Hi-Lo, which cannot overflow for non-negative
+		// bounds, before adding: Len overflows for "0-<MaxInt>".
+		if r.Hi-r.Lo >= MaxRangeValues-n {
+			return nil, &KeyError{Key: key, Kind: ErrLimit,
+				Msg: fmt.Sprintf("ranges expand to more than %d values", MaxRangeValues)}
+		}
+		n += r.Len()
+	}
+	out := make([]int, 0, n)
+	for _, r := range ranges {
+		// Stop on Hi rather than testing i <= Hi, which never fails
+		// for a range ending at math.MaxInt.
+		for i := r.Lo; ; i++ {
+			out = append(out, i)
+			if i == r.Hi {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// MustGetIntRangeList returns the ranges or panics if the value is not
+// present or invalid.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - []IntRange: The ranges in order.
+func MustGetIntRangeList(key string) []IntRange {
+	v, err := GetIntRangeList(key)
+	if err != nil {
+		panic(expected(err, "comma-separated ranges, e.g. \"8000-8010,9000\""))
+	}
+	return v
+}
+
+// itemErr returns a type error for item i of a list value, reported
+// like typeErr.
+func itemErr(key string, i int, want, got string) error {
+	msg := fmt.Sprintf("item %d: want %s, got %s", i+1, want, redact.Value(key, got))
+	types.CallOnValidationError(key, "type", msg)
+	return &KeyError{Key: key, Kind: ErrType, Msg: msg}
+}
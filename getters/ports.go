@@ -0,0 +1,64 @@
+package getters
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+)
+
+// GetPortRange returns a "min-max" port range, such as "8000-8010". A
+// single port is a range of one. Both ends must be valid TCP ports
+// (1-65535) and min must not exceed max.
+//
+// Parameters:
+//   - key: The key to get.
+//
+// Returns:
+//   - int: The first port.
+//   - int: The last port.
+//   - error: The error if the value is not present or invalid.
+func GetPortRange(key string) (int, int, error) {
+	v, err := lookup(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	r, err := ParseIntRange(v)
+	if err != nil || r.Lo < 1 || r.Hi > 65535 {
+		return 0, 0, typeErr(key, "port range \"min-max\" within 1-65535", v)
+	}
+	return r.Lo, r.Hi, nil
+}
+
+// PickFreePort listens on the first free TCP port in the range held by
+// rangeKey, starting at a random offset so parallel test harnesses
+// rarely contend for the same port. Returning the listener rather than
+// a port number keeps another process from taking the port before the
+// caller binds it.
+//
+// Parameters:
+//   - rangeKey: The key holding the range, see GetPortRange.
+//
+// Returns:
+//   - net.Listener: The listener on all interfaces; its port is
+//     l.Addr().(*net.TCPAddr).Port.
+//   - error: The error if the range is invalid or no port in it could
+//     be bound; it wraps the last listen error.
+func PickFreePort(rangeKey string) (net.Listener, error) {
+	lo, hi, err := GetPortRange(rangeKey)
+	if err != nil {
+		return nil, err
+	}
+	n := hi - lo + 1
+	off := rand.IntN(n)
+	var last error
+	for i := 0; i < n; i++ {
+		port := lo + (off+i)%n
+		l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+		if err == nil {
+			return l, nil
+		}
+		last = err
+	}
+	return nil, fmt.Errorf("envvar: %s: no free port in %d-%d: %w", rangeKey, lo, hi, last)
+}
@@ -0,0 +1,104 @@
+package getters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Platforms reported by RuntimeInfo.Platform.
+const (
+	RuntimeKubernetes = "kubernetes"
+	RuntimeECS        = "ecs"
+	RuntimeCloudRun   = "cloud-run"
+	RuntimeLambda     = "lambda"
+)
+
+// serviceAccountDir is where Kubernetes mounts the service account.
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// RuntimeInfo describes the container platform the process runs on,
+// normalized for logging enrichment. Fields a platform does not expose
+// through its environment are empty.
+type RuntimeInfo struct {
+	// Platform is one of the Runtime* constants, or "" when none is
+	// detected.
+	Platform string
+	// Instance identifies this replica: the pod name, ECS container,
+	// Lambda log stream, or Cloud Run hostname.
+	Instance string
+	// Region is the cloud region, when exposed.
+	Region string
+	// Service is the deployed unit: the Cloud Run service or job, the
+	// Lambda function, or the ECS family.
+	Service string
+	// Version is the Cloud Run revision or Lambda version.
+	Version string
+	// Namespace is the Kubernetes namespace.
+	Namespace string
+	// Node is the Kubernetes node, from the NODE_NAME downward API
+	// convention.
+	Node string
+}
+
+// Runtime detects Kubernetes, ECS, Cloud Run, and Lambda from their
+// well-known variables, read through the default source. On Kubernetes
+// the pod name and namespace come from the POD_NAME and POD_NAMESPACE
+// downward API conventions, falling back to HOSTNAME and the mounted
+// service account.
+//
+// Returns:
+//   - RuntimeInfo: The platform; Platform is "" when none is detected.
+func Runtime() RuntimeInfo {
+	region := firstVar("AWS_REGION", "AWS_DEFAULT_REGION")
+	switch {
+	case ciVar("AWS_LAMBDA_FUNCTION_NAME") != "":
+		return RuntimeInfo{
+			Platform: RuntimeLambda,
+			Instance: ciVar("AWS_LAMBDA_LOG_STREAM_NAME"),
+			Region:   region,
+			Service:  ciVar("AWS_LAMBDA_FUNCTION_NAME"),
+			Version:  ciVar("AWS_LAMBDA_FUNCTION_VERSION"),
+		}
+	case firstVar("ECS_CONTAINER_METADATA_URI_V4", "ECS_CONTAINER_METADATA_URI") != "" ||
+		strings.HasPrefix(ciVar("AWS_EXECUTION_ENV"), "AWS_ECS"):
+		uri := firstVar("ECS_CONTAINER_METADATA_URI_V4", "ECS_CONTAINER_METADATA_URI")
+		return RuntimeInfo{
+			Platform: RuntimeECS,
+			Instance: uri[strings.LastIndex(uri, "/")+1:],
+			Region:   region,
+			Service:  ciVar("ECS_TASK_FAMILY"),
+		}
+	case ciVar("KUBERNETES_SERVICE_HOST") != "":
+		ns := ciVar("POD_NAMESPACE")
+		if ns == "" {
+			b, _ := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+			ns = strings.TrimSpace(string(b))
+		}
+		return RuntimeInfo{
+			Platform:  RuntimeKubernetes,
+			Instance:  firstVar("POD_NAME", "HOSTNAME"),
+			Region:    firstVar("REGION", "AWS_REGION", "AWS_DEFAULT_REGION"),
+			Namespace: ns,
+			Node:      ciVar("NODE_NAME"),
+		}
+	case ciVar("K_SERVICE") != "" || ciVar("CLOUD_RUN_JOB") != "":
+		return RuntimeInfo{
+			Platform: RuntimeCloudRun,
+			Instance: ciVar("HOSTNAME"),
+			Service:  firstVar("K_SERVICE", "CLOUD_RUN_JOB"),
+			Version:  firstVar("K_REVISION", "CLOUD_RUN_EXECUTION"),
+		}
+	}
+	return RuntimeInfo{}
+}
+
+// firstVar returns the first non-empty trimmed value of keys.
+func firstVar(keys ...string) string {
+	for _, k := range keys {
+		if v := ciVar(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
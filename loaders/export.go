@@ -0,0 +1,112 @@
+package loaders
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/redact"
+)
+
+// SystemdEnvironment renders m as Environment= lines for a systemd unit
+// [Service] section, keys sorted. Secret keys (see redact.IsSecretKey,
+// including fields tagged `secret:"true"` after binders.EnvMap) are not
+// inlined: they are referenced through an EnvironmentFile= line for
+// secretsFile, which must provide them, or listed as omitted in a
+// comment when secretsFile is empty.
+//
+// Parameters:
+//   - m: The variables.
+//   - secretsFile: The env file holding the secrets, or "".
+//
+// Returns:
+//   - []byte: The unit file lines.
+func SystemdEnvironment(m map[string]string, secretsFile string) []byte {
+	plain, secret := splitSecrets(m)
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "%", "%%")
+	var b bytes.Buffer
+	for _, k := range plain {
+		b.WriteString(`Environment="`)
+		b.WriteString(r.Replace(k + "=" + m[k]))
+		b.WriteString("\"\n")
+	}
+	if len(secret) > 0 {
+		if secretsFile != "" {
+			b.WriteString("# Secrets: " + strings.Join(secret, ", ") + "\n")
+			b.WriteString("EnvironmentFile=" + r.Replace(secretsFile) + "\n")
+		} else {
+			b.WriteString("# Omitted secrets: " + strings.Join(secret, ", ") + "\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// DockerFlags renders m as shell-quoted docker run flags, keys sorted:
+// -e 'KEY=VALUE' for plain values and a bare -e KEY for secrets, which
+// docker then copies from the environment of the invoking shell instead
+// of exposing them on the command line.
+//
+// Parameters:
+//   - m: The variables.
+//
+// Returns:
+//   - string: The flags, e.g. "-e 'PORT=8080' -e DB_PASSWORD".
+func DockerFlags(m map[string]string) string {
+	plain, secret := splitSecrets(m)
+	flags := make([]string, 0, len(m))
+	for _, k := range plain {
+		flags = append(flags, "-e "+shellQuote(k+"="+m[k]))
+	}
+	for _, k := range secret {
+		flags = append(flags, "-e "+k)
+	}
+	return strings.Join(flags, " ")
+}
+
+// DockerEnvFile renders m for docker run --env-file, keys sorted.
+// Docker reads values literally, so no quoting is applied. Secrets, and
+// values containing newlines that the format cannot hold, are written
+// as a bare KEY line, which docker fills from the invoking environment.
+//
+// Parameters:
+//   - m: The variables.
+//
+// Returns:
+//   - []byte: The env file content.
+func DockerEnvFile(m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for _, k := range keys {
+		v := m[k]
+		b.WriteString(k)
+		if !redact.IsSecretKey(k) && !strings.ContainsAny(v, "\r\n") {
+			b.WriteByte('=')
+			b.WriteString(v)
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// splitSecrets returns the plain and secret keys of m, each sorted.
+func splitSecrets(m map[string]string) (plain, secret []string) {
+	for k := range m {
+		if redact.IsSecretKey(k) {
+			secret = append(secret, k)
+		} else {
+			plain = append(plain, k)
+		}
+	}
+	sort.Strings(plain)
+	sort.Strings(secret)
+	return plain, secret
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
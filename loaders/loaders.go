@@ -1,18 +1,26 @@
 package loaders
 
 import (
-	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/aatuh/envvar/v2/sources"
 	"github.com/aatuh/envvar/v2/types"
 )
 
 // SetEnvVars sets the provided map into process env. Values overwrite
-// existing ones. Keys with empty value are set to "".
+// existing ones. Keys with empty value are set to "". The keys are
+// dropped from caches in the default source, such as the one
+// installed by Warmup.
 //
 // Parameters:
 //   - m: The map to set.
@@ -20,90 +28,490 @@ import (
 // Returns:
 //   - error: The error if the setting fails.
 func SetEnvVars(m map[string]string) error {
+	keys := make([]string, 0, len(m))
+	defer func() { invalidate(keys) }()
 	for k, v := range m {
 		if err := os.Setenv(k, v); err != nil {
 			return err
 		}
+		keys = append(keys, k)
 	}
 	return nil
 }
 
+// setEnv sets m into process env according to o.
+func setEnv(m map[string]string, o options) error {
+	if !o.defaultsOnly {
+		return SetEnvVars(m)
+	}
+	var keys []string
+	defer func() { invalidate(keys) }()
+	for k, v := range m {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+	return nil
+}
+
+// invalidate drops keys from the caches in the default source. An
+// empty keys changes nothing.
+func invalidate(keys []string) {
+	if len(keys) > 0 {
+		sources.InvalidateCaches(sources.Default(), keys...)
+	}
+}
+
 var (
 	loadOnceGuard sync.Once
 	loadErr       error
-
-	// defaultPaths are tried when caller passes nil.
-	defaultPaths = []string{".env", "/env/.env"}
 )
 
-// LoadOnce loads the environment variables from the given paths.
+// Option configures file loading.
+type Option func(*options)
+
+// options holds the loading configuration.
+type options struct {
+	verifier     Verifier
+	defaultsOnly bool
+	// fsys is the filesystem files are read from; nil means the OS.
+	fsys fs.FS
+}
+
+// readRaw reads p from the configured filesystem.
+func (o options) readRaw(p string) ([]byte, error) {
+	if o.fsys != nil {
+		return fs.ReadFile(o.fsys, p)
+	}
+	return os.ReadFile(p)
+}
+
+// stat stats p on the configured filesystem.
+func (o options) stat(p string) (fs.FileInfo, error) {
+	if o.fsys != nil {
+		return fs.Stat(o.fsys, p)
+	}
+	return os.Stat(p)
+}
+
+// canonical returns the identity of p used for loop detection.
+func (o options) canonical(p string) (string, error) {
+	if o.fsys != nil {
+		return path.Clean(p), nil
+	}
+	return filepath.Abs(p)
+}
+
+// resolve resolves ref relative to the directory of from.
+func (o options) resolve(from, ref string) string {
+	if o.fsys != nil {
+		if path.IsAbs(ref) {
+			return path.Clean(ref[1:])
+		}
+		return path.Join(path.Dir(from), ref)
+	}
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(filepath.Dir(from), ref)
+}
+
+// WithVerifier verifies each file with v before parsing it and refuses
+// to load files that fail verification.
+//
+// Parameters:
+//   - v: The verifier, e.g. SHA256Sidecar().
+//
+// Returns:
+//   - Option: The option.
+func WithVerifier(v Verifier) Option {
+	return func(o *options) { o.verifier = v }
+}
+
+// WithFS reads files from fsys instead of the OS, e.g. an embed.FS or
+// a fstest.MapFS in tests. Paths use fs.FS syntax: slash-separated and
+// unrooted. It applies to every loader, including Watch.
+//
+// Parameters:
+//   - fsys: The filesystem.
+//
+// Returns:
+//   - Option: The option.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) { o.fsys = fsys }
+}
+
+// WithDefaultsOnly sets only variables that are not already present in
+// the process env, so the file provides defaults and the real
+// environment wins.
+//
+// Returns:
+//   - Option: The option.
+func WithDefaultsOnly() Option {
+	return func(o *options) { o.defaultsOnly = true }
+}
+
+// buildOptions applies opts.
+func buildOptions(opts []Option) options {
+	var o options
+	for _, fn := range opts {
+		fn(&o)
+	}
+	return o
+}
+
+// LoadOnce loads the first existing file of paths, or of DefaultPaths
+// when paths is empty. Finding none is not an error unless the profile
+// is ProfileProd.
 //
 // Parameters:
 //   - paths: The paths to load.
+//   - opts: The loading options.
 //
 // Returns:
 //   - error: The error if the loading fails.
-func LoadOnce(paths []string) error {
+func LoadOnce(paths []string, opts ...Option) error {
 	loadOnceGuard.Do(func() {
 		if len(paths) == 0 {
-			paths = defaultPaths
+			paths = DefaultPaths()
 		}
+		o := buildOptions(opts)
 		for _, p := range paths {
-			info, err := os.Stat(p)
+			info, err := o.stat(p)
 			if err != nil || info.IsDir() {
 				continue
 			}
-			m, err := ReadFile(p)
-			if err != nil {
-				loadErr = err
-				return
-			}
-			_ = SetEnvVars(m)
-			types.CallOnLoad(p, len(m))
+			loadErr = Load(p, opts...)
 			return
 		}
-		// Not an error if none exist.
+		// Not an error if none exist, except under the prod profile.
+		if types.CurrentProfile().RequireFiles() {
+			loadErr = fmt.Errorf("envvar: no env file found in %s: %w",
+				strings.Join(paths, ", "), fs.ErrNotExist)
+		}
 	})
 	return loadErr
 }
 
+// ResetLoadOnce makes the next LoadOnce call load again. It must not
+// run concurrently with LoadOnce. Meant for tests; see
+// envvartest.ResetGlobalState.
+func ResetLoadOnce() {
+	loadOnceGuard = sync.Once{}
+	loadErr = nil
+}
+
+// Load reads a single file and sets its variables into process env.
+//
+// Parameters:
+//   - path: The path to load.
+//   - opts: The loading options.
+//
+// Returns:
+//   - error: The error if reading, verification, or parsing fails.
+func Load(path string, opts ...Option) error {
+	o := buildOptions(opts)
+	m, err := readFile(path, o)
+	if err != nil {
+		return err
+	}
+	if err := setEnv(m, o); err != nil {
+		return err
+	}
+	types.CallOnLoad(path, len(m))
+	return nil
+}
+
+// ReadFrom parses env file content from r, e.g. a network stream.
+// Includes and parents resolve relative to the working directory.
+//
+// Parameters:
+//   - r: The reader.
+//
+// Returns:
+//   - map[string]string: The map of key-value pairs.
+//   - error: The error if reading or parsing fails.
+func ReadFrom(r io.Reader) (map[string]string, error) {
+	es, dirs, err := parseEntries(r, readerPath, options{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if es, err = withParent(readerPath, es, dirs, options{}, nil); err != nil {
+		return nil, err
+	}
+	return values(es), nil
+}
+
+// readerPath names content read by ReadFrom in errors and provenance.
+const readerPath = "<reader>"
+
+// LoadFS loads the files of fsys matching paths (plain paths or
+// fs.Glob patterns) as defaults layered under the real environment:
+// variables already set in the process env are kept. Matches are
+// merged in order, later files overriding earlier ones; includes and
+// parents resolve within fsys. Use it with go:embed to ship default
+// configuration in the binary.
+//
+// Parameters:
+//   - fsys: The filesystem, e.g. an embed.FS.
+//   - paths: The files or patterns, e.g. "defaults.env" or
+//     "config/*.env".
+//
+// Returns:
+//   - error: The error if a pattern is malformed, a plain path does
+//     not exist, or reading fails.
+func LoadFS(fsys fs.FS, paths ...string) error {
+	m, err := ReadFS(fsys, paths...)
+	if err != nil {
+		return err
+	}
+	if err := setEnv(m, options{defaultsOnly: true}); err != nil {
+		return err
+	}
+	types.CallOnLoad("fs:"+strings.Join(paths, ","), len(m))
+	return nil
+}
+
+// ReadFS is like LoadFS but returns the merged variables instead of
+// setting them.
+//
+// Parameters:
+//   - fsys: The filesystem.
+//   - paths: The files or patterns.
+//
+// Returns:
+//   - map[string]string: The merged key-value pairs.
+//   - error: The error if a pattern is malformed, a plain path does
+//     not exist, or reading fails.
+func ReadFS(fsys fs.FS, paths ...string) (map[string]string, error) {
+	o := options{fsys: fsys}
+	merged := map[string]string{}
+	seen := map[string]bool{}
+	for _, pat := range paths {
+		matches, err := fs.Glob(fsys, pat)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pat, `*?[\`) {
+			return nil, fmt.Errorf("envvar: %s: %w", pat, fs.ErrNotExist)
+		}
+		for _, p := range matches {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			es, err := readEntries(p, o, nil)
+			if err != nil {
+				return nil, err
+			}
+			for k, e := range es {
+				merged[k] = e.Value
+			}
+		}
+	}
+	return merged, nil
+}
+
+// MergePolicy decides which file wins when several define a key.
+type MergePolicy int
+
+const (
+	// LaterWins lets later files override earlier ones, e.g. .env
+	// followed by .env.local.
+	LaterWins MergePolicy = iota
+	// FirstWins keeps the value from the first file defining a key.
+	FirstWins
+)
+
+// LoadAll loads every existing file in paths, merges them with policy,
+// and sets the result into process env. Missing files are skipped.
+//
+// Parameters:
+//   - paths: The paths to load, in order.
+//   - policy: The merge policy.
+//   - opts: The loading options.
+//
+// Returns:
+//   - error: The error if reading, verification, or parsing fails.
+func LoadAll(paths []string, policy MergePolicy, opts ...Option) error {
+	m, err := ReadAll(paths, policy, opts...)
+	if err != nil {
+		return err
+	}
+	if err := setEnv(m, buildOptions(opts)); err != nil {
+		return err
+	}
+	types.CallOnLoad(strings.Join(paths, ","), len(m))
+	return nil
+}
+
+// ReadAll reads every existing file in paths and merges them with
+// policy. Missing files are skipped.
+//
+// Parameters:
+//   - paths: The paths to read, in order.
+//   - policy: The merge policy.
+//   - opts: The loading options.
+//
+// Returns:
+//   - map[string]string: The merged key-value pairs.
+//   - error: The error if reading, verification, or parsing fails.
+func ReadAll(paths []string, policy MergePolicy, opts ...Option) (map[string]string, error) {
+	o := buildOptions(opts)
+	merged := map[string]string{}
+	for _, p := range paths {
+		info, err := o.stat(p)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		m, err := readFile(p, o)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range m {
+			if _, ok := merged[k]; ok && policy == FirstWins {
+				continue
+			}
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
 // ReadFile reads the environment variables from the given path.
 //
 // Parameters:
 //   - path: The path to read.
+//   - opts: The loading options.
 //
 // Returns:
 //   - map[string]string: The map of key-value pairs.
 //   - error: The error if the reading fails.
-func ReadFile(path string) (map[string]string, error) {
-	f, err := os.Open(path)
+func ReadFile(path string, opts ...Option) (map[string]string, error) {
+	return readFile(path, buildOptions(opts))
+}
+
+// ExtendsKey is the meta-key naming a parent env file, equivalent to
+// a "#!extends <path>" directive. It is not set as a variable.
+const ExtendsKey = "ENVVAR_EXTENDS"
+
+// Entry is a variable read from an env file, with its provenance.
+type Entry struct {
+	// Value is the parsed value.
+	Value string
+	// Path is the file defining the variable, after includes and
+	// parents are resolved.
+	Path string
+	// Line is the 1-based line where the definition starts.
+	Line int
+}
+
+// ReadEntries is like ReadFile but also reports where each variable was
+// defined, following includes and parents.
+//
+// Parameters:
+//   - path: The path to read.
+//   - opts: The loading options.
+//
+// Returns:
+//   - map[string]Entry: The variables with their provenance.
+//   - error: The error if the reading fails.
+func ReadEntries(path string, opts ...Option) (map[string]Entry, error) {
+	return readEntries(path, buildOptions(opts), nil)
+}
+
+// readFile reads, verifies, and parses path, including the files it
+// includes and extends.
+func readFile(path string, o options) (map[string]string, error) {
+	es, err := readEntries(path, o, nil)
+	if err != nil {
+		return nil, err
+	}
+	return values(es), nil
+}
+
+// values drops the provenance of es.
+func values(es map[string]Entry) map[string]string {
+	m := make(map[string]string, len(es))
+	for k, e := range es {
+		m[k] = e.Value
+	}
+	return m
+}
+
+// readEntries reads path and the files it includes and extends. stack
+// holds the absolute paths of the files being read, for loop detection.
+func readEntries(path string, o options, stack []string) (map[string]Entry, error) {
+	abs, err := o.canonical(path)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	if slices.Contains(stack, abs) {
+		return nil, errors.New("envvar: env file loop: " +
+			strings.Join(append(stack, abs), " -> "))
+	}
+	stack = append(stack[:len(stack):len(stack)], abs)
+	data, err := o.readRaw(path)
+	if err != nil {
+		return nil, err
+	}
+	if o.verifier != nil {
+		if err := o.verifier.Verify(path, data); err != nil {
+			return nil, err
+		}
+	}
+	m, dirs, err := parseEntries(bytes.NewReader(data), path, o, stack)
+	if err != nil {
+		return nil, err
+	}
+	return withParent(path, m, dirs, o, stack)
+}
+
+// withParent merges the parent declared by path, if any, under m.
+func withParent(path string, m map[string]Entry, dirs []directive, o options, stack []string) (map[string]Entry, error) {
+	parent, err := extendsOf(path, m, dirs)
+	if err != nil || parent == "" {
+		return m, err
+	}
+	parent = o.resolve(path, parent)
+	base, err := readEntries(parent, o, stack)
+	if err != nil {
+		return nil, fmt.Errorf("envvar: %s extends %s: %w",
+			filepath.Base(path), parent, err)
+	}
+	for k, e := range m {
+		base[k] = e
+	}
+	return base, nil
+}
 
-	m := make(map[string]string)
-	sc := bufio.NewScanner(f)
-	ln := 0
-	for sc.Scan() {
-		ln++
-		line := strings.TrimSpace(sc.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
+// extendsOf returns the parent declared by path, removing ExtendsKey
+// from m. A file may declare at most one parent.
+func extendsOf(path string, m map[string]Entry, dirs []directive) (string, error) {
+	var parent string
+	for _, d := range dirs {
+		if d.name != "extends" {
 			continue
 		}
-		k, v, ok := strings.Cut(line, "=")
-		if !ok {
-			return nil, errors.New("envvar: invalid line " +
-				filepath.Base(path) + ":" + strconvI(ln))
+		if parent != "" || d.arg == "" {
+			return "", errors.New("envvar: invalid extends directive " +
+				filepath.Base(path) + ":" + strconvI(d.line))
 		}
-		k = strings.TrimSpace(k)
-		v = strings.TrimSpace(v)
-		m[k] = v
+		parent = d.arg
 	}
-	if err := sc.Err(); err != nil {
-		return nil, err
+	if e, ok := m[ExtendsKey]; ok {
+		delete(m, ExtendsKey)
+		if parent != "" {
+			return "", errors.New("envvar: " + filepath.Base(path) +
+				": both #!extends and " + ExtendsKey + " set")
+		}
+		parent = e.Value
 	}
-	return m, nil
+	return parent, nil
 }
 
 // strconvI converts an integer to a string.
@@ -1,11 +1,34 @@
 package loaders
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
 )
 
+// parse parses dotenv content without provenance.
+func parse(r io.Reader, path string) (map[string]string, []directive, error) {
+	es, dirs, err := parseEntries(r, path, options{}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return values(es), dirs, nil
+}
+
 func TestReadFile(t *testing.T) {
 	dir := t.TempDir()
 	p := filepath.Join(dir, ".env.test")
@@ -22,3 +45,537 @@ func TestReadFile(t *testing.T) {
 		t.Fatalf("map mismatch: %#v", m)
 	}
 }
+
+func TestVerifiedLoad(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, ".env")
+	content := []byte("VERIFIED_KEY=ok\n")
+	if err := os.WriteFile(p, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	if err := os.WriteFile(p+SigSuffix, []byte(hex.EncodeToString(sum[:])+"  .env\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VERIFIED_KEY", "")
+	if err := Load(p, WithVerifier(SHA256Sidecar())); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if os.Getenv("VERIFIED_KEY") != "ok" {
+		t.Fatalf("VERIFIED_KEY not set")
+	}
+
+	if err := os.WriteFile(p, []byte("VERIFIED_KEY=tampered\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(p, WithVerifier(SHA256Sidecar())); !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("want ErrIntegrity, got %v", err)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := ed25519.Sign(priv, []byte("VERIFIED_KEY=tampered\n"))
+	if err := os.WriteFile(p+SigSuffix, []byte(base64.StdEncoding.EncodeToString(sig)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(p, WithVerifier(Ed25519Sidecar(pub))); err != nil {
+		t.Fatalf("ed25519 verify: %v", err)
+	}
+	if _, err := ReadFile(p, WithVerifier(Ed25519Sidecar(pub[:16]))); !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("short key: want ErrIntegrity, got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, ".env")
+	if err := os.WriteFile(p, []byte("WATCH_A=1\nWATCH_B=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WATCH_A", "1")
+	t.Setenv("WATCH_B", "2")
+	t.Setenv("WATCH_C", "")
+	w, err := Watch([]string{p}, WatchOptions{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer w.Stop()
+
+	if err := os.WriteFile(p, []byte("WATCH_A=10\nWATCH_C=3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := w.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	want := []types.Change{
+		{Key: "WATCH_A", Kind: types.Modified, Old: "1", New: "10"},
+		{Key: "WATCH_B", Kind: types.Removed, Old: "2"},
+		{Key: "WATCH_C", Kind: types.Added, New: "3"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if os.Getenv("WATCH_A") != "10" || os.Getenv("WATCH_C") != "3" {
+		t.Fatalf("env not updated")
+	}
+	if _, ok := os.LookupEnv("WATCH_B"); ok {
+		t.Fatalf("WATCH_B not unset")
+	}
+	if changes, _ := w.Poll(); len(changes) != 0 {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+}
+
+func TestWatchApplyRetry(t *testing.T) {
+	p := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(p, []byte("RETRY_A=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fail := true
+	var applied []types.Change
+	w, err := Watch([]string{p}, WatchOptions{Interval: -1, Apply: func(c []types.Change) error {
+		if fail {
+			fail = false
+			return errors.New("apply failed")
+		}
+		applied = c
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer w.Stop()
+	if err := os.WriteFile(p, []byte("RETRY_A=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Poll(); err == nil {
+		t.Fatal("want apply error")
+	}
+	changes, err := w.Poll()
+	if err != nil || len(changes) != 1 || len(applied) != 1 || applied[0].New != "2" {
+		t.Fatalf("retry: changes = %+v, applied = %+v, err = %v", changes, applied, err)
+	}
+	if w.Snapshot()["RETRY_A"] != "2" {
+		t.Fatalf("snapshot = %v", w.Snapshot())
+	}
+}
+
+func TestDefaultPaths(t *testing.T) {
+	t.Setenv("APPDATA", `C:\Users\u\AppData\Roaming`)
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	prog := programName()
+	if got := PlatformPaths("windows"); len(got) != 2 ||
+		got[1] != filepath.Join(`C:\Users\u\AppData\Roaming`, prog, ".env") {
+		t.Fatalf("windows paths = %v", got)
+	}
+	want := []string{".env", filepath.Join("/xdg", prog, ".env"), "/env/.env"}
+	if got := PlatformPaths("linux"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("linux paths = %v", got)
+	}
+
+	SetDefaultPaths([]string{"a.env"})
+	defer SetDefaultPaths(nil)
+	if got := DefaultPaths(); !reflect.DeepEqual(got, []string{"a.env"}) {
+		t.Fatalf("DefaultPaths = %v", got)
+	}
+}
+
+func TestExtends(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	write("base.env", "A=base\nB=base\n")
+	write("mid/staging.env", "#!extends ../base.env\nB=staging\nC=staging\n")
+	prod := write("mid/prod.env", "ENVVAR_EXTENDS=staging.env\nC=prod\n")
+
+	m, err := ReadFile(prod)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := map[string]string{"A": "base", "B": "staging", "C": "prod"}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("map = %v", m)
+	}
+
+	write("loop1.env", "#!extends loop2.env\n")
+	loop := write("loop2.env", "#!extends loop1.env\n")
+	if _, err := ReadFile(loop); err == nil ||
+		!strings.Contains(err.Error(), "env file loop") {
+		t.Fatalf("want loop error, got %v", err)
+	}
+}
+
+func TestParseDotenvSyntax(t *testing.T) {
+	src := `
+export EXPORTED=yes
+PLAIN = spaced value   # comment
+HASH=a#b
+SINGLE='literal $HOME \n' # comment
+DOUBLE="tab\tquote\" dollar\$ other\q"
+EMPTY=
+EMPTY_QUOTED=""
+MULTI="line1
+line2"
+BACKTICK=` + "`it's`" + `
+`
+	m, _, err := parse(strings.NewReader(src), "test.env")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := map[string]string{
+		"EXPORTED":     "yes",
+		"PLAIN":        "spaced value",
+		"HASH":         "a#b",
+		"SINGLE":       `literal $HOME \n`,
+		"DOUBLE":       "tab\tquote\" dollar$ other\\q",
+		"EMPTY":        "",
+		"EMPTY_QUOTED": "",
+		"MULTI":        "line1\nline2",
+		"BACKTICK":     "it's",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("map = %#v", m)
+	}
+
+	for _, bad := range []string{"A=\"open\n", "A='x' trailing\n", "=v\n"} {
+		if _, _, err := parse(strings.NewReader(bad), "bad.env"); err == nil {
+			t.Fatalf("parse(%q): want error", bad)
+		}
+	}
+}
+
+func TestParseConditionals(t *testing.T) {
+	t.Setenv("COND_REGION", "eu")
+	src := `APP_ENV=production
+#!if APP_ENV=production
+LOG_LEVEL=warn
+#!if COND_REGION!=eu
+REGION_URL=us.example.com
+#!else
+REGION_URL=eu.example.com
+#!endif
+#!else
+LOG_LEVEL=debug
+#!endif
+#!if MISSING_KEY
+NEVER=1
+#!endif
+`
+	m, _, err := parse(strings.NewReader(src), "cond.env")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := map[string]string{"APP_ENV": "production", "LOG_LEVEL": "warn",
+		"REGION_URL": "eu.example.com"}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("map = %v", m)
+	}
+
+	for _, bad := range []string{"#!if A\n", "#!endif\n", "#!if A\n#!else\n#!else\n#!endif\n"} {
+		if _, _, err := parse(strings.NewReader(bad), "bad.env"); err == nil {
+			t.Fatalf("parse(%q): want error", bad)
+		}
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte("A=base\nB=base\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("B=local\nC=local\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{base, filepath.Join(dir, "missing"), local}
+
+	m, err := ReadAll(paths, LaterWins)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if want := map[string]string{"A": "base", "B": "local", "C": "local"}; !reflect.DeepEqual(m, want) {
+		t.Fatalf("LaterWins = %v", m)
+	}
+	m, err = ReadAll(paths, FirstWins)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if want := map[string]string{"A": "base", "B": "base", "C": "local"}; !reflect.DeepEqual(m, want) {
+		t.Fatalf("FirstWins = %v", m)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	write("secrets.env", "DB_PASSWORD=s3cret\nDB_USER=included\n")
+	main := write("app.env", "DB_USER=main\n#!include secrets.env\nPORT=8080\n")
+
+	es, err := ReadEntries(main)
+	if err != nil {
+		t.Fatalf("ReadEntries: %v", err)
+	}
+	if e := es["DB_PASSWORD"]; e.Value != "s3cret" || filepath.Base(e.Path) != "secrets.env" || e.Line != 1 {
+		t.Fatalf("DB_PASSWORD = %+v", e)
+	}
+	if e := es["DB_USER"]; e.Value != "included" || e.Line != 2 {
+		t.Fatalf("DB_USER = %+v", e)
+	}
+	if e := es["PORT"]; e.Path != main || e.Line != 3 {
+		t.Fatalf("PORT = %+v", e)
+	}
+
+	cyc := write("cycle.env", "#!include cycle.env\n")
+	if _, err := ReadFile(cyc); err == nil || !strings.Contains(err.Error(), "env file loop") {
+		t.Fatalf("want loop error, got %v", err)
+	}
+}
+
+func TestLoadDefaultsOnly(t *testing.T) {
+	p := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(p, []byte("DEFONLY_SET=file\nDEFONLY_NEW=file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEFONLY_SET", "real")
+	t.Setenv("DEFONLY_NEW", "")
+	os.Unsetenv("DEFONLY_NEW")
+	if err := Load(p, WithDefaultsOnly()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("DEFONLY_SET"); got != "real" {
+		t.Fatalf("DEFONLY_SET = %q, want real env to win", got)
+	}
+	if got := os.Getenv("DEFONLY_NEW"); got != "file" {
+		t.Fatalf("DEFONLY_NEW = %q", got)
+	}
+}
+
+func TestReadFromAndFS(t *testing.T) {
+	m, err := ReadFrom(strings.NewReader("export A=1\nB='two'\n"))
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if _, err := ReadFrom(strings.NewReader("A=1\nmy.key=2\n")); err == nil ||
+		!strings.Contains(err.Error(), `invalid variable name "my.key"`) {
+		t.Fatalf("want invalid name error, got %v", err)
+	}
+	if want := map[string]string{"A": "1", "B": "two"}; !reflect.DeepEqual(m, want) {
+		t.Fatalf("ReadFrom = %v", m)
+	}
+
+	fsys := fstest.MapFS{
+		"config/a.env":      {Data: []byte("FS_A=a\nFS_SHARED=a\n")},
+		"config/b.env":      {Data: []byte("FS_SHARED=b\n#!include ../secrets/s.env\n")},
+		"secrets/s.env":     {Data: []byte("FS_SECRET=s\n")},
+		"config/ignore.txt": {Data: []byte("not env")},
+	}
+	m, err = ReadFS(fsys, "config/*.env")
+	if err != nil {
+		t.Fatalf("ReadFS: %v", err)
+	}
+	want := map[string]string{"FS_A": "a", "FS_SHARED": "b", "FS_SECRET": "s"}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("ReadFS = %v", m)
+	}
+	for k := range want {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	t.Setenv("FS_SHARED", "real")
+	if err := LoadFS(fsys, "config/*.env"); err != nil {
+		t.Fatalf("LoadFS: %v", err)
+	}
+	if os.Getenv("FS_SECRET") != "s" {
+		t.Fatalf("FS_SECRET not set")
+	}
+	if got := os.Getenv("FS_SHARED"); got != "real" {
+		t.Fatalf("real env should win over embedded defaults, got %q", got)
+	}
+	if err := LoadFS(fsys, "config/missing.env"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("want ErrNotExist for a missing plain path, got %v", err)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":                  "PROF_A=env\nPROF_B=env\nPROF_C=env\nPROF_D=env\n",
+		".env.staging":          "PROF_B=staging\nPROF_C=staging\nPROF_D=staging\n",
+		".env.local":            "PROF_C=local\nPROF_D=local\n",
+		".env.staging.local":    "PROF_D=staging.local\n",
+		".env.production.local": "PROF_D=production.local\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	t.Setenv("APP_ENV", "staging")
+	for _, k := range []string{"PROF_A", "PROF_B", "PROF_C", "PROF_D"} {
+		t.Setenv(k, "")
+	}
+	if err := LoadProfile(""); err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	got := []string{os.Getenv("PROF_A"), os.Getenv("PROF_B"),
+		os.Getenv("PROF_C"), os.Getenv("PROF_D")}
+	want := []string{"env", "staging", "local", "staging.local"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	m := map[string]string{
+		"B":      "plain",
+		"A":      "with space # not comment",
+		"QUOTES": `say "hi" it's`,
+		"MULTI":  "line1\nline2\ttab \\ slash",
+		"EMPTY":  "",
+		"DOLLAR": "$HOME",
+	}
+	out := Marshal(m)
+	if !strings.HasPrefix(string(out), "A=") {
+		t.Fatalf("keys not sorted:\n%s", out)
+	}
+	if string(Marshal(m)) != string(out) {
+		t.Fatalf("Marshal not deterministic")
+	}
+	back, err := ReadFrom(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if !reflect.DeepEqual(back, m) {
+		t.Fatalf("round trip = %#v\n%s", back, out)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	types.SetLimits(types.Limits{MaxValueSize: 4, MaxKeys: 2, MaxTotalSize: 32})
+	defer types.SetLimits(types.Limits{})
+	for _, bad := range []string{
+		"A=12345\n",
+		"A=1\nB=2\nC=3\n",
+		"A=\"" + strings.Repeat("x", 40) + "\"\n",
+	} {
+		if _, err := ReadFrom(strings.NewReader(bad)); !errors.Is(err, types.ErrLimit) {
+			t.Fatalf("ReadFrom(%q) = %v, want ErrLimit", bad, err)
+		}
+	}
+	if _, err := ReadFrom(strings.NewReader("A=1234\nB=2\n")); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+}
+
+func TestReadProcEnviron(t *testing.T) {
+	dir := t.TempDir()
+	old := procRoot
+	procRoot = dir
+	defer func() { procRoot = old }()
+	if err := os.MkdirAll(filepath.Join(dir, "42"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "A=1\x00DSN=postgres://h/db?x=y\x00\x00"
+	if err := os.WriteFile(filepath.Join(dir, "42", "environ"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadProcEnviron(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"A": "1", "DSN": "postgres://h/db?x=y"}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("got %v, want %v", m, want)
+	}
+	if _, err := ReadProcEnviron(7); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("want ErrNotExist, got %v", err)
+	}
+}
+
+func TestExportSnippets(t *testing.T) {
+	m := map[string]string{
+		"PORT":        "8080",
+		"GREETING":    `it's "100%"`,
+		"DB_PASSWORD": "hunter2",
+	}
+	unit := string(SystemdEnvironment(m, "/etc/app/secrets.env"))
+	want := `Environment="GREETING=it's \"100%%\""
+Environment="PORT=8080"
+# Secrets: DB_PASSWORD
+EnvironmentFile=/etc/app/secrets.env
+`
+	if unit != want {
+		t.Fatalf("systemd:\n%s\nwant:\n%s", unit, want)
+	}
+	if got := DockerFlags(m); got != `-e 'GREETING=it'\''s "100%"' -e 'PORT=8080' -e DB_PASSWORD` {
+		t.Fatalf("docker flags: %s", got)
+	}
+	if got := string(DockerEnvFile(m)); got != "DB_PASSWORD\nGREETING=it's \"100%\"\nPORT=8080\n" {
+		t.Fatalf("docker env file: %q", got)
+	}
+	for _, out := range []string{unit, DockerFlags(m), string(DockerEnvFile(m))} {
+		if strings.Contains(out, "hunter2") {
+			t.Fatalf("secret inlined: %s", out)
+		}
+	}
+}
+
+func TestWithFSMultiFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.env":  {Data: []byte("WF_A=base\nWF_B=base\n")},
+		"local.env": {Data: []byte("WF_B=local\n")},
+	}
+	m, err := ReadAll([]string{"base.env", "missing.env", "local.env"}, LaterWins, WithFS(fsys))
+	if want := map[string]string{"WF_A": "base", "WF_B": "local"}; err != nil || !reflect.DeepEqual(m, want) {
+		t.Fatalf("ReadAll = %v, %v", m, err)
+	}
+
+	for _, k := range []string{"WF_A", "WF_B"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	if err := LoadAll([]string{"base.env", "local.env"}, FirstWins, WithFS(fsys)); err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv("WF_A") != "base" || os.Getenv("WF_B") != "base" {
+		t.Fatalf("LoadAll: WF_A=%q WF_B=%q", os.Getenv("WF_A"), os.Getenv("WF_B"))
+	}
+
+	t.Setenv("WF_ONCE", "")
+	os.Unsetenv("WF_ONCE")
+	ResetLoadOnce()
+	defer ResetLoadOnce()
+	fsys["once.env"] = &fstest.MapFile{Data: []byte("WF_ONCE=1\n")}
+	if err := LoadOnce([]string{"missing.env", "once.env"}, WithFS(fsys)); err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv("WF_ONCE") != "1" {
+		t.Fatal("LoadOnce ignored WithFS")
+	}
+}
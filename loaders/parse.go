@@ -0,0 +1,279 @@
+package loaders
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/sources"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// directive is a "#!name arg" line.
+type directive struct {
+	name string
+	arg  string
+	line int
+}
+
+// parseEntries parses dotenv content and its "#!" directives. path is
+// used in error messages and to resolve includes; stack is passed on
+// to included files for loop detection. The accepted syntax follows
+// the de-facto dotenv format:
+//
+//	KEY=value            # inline comment
+//	export KEY=value
+//	KEY='literal $value'
+//	KEY="escaped\tvalue\n"
+//	KEY="multi
+//	line"
+//
+// Unquoted values are trimmed and end at " #". Single-quoted (and
+// backquoted) values are literal. Double-quoted values interpret \n,
+// \r, \t, \", \\ and \$; other escapes are kept as-is. Quoted values may
+// span lines.
+//
+// Conditional blocks include lines only when a condition holds:
+//
+//	#!if APP_ENV=production
+//	LOG_LEVEL=warn
+//	#!else
+//	LOG_LEVEL=debug
+//	#!endif
+//
+// Conditions are KEY=value, KEY!=value, or KEY (set and non-empty), and
+// see keys defined earlier in the file, then the default source. Blocks
+// nest.
+//
+// "#!include other.env" inserts the variables of another file, resolved
+// relative to path, at that point.
+func parseEntries(r io.Reader, path string, o options, stack []string) (map[string]Entry, []directive, error) {
+	m := make(map[string]Entry)
+	var dirs []directive
+	var lines []string
+	lim := types.CurrentLimits()
+	if lim.MaxTotalSize > 0 {
+		r = io.LimitReader(r, int64(lim.MaxTotalSize)+1)
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	total := 0
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+		total += len(sc.Bytes()) + 1
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	if lim.MaxTotalSize > 0 && total > lim.MaxTotalSize {
+		return nil, nil, fmt.Errorf("%w: %s exceeds %d bytes",
+			types.ErrLimit, filepath.Base(path), lim.MaxTotalSize)
+	}
+	limitErr := func(ln int, key, msg string) error {
+		return fmt.Errorf("%w: %s:%d: %s: %s", types.ErrLimit,
+			filepath.Base(path), ln, key, msg)
+	}
+	invalid := func(ln int, why string) error {
+		return errors.New("envvar: " + why + " " +
+			filepath.Base(path) + ":" + strconvI(ln))
+	}
+	var conds []condBlock
+	active := true
+	for i := 0; i < len(lines); i++ {
+		ln := i + 1
+		line := strings.TrimSpace(lines[i])
+		if d, ok := strings.CutPrefix(line, "#!"); ok {
+			name, arg, _ := strings.Cut(d, " ")
+			arg = strings.TrimSpace(arg)
+			switch name {
+			case "if":
+				if arg == "" {
+					return nil, nil, invalid(ln, "empty #!if condition")
+				}
+				c := condBlock{parent: active, line: ln}
+				c.holds = active && evalCond(arg, m)
+				conds = append(conds, c)
+			case "else":
+				if len(conds) == 0 || conds[len(conds)-1].inElse {
+					return nil, nil, invalid(ln, "unexpected #!else")
+				}
+				conds[len(conds)-1].inElse = true
+			case "endif":
+				if len(conds) == 0 {
+					return nil, nil, invalid(ln, "unexpected #!endif")
+				}
+				conds = conds[:len(conds)-1]
+			case "include":
+				if !active {
+					break
+				}
+				if arg == "" {
+					return nil, nil, invalid(ln, "empty #!include")
+				}
+				arg = o.resolve(path, arg)
+				sub, err := readEntries(arg, o, stack)
+				if err != nil {
+					return nil, nil, fmt.Errorf("envvar: %s:%d includes %s: %w",
+						filepath.Base(path), ln, arg, err)
+				}
+				for k, e := range sub {
+					m[k] = e
+				}
+			default:
+				if active {
+					dirs = append(dirs, directive{name: name, arg: arg, line: ln})
+				}
+			}
+			active = len(conds) == 0 || conds[len(conds)-1].active()
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || !active {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export"); ok &&
+			rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, nil, invalid(ln, "invalid line")
+		}
+		if types.ValidName(k) != nil {
+			return nil, nil, invalid(ln, fmt.Sprintf("invalid variable name %q at", k))
+		}
+		v = strings.TrimLeft(v, " \t")
+		if v == "" || !strings.ContainsRune(`"'`+"`", rune(v[0])) {
+			v = unquotedValue(v)
+			if msg := lim.CheckValue(v); msg != "" {
+				return nil, nil, limitErr(ln, k, msg)
+			}
+			m[k] = Entry{Value: v, Path: path, Line: ln}
+			continue
+		}
+		q := v[0]
+		body := v[1:]
+		for {
+			end := closingQuote(body, q)
+			if end >= 0 {
+				if tail := strings.TrimSpace(body[end+1:]); tail != "" &&
+					!strings.HasPrefix(tail, "#") {
+					return nil, nil, invalid(i+1, "unexpected text after quoted value")
+				}
+				body = body[:end]
+				break
+			}
+			if i+1 >= len(lines) {
+				return nil, nil, invalid(ln, "unterminated quoted value")
+			}
+			i++
+			body += "\n" + lines[i]
+		}
+		if q == '"' {
+			body = unescape(body)
+		}
+		if msg := lim.CheckValue(body); msg != "" {
+			return nil, nil, limitErr(ln, k, msg)
+		}
+		m[k] = Entry{Value: body, Path: path, Line: ln}
+	}
+	if lim.MaxKeys > 0 && len(m) > lim.MaxKeys {
+		return nil, nil, fmt.Errorf("%w: %s defines %d keys, max %d",
+			types.ErrLimit, filepath.Base(path), len(m), lim.MaxKeys)
+	}
+	if len(conds) > 0 {
+		return nil, nil, invalid(conds[len(conds)-1].line, "unterminated #!if")
+	}
+	return m, dirs, nil
+}
+
+// condBlock is an open "#!if" block.
+type condBlock struct {
+	parent bool // whether the enclosing block is active
+	holds  bool // whether the condition held
+	inElse bool
+	line   int
+}
+
+// active reports whether lines in the current branch are included.
+func (c condBlock) active() bool {
+	return c.parent && c.holds != c.inElse
+}
+
+// evalCond evaluates a "#!if" condition against m, then the default
+// source.
+func evalCond(cond string, m map[string]Entry) bool {
+	lookup := func(k string) (string, bool) {
+		if e, ok := m[k]; ok {
+			return e.Value, true
+		}
+		return sources.Lookup(k)
+	}
+	if k, want, ok := strings.Cut(cond, "!="); ok {
+		v, _ := lookup(strings.TrimSpace(k))
+		return v != strings.TrimSpace(want)
+	}
+	if k, want, ok := strings.Cut(cond, "="); ok {
+		v, found := lookup(strings.TrimSpace(k))
+		return found && v == strings.TrimSpace(want)
+	}
+	v, ok := lookup(cond)
+	return ok && v != ""
+}
+
+// unquotedValue trims v and strips an inline comment.
+func unquotedValue(v string) string {
+	for i := 1; i < len(v); i++ {
+		if v[i] == '#' && (v[i-1] == ' ' || v[i-1] == '\t') {
+			v = v[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(v)
+}
+
+// closingQuote returns the index of the quote q ending s, or -1. In
+// double-quoted values a backslash escapes the next byte.
+func closingQuote(s string, q byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape interprets the escapes of a double-quoted value.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package loaders
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	// pathsMu protects defaultPaths.
+	pathsMu sync.RWMutex
+	// defaultPaths are tried when caller passes nil. nil means the
+	// platform defaults.
+	defaultPaths []string
+)
+
+// SetDefaultPaths replaces the paths LoadOnce tries when called with
+// nil. Passing an empty slice restores the platform defaults.
+//
+// Parameters:
+//   - paths: The paths, in order of preference.
+func SetDefaultPaths(paths []string) {
+	pathsMu.Lock()
+	defer pathsMu.Unlock()
+	defaultPaths = append([]string(nil), paths...)
+}
+
+// DefaultPaths returns the paths LoadOnce tries when called with nil.
+//
+// Returns:
+//   - []string: The paths, in order of preference.
+func DefaultPaths() []string {
+	pathsMu.RLock()
+	defer pathsMu.RUnlock()
+	if defaultPaths != nil {
+		return append([]string(nil), defaultPaths...)
+	}
+	return PlatformPaths(runtime.GOOS)
+}
+
+// PlatformPaths returns the default env file locations for goos:
+//
+//   - ".env" in the working directory, everywhere.
+//   - "<config>/<program>/.env", where config is %APPDATA% on Windows,
+//     $XDG_CONFIG_HOME (or ~/.config) on Unix, ~/Library/Application
+//     Support on macOS, and $home/lib on Plan 9.
+//   - "/env/.env" on Unix, for container mounts.
+//
+// Parameters:
+//   - goos: The operating system, usually runtime.GOOS.
+//
+// Returns:
+//   - []string: The paths, in order of preference.
+func PlatformPaths(goos string) []string {
+	paths := []string{".env"}
+	if dir := configDir(goos); dir != "" {
+		paths = append(paths, filepath.Join(dir, programName(), ".env"))
+	}
+	switch goos {
+	case "windows", "plan9":
+	default:
+		paths = append(paths, "/env/.env")
+	}
+	return paths
+}
+
+// configDir returns the per-user configuration directory for goos, or
+// "" if it cannot be determined.
+func configDir(goos string) string {
+	switch goos {
+	case "windows":
+		return os.Getenv("APPDATA")
+	case "plan9":
+		if h := os.Getenv("home"); h != "" {
+			return filepath.Join(h, "lib")
+		}
+		return ""
+	case "darwin", "ios":
+		if h := os.Getenv("HOME"); h != "" {
+			return filepath.Join(h, "Library", "Application Support")
+		}
+		return ""
+	}
+	if x := os.Getenv("XDG_CONFIG_HOME"); x != "" && filepath.IsAbs(x) {
+		return x
+	}
+	if h := os.Getenv("HOME"); h != "" {
+		return filepath.Join(h, ".config")
+	}
+	return ""
+}
+
+// programName returns the executable base name without extension.
+func programName() string {
+	if len(os.Args) == 0 {
+		return "envvar"
+	}
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// ProfileKeys are the variables LoadProfile consults, in order, when no
+// profile is given.
+var ProfileKeys = []string{"APP_ENV", "GO_ENV"}
+
+// ProfilePaths returns the layered files of profile, lowest priority
+// first: .env, .env.<profile>, .env.local, .env.<profile>.local. An
+// empty profile yields .env and .env.local.
+//
+// Parameters:
+//   - profile: The profile, e.g. "production".
+//
+// Returns:
+//   - []string: The paths.
+func ProfilePaths(profile string) []string {
+	if profile == "" {
+		return []string{".env", ".env.local"}
+	}
+	return []string{".env", ".env." + profile, ".env.local",
+		".env." + profile + ".local"}
+}
+
+// LoadProfile loads the existing files of ProfilePaths in the working
+// directory, later files overriding earlier ones. When profile is
+// empty it is taken from the first set variable of ProfileKeys.
+//
+// Parameters:
+//   - profile: The profile, or "" to detect it.
+//   - opts: The loading options.
+//
+// Returns:
+//   - error: The error if reading, verification, or parsing fails.
+func LoadProfile(profile string, opts ...Option) error {
+	if profile == "" {
+		for _, k := range ProfileKeys {
+			if v := strings.TrimSpace(os.Getenv(k)); v != "" {
+				profile = v
+				break
+			}
+		}
+	}
+	return LoadAll(ProfilePaths(profile), LaterWins, opts...)
+}
@@ -0,0 +1,36 @@
+package loaders
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+// procRoot is the procfs mount point.
+var procRoot = "/proc"
+
+// ReadProcEnviron reads the environment of another process from
+// /proc/<pid>/environ (Linux). It is the environment the process was
+// started with; later changes made by the process itself are not
+// visible. Reading another user's process requires privileges. Combine
+// it with Diff to compare processes, or with sources.Map and Bind to
+// decode it into a config struct.
+//
+// Parameters:
+//   - pid: The process ID.
+//
+// Returns:
+//   - map[string]string: The variables.
+//   - error: The error if the file cannot be read, e.g. on non-Linux
+//     systems or without permission.
+func ReadProcEnviron(pid int) (map[string]string, error) {
+	b, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return nil, fmt.Errorf("envvar: environ of pid %d: %w", pid, err)
+	}
+	return sources.FromEnviron(strings.Split(string(b), "\x00")), nil
+}
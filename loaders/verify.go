@@ -0,0 +1,106 @@
+package loaders
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrIntegrity is returned (wrapped) when an env file fails
+// verification.
+var ErrIntegrity = errors.New("envvar: integrity check failed")
+
+// SigSuffix is appended to an env file path to locate its detached
+// checksum or signature, e.g. ".env.sig".
+const SigSuffix = ".sig"
+
+// Verifier checks the contents of an env file before it is parsed.
+type Verifier interface {
+	// Verify returns an error if data, read from path, must not be
+	// loaded.
+	Verify(path string, data []byte) error
+}
+
+// VerifierFunc adapts a function to Verifier.
+type VerifierFunc func(path string, data []byte) error
+
+// Verify calls f.
+func (f VerifierFunc) Verify(path string, data []byte) error {
+	return f(path, data)
+}
+
+// SHA256Sidecar verifies a file against the hex SHA-256 digest stored
+// in path+SigSuffix. The sidecar may use the bare digest, "sha256:<hex>",
+// or sha256sum output ("<hex>  <name>").
+//
+// Returns:
+//   - Verifier: The verifier.
+func SHA256Sidecar() Verifier {
+	return VerifierFunc(func(path string, data []byte) error {
+		raw, err := readSig(path)
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(raw)
+		if len(fields) == 0 {
+			return fmt.Errorf("%w: %s: empty checksum", ErrIntegrity, path)
+		}
+		want, err := hex.DecodeString(strings.TrimPrefix(fields[0], "sha256:"))
+		if err != nil {
+			return fmt.Errorf("%w: %s: malformed checksum", ErrIntegrity, path)
+		}
+		got := sha256.Sum256(data)
+		if subtle.ConstantTimeCompare(want, got[:]) != 1 {
+			return fmt.Errorf("%w: %s: checksum mismatch", ErrIntegrity, path)
+		}
+		return nil
+	})
+}
+
+// Ed25519Sidecar verifies a file against the base64 Ed25519 signature
+// stored in path+SigSuffix. A pub that is not ed25519.PublicKeySize
+// bytes long fails every file.
+//
+// Parameters:
+//   - pub: The public key.
+//
+// Returns:
+//   - Verifier: The verifier.
+func Ed25519Sidecar(pub ed25519.PublicKey) Verifier {
+	if len(pub) != ed25519.PublicKeySize {
+		n := len(pub)
+		return VerifierFunc(func(path string, _ []byte) error {
+			return fmt.Errorf("%w: %s: ed25519 public key is %d bytes, want %d",
+				ErrIntegrity, path, n, ed25519.PublicKeySize)
+		})
+	}
+	return VerifierFunc(func(path string, data []byte) error {
+		raw, err := readSig(path)
+		if err != nil {
+			return err
+		}
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%w: %s: malformed signature", ErrIntegrity, path)
+		}
+		if !ed25519.Verify(pub, data, sig) {
+			return fmt.Errorf("%w: %s: signature mismatch", ErrIntegrity, path)
+		}
+		return nil
+	})
+}
+
+// readSig reads the sidecar for path.
+func readSig(path string) (string, error) {
+	b, err := os.ReadFile(path + SigSuffix)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrIntegrity, path, err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,280 @@
+package loaders
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// DefaultWatchInterval is the polling interval used when
+// WatchOptions.Interval is zero.
+const DefaultWatchInterval = time.Second
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// Interval is the polling interval. Defaults to DefaultWatchInterval.
+	// A negative interval disables background polling; call Poll
+	// instead.
+	Interval time.Duration
+	// Apply receives the changes of each reload. Defaults to ApplyEnv,
+	// which updates the process env.
+	Apply func(changes []types.Change) error
+	// Load configures file reading, e.g. WithVerifier.
+	Load []Option
+}
+
+// fileStamp identifies a file version without reading it.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// Watcher polls env files and applies changes as they appear.
+type Watcher struct {
+	paths []string
+	apply func([]types.Change) error
+	o     options
+
+	mu     sync.Mutex
+	stamps []fileStamp
+	snap   map[string]string
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Watch reads paths and polls them for changes until Stop is called.
+// Later paths override earlier ones; missing files contribute no keys,
+// so deleting a file removes its variables. The initial contents are
+// taken as the baseline and are not applied, so call Load first if the
+// process env must start in sync.
+//
+// On each change the merged contents are diffed against the previous
+// version, the diff is passed to WatchOptions.Apply, the changed keys
+// are dropped from caches in the default source, and installed hooks
+// implementing types.ChangeHook receive it. Read errors are
+// reported through types.ErrorHook and the previous version is kept.
+//
+// Parameters:
+//   - paths: The env files to watch.
+//   - opts: The options.
+//
+// Returns:
+//   - *Watcher: The running watcher.
+//   - error: The error if the initial read fails.
+func Watch(paths []string, opts WatchOptions) (*Watcher, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("envvar: watch: no paths")
+	}
+	w := &Watcher{
+		paths: append([]string(nil), paths...),
+		apply: opts.Apply,
+		o:     buildOptions(opts.Load),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if w.apply == nil {
+		w.apply = ApplyEnv
+	}
+	stamps, snap, err := w.read()
+	if err != nil {
+		return nil, err
+	}
+	w.stamps, w.snap = stamps, snap
+	interval := opts.Interval
+	switch {
+	case interval < 0:
+		close(w.done)
+		return w, nil
+	case interval == 0:
+		interval = DefaultWatchInterval
+	}
+	// Create the ticker before returning so clock advances made right
+	// after Watch are observed.
+	go w.loop(types.CurrentClock().NewTicker(interval))
+	return w, nil
+}
+
+// Poll checks the files once and applies any changes immediately.
+//
+// Returns:
+//   - []types.Change: The applied changes, sorted by key.
+//   - error: The error if reading or applying fails.
+func (w *Watcher) Poll() ([]types.Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stamps, changed := w.stat()
+	if !changed {
+		return nil, nil
+	}
+	_, snap, err := w.read()
+	if err != nil {
+		return nil, err
+	}
+	changes := Diff(w.snap, snap)
+	if len(changes) == 0 {
+		w.stamps = stamps
+		return nil, nil
+	}
+	err = w.apply(changes)
+	// Apply may have changed some keys before failing.
+	keys := make([]string, len(changes))
+	for i, c := range changes {
+		keys[i] = c.Key
+	}
+	invalidate(keys)
+	if err != nil {
+		// Keep the old stamps so the next Poll retries the change.
+		return nil, err
+	}
+	w.stamps, w.snap = stamps, snap
+	types.CallOnChange(w.name(), changes)
+	return changes, nil
+}
+
+// Snapshot returns a copy of the last applied merged contents.
+//
+// Returns:
+//   - map[string]string: The variables.
+func (w *Watcher) Snapshot() map[string]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make(map[string]string, len(w.snap))
+	for k, v := range w.snap {
+		out[k] = v
+	}
+	return out
+}
+
+// Stop stops polling and waits for the poller to exit. It is safe to
+// call more than once.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// loop polls until stopped.
+func (w *Watcher) loop(t types.Ticker) {
+	defer close(w.done)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C():
+			if _, err := w.Poll(); err != nil {
+				types.CallOnError(w.name(), err)
+			}
+		}
+	}
+}
+
+// name identifies the watcher in hook calls.
+func (w *Watcher) name() string {
+	return "watch:" + strings.Join(w.paths, ",")
+}
+
+// stat returns the current stamps and whether any differ from the last
+// seen ones.
+func (w *Watcher) stat() ([]fileStamp, bool) {
+	stamps := make([]fileStamp, len(w.paths))
+	changed := false
+	for i, p := range w.paths {
+		if info, err := w.o.stat(p); err == nil && !info.IsDir() {
+			stamps[i] = fileStamp{exists: true, size: info.Size(),
+				modTime: info.ModTime()}
+		}
+		if stamps[i] != w.stamps[i] {
+			changed = true
+		}
+	}
+	return stamps, changed
+}
+
+// read stats and reads every path and merges the contents.
+func (w *Watcher) read() ([]fileStamp, map[string]string, error) {
+	stamps := make([]fileStamp, len(w.paths))
+	merged := map[string]string{}
+	for i, p := range w.paths {
+		info, err := w.o.stat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		stamps[i] = fileStamp{exists: true, size: info.Size(),
+			modTime: info.ModTime()}
+		m, err := readFile(p, w.o)
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return stamps, merged, nil
+}
+
+// Diff returns the changes that turn old into new, sorted by key.
+//
+// Parameters:
+//   - old: The previous variables.
+//   - new: The current variables.
+//
+// Returns:
+//   - []types.Change: The changes.
+func Diff(old, new map[string]string) []types.Change {
+	var out []types.Change
+	for k, nv := range new {
+		ov, ok := old[k]
+		switch {
+		case !ok:
+			out = append(out, types.Change{Key: k, Kind: types.Added, New: nv})
+		case ov != nv:
+			out = append(out, types.Change{Key: k, Kind: types.Modified,
+				Old: ov, New: nv})
+		}
+	}
+	for k, ov := range old {
+		if _, ok := new[k]; !ok {
+			out = append(out, types.Change{Key: k, Kind: types.Removed, Old: ov})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ApplyEnv applies changes to the process env: added and modified keys
+// are set and removed keys are unset.
+//
+// Parameters:
+//   - changes: The changes.
+//
+// Returns:
+//   - error: The error if setting a variable fails.
+func ApplyEnv(changes []types.Change) error {
+	for _, c := range changes {
+		var err error
+		if c.Kind == types.Removed {
+			err = os.Unsetenv(c.Key)
+		} else {
+			err = os.Setenv(c.Key, c.New)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
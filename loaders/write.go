@@ -0,0 +1,57 @@
+package loaders
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Marshal renders m as an env file with keys in sorted order, so the
+// output is reproducible. Values that would not survive a round trip
+// unquoted are double-quoted and escaped.
+//
+// Parameters:
+//   - m: The variables.
+//
+// Returns:
+//   - []byte: The env file content.
+func Marshal(m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(m[k]))
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// WriteFile writes m to path in the format of Marshal.
+//
+// Parameters:
+//   - path: The file path.
+//   - m: The variables.
+//   - perm: The file mode used if the file is created.
+//
+// Returns:
+//   - error: The error if writing fails.
+func WriteFile(path string, m map[string]string, perm os.FileMode) error {
+	return os.WriteFile(path, Marshal(m), perm)
+}
+
+// quoteValue quotes v if parse would not return it verbatim.
+func quoteValue(v string) string {
+	if v == "" || (!strings.ContainsAny(v, " \t\r\n\"'`#\\") &&
+		strings.TrimSpace(v) == v) {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`,
+		"\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(v) + `"`
+}
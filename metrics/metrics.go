@@ -0,0 +1,292 @@
+// Package metrics provides a Hook that counts envvar activity and
+// exports it to expvar and in the Prometheus text format.
+//
+// The package has no Prometheus client dependency. A Hook is an
+// http.Handler serving the text exposition format, so it can be mounted
+// next to an existing /metrics endpoint or scraped on its own:
+//
+//	m := metrics.New()
+//	envvar.SetHook(m)
+//	m.Publish("envvar")                // expvar, under /debug/vars
+//	http.Handle("/metrics/envvar", m) // Prometheus
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Buckets are the histogram upper bounds, in seconds, used for get and
+// bind durations.
+var Buckets = []float64{1e-6, 1e-5, 1e-4, 1e-3, 1e-2, 1e-1, 1, 10}
+
+// Hook counts gets, misses, parse errors, loads, defaults, and binds.
+// It implements envvar.Hook and the optional ErrorHook, BindHook,
+// ValidationHook, and DefaultHook. The zero value is not usable; use
+// New.
+type Hook struct {
+	mu       sync.Mutex
+	gets     uint64
+	misses   uint64
+	getErrs  uint64
+	parse    uint64
+	defaults uint64
+	bgErrs   uint64
+	binds    uint64
+	bindErrs uint64
+	loads    map[string]uint64
+	keys     map[string]int
+	rules    map[string]uint64
+	getDur   histogram
+	bindDur  histogram
+}
+
+// New returns an empty Hook.
+//
+// Returns:
+//   - *Hook: The hook.
+func New() *Hook {
+	return &Hook{
+		loads:   map[string]uint64{},
+		keys:    map[string]int{},
+		rules:   map[string]uint64{},
+		getDur:  newHistogram(),
+		bindDur: newHistogram(),
+	}
+}
+
+// OnLoad counts a load of source and records its key count.
+func (h *Hook) OnLoad(source string, keys int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.loads[source]++
+	h.keys[source] = keys
+}
+
+// OnGet counts a read, a miss when !ok, and a failed read when err is
+// set, and observes its duration.
+func (h *Hook) OnGet(_ string, ok bool, err error, dur time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.gets++
+	if !ok {
+		h.misses++
+	}
+	if err != nil {
+		h.getErrs++
+	}
+	h.getDur.observe(dur)
+}
+
+// OnError counts a background error.
+func (h *Hook) OnError(string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bgErrs++
+}
+
+// OnBind counts a Bind call and its errors and observes its duration.
+func (h *Hook) OnBind(_ string, _, errors int, dur time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.binds++
+	h.bindErrs += uint64(errors)
+	h.bindDur.observe(dur)
+}
+
+// OnValidationError counts a failed rule; the rule "type" counts as a
+// parse error.
+func (h *Hook) OnValidationError(_, rule, _ string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if rule == "type" {
+		h.parse++
+		return
+	}
+	h.rules[rule]++
+}
+
+// OnDefaultUsed counts a default taking the place of a variable.
+func (h *Hook) OnDefaultUsed(string, string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.defaults++
+}
+
+// Snapshot is a point-in-time copy of the counters.
+type Snapshot struct {
+	Gets             uint64            `json:"gets"`
+	Misses           uint64            `json:"misses"`
+	GetErrors        uint64            `json:"get_errors"`
+	ParseErrors      uint64            `json:"parse_errors"`
+	ValidationErrors map[string]uint64 `json:"validation_errors"`
+	DefaultsUsed     uint64            `json:"defaults_used"`
+	Errors           uint64            `json:"errors"`
+	Loads            map[string]uint64 `json:"loads"`
+	LoadedKeys       map[string]int    `json:"loaded_keys"`
+	Binds            uint64            `json:"binds"`
+	BindErrors       uint64            `json:"bind_errors"`
+	GetSeconds       float64           `json:"get_seconds"`
+	BindSeconds      float64           `json:"bind_seconds"`
+}
+
+// Snapshot copies the current counters.
+//
+// Returns:
+//   - Snapshot: The counters.
+func (h *Hook) Snapshot() Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return Snapshot{
+		Gets:             h.gets,
+		Misses:           h.misses,
+		GetErrors:        h.getErrs,
+		ParseErrors:      h.parse,
+		ValidationErrors: copyMap(h.rules),
+		DefaultsUsed:     h.defaults,
+		Errors:           h.bgErrs,
+		Loads:            copyMap(h.loads),
+		LoadedKeys:       copyMap(h.keys),
+		Binds:            h.binds,
+		BindErrors:       h.bindErrs,
+		GetSeconds:       h.getDur.sum,
+		BindSeconds:      h.bindDur.sum,
+	}
+}
+
+// String renders the snapshot as JSON, making Hook an expvar.Var.
+//
+// Returns:
+//   - string: The JSON.
+func (h *Hook) String() string {
+	b, _ := json.Marshal(h.Snapshot())
+	return string(b)
+}
+
+// Publish exports the hook to expvar under name. Like expvar.Publish,
+// it panics if name is already in use.
+//
+// Parameters:
+//   - name: The expvar name, e.g. "envvar".
+func (h *Hook) Publish(name string) {
+	expvar.Publish(name, h)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (h *Hook) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = h.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format. Names are
+// prefixed "envvar_"; durations are histograms in seconds.
+//
+// Parameters:
+//   - w: The writer.
+//
+// Returns:
+//   - int64: The number of bytes written.
+//   - error: The write error, if any.
+func (h *Hook) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	var b strings.Builder
+	counter(&b, "envvar_gets_total", "Variable reads.", h.gets)
+	counter(&b, "envvar_misses_total", "Variable reads that found no value.", h.misses)
+	counter(&b, "envvar_get_errors_total", "Variable reads that failed to resolve.", h.getErrs)
+	counter(&b, "envvar_parse_errors_total", "Values that failed to parse.", h.parse)
+	labeled(&b, "envvar_validation_errors_total", "counter", "Failed validation rules.", "rule", h.rules)
+	counter(&b, "envvar_defaults_used_total", "Defaults used in place of variables.", h.defaults)
+	counter(&b, "envvar_errors_total", "Background errors, such as failed refreshes.", h.bgErrs)
+	labeled(&b, "envvar_loads_total", "counter", "Loads from files and sources.", "source", h.loads)
+	labeled(&b, "envvar_loaded_keys", "gauge", "Variables in the last load of each source.", "source", h.keys)
+	counter(&b, "envvar_binds_total", "Bind calls.", h.binds)
+	counter(&b, "envvar_bind_errors_total", "Errors reported by Bind calls.", h.bindErrs)
+	h.getDur.write(&b, "envvar_get_duration_seconds", "Time spent reading variables.")
+	h.bindDur.write(&b, "envvar_bind_duration_seconds", "Time spent in Bind calls.")
+	h.mu.Unlock()
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// histogram accumulates observations into Buckets.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	n      uint64
+}
+
+// newHistogram returns a histogram over Buckets.
+func newHistogram() histogram {
+	return histogram{counts: make([]uint64, len(Buckets))}
+}
+
+// observe records d.
+func (hg *histogram) observe(d time.Duration) {
+	s := d.Seconds()
+	for i, ub := range Buckets {
+		if s <= ub {
+			hg.counts[i]++
+		}
+	}
+	hg.sum += s
+	hg.n++
+}
+
+// write renders hg in the text format.
+func (hg *histogram) write(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, ub := range Buckets {
+		fmt.Fprintf(b, "%s_bucket{le=%q} %d\n", name, formatFloat(ub), hg.counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{le=\"+Inf\"} %d\n", name, hg.n)
+	fmt.Fprintf(b, "%s_sum %s\n%s_count %d\n", name, formatFloat(hg.sum), name, hg.n)
+}
+
+// counter renders an unlabeled counter.
+func counter(b *strings.Builder, name, help string, v uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, v)
+}
+
+// labeled renders a metric with one label, in label order.
+func labeled[V uint64 | int](b *strings.Builder, name, typ, help, label string, vals map[string]V) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s{%s=\"%s\"} %d\n", name, label, escapeLabel(k), vals[k])
+	}
+}
+
+// escapeLabel escapes a label value per the text format.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatFloat renders f the way Prometheus expects.
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// copyMap returns a copy of m.
+func copyMap[V any](m map[string]V) map[string]V {
+	out := make(map[string]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aatuh/envvar/v2/binders"
+	"github.com/aatuh/envvar/v2/getters"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+func TestHookCounts(t *testing.T) {
+	t.Setenv("MET_PORT", "8080")
+	t.Setenv("MET_BAD", "x")
+	t.Setenv("MET_LEVEL", "trace")
+	m := New()
+	types.SetHook(m)
+	defer types.SetHook(nil)
+
+	getters.GetInt("MET_PORT")
+	getters.GetInt("MET_BAD")
+	getters.GetIntOr("MET_UNSET", 1)
+	types.CallOnLoad("app.env", 3)
+	var cfg struct {
+		Level string `env:"MET_LEVEL" validate:"oneof=debug|info"`
+	}
+	if err := binders.Bind(&cfg); err == nil {
+		t.Fatal("want validation error")
+	}
+
+	s := m.Snapshot()
+	if s.Gets != 3 || s.Misses != 1 || s.ParseErrors != 1 || s.DefaultsUsed != 1 {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if s.Binds != 1 || s.BindErrors != 1 || s.ValidationErrors["oneof"] != 1 {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if s.Loads["app.env"] != 1 || s.LoadedKeys["app.env"] != 3 {
+		t.Fatalf("snapshot = %+v", s)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE envvar_gets_total counter\nenvvar_gets_total 3\n",
+		"envvar_parse_errors_total 1\n",
+		`envvar_validation_errors_total{rule="oneof"} 1`,
+		`envvar_loaded_keys{source="app.env"} 3`,
+		`envvar_get_duration_seconds_bucket{le="+Inf"} 3`,
+		"envvar_bind_duration_seconds_count 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %q in:\n%s", want, body)
+		}
+	}
+
+	m.Publish("envvar_test")
+	var got Snapshot
+	if err := json.Unmarshal([]byte(expvar.Get("envvar_test").String()), &got); err != nil || got.Gets != 3 {
+		t.Fatalf("expvar = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package policy evaluates value constraints over a whole environment,
+// independent of struct binding. Violations from all policies are
+// aggregated into a single error.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+// Policy is a named rule evaluated over an environment.
+type Policy struct {
+	// Name identifies the policy in violations.
+	Name string
+	// Check returns nil when env complies. Returning Violations reports
+	// several findings at once; any other error becomes one violation.
+	Check func(env sources.Source) error
+}
+
+// Violation is a single policy finding.
+type Violation struct {
+	// Policy is the name of the violated policy.
+	Policy string
+	// Key is the offending variable, if any.
+	Key string
+	// Msg describes the violation.
+	Msg string
+}
+
+// Error returns the violation message.
+//
+// Returns:
+//   - string: The error message.
+func (v Violation) Error() string {
+	var b strings.Builder
+	b.WriteString("envvar: policy ")
+	b.WriteString(v.Policy)
+	if v.Key != "" {
+		b.WriteString(": ")
+		b.WriteString(v.Key)
+	}
+	if v.Msg != "" {
+		b.WriteString(": ")
+		b.WriteString(v.Msg)
+	}
+	return b.String()
+}
+
+// Violations aggregates policy findings.
+type Violations []Violation
+
+// Error returns the aggregated message.
+//
+// Returns:
+//   - string: The error message.
+func (vs Violations) Error() string {
+	var b strings.Builder
+	b.WriteString("envvar: policy violations:")
+	for _, v := range vs {
+		b.WriteString("\n  - ")
+		b.WriteString(v.Error())
+	}
+	return b.String()
+}
+
+// Evaluate runs every policy against env and returns Violations, or nil
+// when all pass.
+//
+// Parameters:
+//   - env: The environment to check.
+//   - policies: The policies.
+//
+// Returns:
+//   - error: The aggregated violations, or nil.
+func Evaluate(env sources.Source, policies ...Policy) error {
+	var out Violations
+	for _, p := range policies {
+		err := p.Check(env)
+		if err == nil {
+			continue
+		}
+		var vs Violations
+		var v Violation
+		switch {
+		case errors.As(err, &vs):
+			for _, x := range vs {
+				if x.Policy == "" {
+					x.Policy = p.Name
+				}
+				out = append(out, x)
+			}
+		case errors.As(err, &v):
+			if v.Policy == "" {
+				v.Policy = p.Name
+			}
+			out = append(out, v)
+		default:
+			out = append(out, Violation{Policy: p.Name, Msg: err.Error()})
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	return nil
+}
+
+// When applies p only if key currently equals value (case-insensitive),
+// e.g. When("APP_ENV", "production", ...).
+//
+// Parameters:
+//   - key: The condition key.
+//   - value: The value that activates p.
+//   - p: The conditional policy.
+//
+// Returns:
+//   - Policy: The conditional policy.
+func When(key, value string, p Policy) Policy {
+	return Policy{
+		Name: p.Name + " when " + key + "=" + value,
+		Check: func(env sources.Source) error {
+			if v, ok := env.Lookup(key); ok &&
+				strings.EqualFold(strings.TrimSpace(v), value) {
+				return p.Check(env)
+			}
+			return nil
+		},
+	}
+}
+
+// IntRange requires key, if present, to be an integer in [min, max].
+//
+// Parameters:
+//   - key: The key.
+//   - min: The inclusive lower bound.
+//   - max: The inclusive upper bound.
+//
+// Returns:
+//   - Policy: The policy.
+func IntRange(key string, min, max int64) Policy {
+	return Policy{
+		Name: key + " in range",
+		Check: func(env sources.Source) error {
+			v, ok := env.Lookup(key)
+			if !ok {
+				return nil
+			}
+			i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return Violation{Key: key, Msg: "not an integer: " + v}
+			}
+			if i < min || i > max {
+				return Violation{Key: key,
+					Msg: fmt.Sprintf("%d not in [%d, %d]", i, min, max)}
+			}
+			return nil
+		},
+	}
+}
+
+// BoolIs requires key, if present, to parse as the boolean want.
+//
+// Parameters:
+//   - key: The key.
+//   - want: The required value.
+//
+// Returns:
+//   - Policy: The policy.
+func BoolIs(key string, want bool) Policy {
+	return Policy{
+		Name: key + " is " + strconv.FormatBool(want),
+		Check: func(env sources.Source) error {
+			v, ok := env.Lookup(key)
+			if !ok {
+				return nil
+			}
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "1", "t", "true", "y", "yes", "on":
+				if want {
+					return nil
+				}
+			case "0", "f", "false", "n", "no", "off":
+				if !want {
+					return nil
+				}
+			}
+			return Violation{Key: key, Msg: "must be " +
+				strconv.FormatBool(want) + ", got " + v}
+		},
+	}
+}
+
+// Forbid reports every listable key matching keyPattern (a path.Match
+// glob, "*" for all) whose value matches valueRe.
+//
+// Parameters:
+//   - name: The policy name.
+//   - keyPattern: The key glob.
+//   - valueRe: The forbidden value pattern.
+//
+// Returns:
+//   - Policy: The policy.
+func Forbid(name, keyPattern string, valueRe *regexp.Regexp) Policy {
+	return Policy{
+		Name: name,
+		Check: func(env sources.Source) error {
+			keys := sources.SortedKeys(env)
+			var vs Violations
+			for _, k := range keys {
+				if ok, _ := path.Match(keyPattern, k); !ok {
+					continue
+				}
+				if v, ok := env.Lookup(k); ok && valueRe.MatchString(v) {
+					vs = append(vs, Violation{Key: k, Msg: "forbidden value"})
+				}
+			}
+			if len(vs) > 0 {
+				return vs
+			}
+			return nil
+		},
+	}
+}
+
+// awsKeyRe matches AWS access key IDs.
+var awsKeyRe = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+
+// NoPlaintextAWSKeys forbids plaintext AWS access key IDs in any value.
+//
+// Returns:
+//   - Policy: The policy.
+func NoPlaintextAWSKeys() Policy {
+	return Forbid("no plaintext AWS keys", "*", awsKeyRe)
+}
@@ -0,0 +1,111 @@
+// Package rego adapts Open Policy Agent (Rego) evaluation to envvar
+// policies without depending on OPA. Callers wrap a prepared query,
+// typically:
+//
+//	q, _ := rego.New(rego.Query("data.envvar.deny"), rego.Module(...)).
+//		PrepareForEval(ctx)
+//	eval := envrego.EvaluatorFunc(func(ctx context.Context, in any) ([]string, error) {
+//		rs, err := q.Eval(ctx, rego.EvalInput(in))
+//		// collect the deny messages from rs
+//	})
+//
+// The policy receives the input document described by Input.
+package rego
+
+import (
+	"context"
+
+	"github.com/aatuh/envvar/v2/policy"
+	"github.com/aatuh/envvar/v2/redact"
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+// Evaluator evaluates a Rego query and returns its deny messages.
+type Evaluator interface {
+	// Eval evaluates input and returns one message per denial.
+	Eval(ctx context.Context, input any) ([]string, error)
+}
+
+// EvaluatorFunc adapts a function to Evaluator.
+type EvaluatorFunc func(ctx context.Context, input any) ([]string, error)
+
+// Eval calls f.
+func (f EvaluatorFunc) Eval(ctx context.Context, input any) ([]string, error) {
+	return f(ctx, input)
+}
+
+// Input is the document passed to Rego as input.
+type Input struct {
+	// Env maps variable names to values. Secret-like values are
+	// replaced with redact.Mask unless Options.Unredacted is set.
+	Env map[string]string `json:"env"`
+	// Redacted lists the keys whose values were masked, sorted.
+	Redacted []string `json:"redacted"`
+}
+
+// Options configures Policy.
+type Options struct {
+	// Context bounds evaluation. Defaults to context.Background().
+	Context context.Context
+	// Unredacted passes secret values through as-is. Only enable this
+	// for policies that must inspect secret contents.
+	Unredacted bool
+}
+
+// Policy returns a policy.Policy that evaluates the listable keys of
+// the environment with eval. Each deny message becomes a violation.
+//
+// Parameters:
+//   - name: The policy name.
+//   - eval: The Rego evaluator.
+//   - opts: The options.
+//
+// Returns:
+//   - policy.Policy: The policy.
+func Policy(name string, eval Evaluator, opts Options) policy.Policy {
+	return policy.Policy{
+		Name: name,
+		Check: func(env sources.Source) error {
+			ctx := opts.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			msgs, err := eval.Eval(ctx, BuildInput(env, opts.Unredacted))
+			if err != nil {
+				return err
+			}
+			if len(msgs) == 0 {
+				return nil
+			}
+			vs := make(policy.Violations, 0, len(msgs))
+			for _, m := range msgs {
+				vs = append(vs, policy.Violation{Msg: m})
+			}
+			return vs
+		},
+	}
+}
+
+// BuildInput builds the Rego input document for env.
+//
+// Parameters:
+//   - env: The environment.
+//   - unredacted: Whether to keep secret values.
+//
+// Returns:
+//   - Input: The input document.
+func BuildInput(env sources.Source, unredacted bool) Input {
+	in := Input{Env: map[string]string{}, Redacted: []string{}}
+	for _, k := range sources.SortedKeys(env) {
+		v, ok := env.Lookup(k)
+		if !ok {
+			continue
+		}
+		if !unredacted && redact.IsSecretKey(k) {
+			v = redact.Mask
+			in.Redacted = append(in.Redacted, k)
+		}
+		in.Env[k] = v
+	}
+	return in
+}
@@ -0,0 +1,30 @@
+package rego
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aatuh/envvar/v2/policy"
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+func TestRegoPolicy(t *testing.T) {
+	var seen Input
+	eval := EvaluatorFunc(func(_ context.Context, in any) ([]string, error) {
+		seen = in.(Input)
+		if seen.Env["LOG_LEVEL"] == "debug" {
+			return []string{"LOG_LEVEL must not be debug"}, nil
+		}
+		return nil, nil
+	})
+	env := sources.Map{"LOG_LEVEL": "debug", "DB_PASSWORD": "pw"}
+	err := policy.Evaluate(env, Policy("org", eval, Options{}))
+	var vs policy.Violations
+	if !errors.As(err, &vs) || len(vs) != 1 || vs[0].Policy != "org" {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if seen.Env["DB_PASSWORD"] != "***" || len(seen.Redacted) != 1 {
+		t.Fatalf("input not redacted: %+v", seen)
+	}
+}
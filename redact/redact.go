@@ -0,0 +1,82 @@
+// Package redact holds the secret detection heuristics shared by dumps,
+// reports, and policy adapters.
+package redact
+
+import (
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted values.
+const Mask = "***"
+
+var (
+	// markedMu protects marked.
+	markedMu sync.RWMutex
+	// marked holds keys declared secret explicitly.
+	marked = map[string]struct{}{}
+)
+
+// Reset forgets the keys declared with MarkSecret and the values
+// registered with MarkSecretValue. Meant for tests; see
+// envvartest.ResetGlobalState.
+func Reset() {
+	markedMu.Lock()
+	marked = map[string]struct{}{}
+	markedMu.Unlock()
+	valuesMu.Lock()
+	values = map[string]struct{}{}
+	valuesGen++
+	valuesMu.Unlock()
+}
+
+// MarkSecret declares keys sensitive regardless of their names. Bind
+// marks the keys of fields tagged `secret:"true"`.
+//
+// Parameters:
+//   - keys: The variable names.
+func MarkSecret(keys ...string) {
+	markedMu.Lock()
+	defer markedMu.Unlock()
+	for _, k := range keys {
+		marked[k] = struct{}{}
+	}
+}
+
+// IsSecretKey reports whether key is sensitive: it was passed to
+// MarkSecret, or it contains "SECRET", "TOKEN", or "PASSWORD", or ends
+// with "_KEY" (case-insensitive).
+//
+// Parameters:
+//   - key: The variable name.
+//
+// Returns:
+//   - bool: True if the value should be redacted.
+func IsSecretKey(key string) bool {
+	markedMu.RLock()
+	_, ok := marked[key]
+	markedMu.RUnlock()
+	if ok {
+		return true
+	}
+	upper := strings.ToUpper(key)
+	return strings.Contains(upper, "SECRET") ||
+		strings.Contains(upper, "TOKEN") ||
+		strings.Contains(upper, "PASSWORD") ||
+		strings.HasSuffix(upper, "_KEY")
+}
+
+// Value returns Mask if key looks sensitive, else v.
+//
+// Parameters:
+//   - key: The variable name.
+//   - v: The value.
+//
+// Returns:
+//   - string: The possibly redacted value.
+func Value(key, v string) string {
+	if IsSecretKey(key) {
+		return Mask
+	}
+	return v
+}
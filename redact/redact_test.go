@@ -0,0 +1,47 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	cases := map[string]bool{
+		"API_SECRET": true, "jwt_token": true, "DB_PASSWORD": true,
+		"PRIVATE_KEY": true, "KEYBOARD": false, "PORT": false,
+	}
+	for k, want := range cases {
+		if got := IsSecretKey(k); got != want {
+			t.Fatalf("IsSecretKey(%q)=%v", k, got)
+		}
+	}
+}
+
+func TestMarkSecret(t *testing.T) {
+	if IsSecretKey("DSN") {
+		t.Fatal("DSN should not look secret by name")
+	}
+	MarkSecret("DSN")
+	if Value("DSN", "postgres://u:p@h") != Mask {
+		t.Fatal("marked key should be masked")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b, "tok-123", "ab")
+	MarkSecretValue("hunter2", "hunter2-long")
+	n, err := w.Write([]byte("auth tok-123 pw=hunter2-long/hunter2 ab\n"))
+	if err != nil || n != 40 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if got := b.String(); got != "auth *** pw=***/*** ab\n" {
+		t.Fatalf("got %q", got)
+	}
+	MarkSecretValue("late-secret")
+	b.Reset()
+	w.Write([]byte("late-secret"))
+	if b.String() != Mask {
+		t.Fatalf("values registered later must be masked: %q", b.String())
+	}
+}
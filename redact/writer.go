@@ -0,0 +1,128 @@
+package redact
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MinSecretLen is the shortest value MarkSecretValue and NewWriter
+// mask. Shorter values, such as "1" or "yes", would mask unrelated
+// output.
+const MinSecretLen = 4
+
+var (
+	// valuesMu protects values and valuesGen.
+	valuesMu sync.RWMutex
+	// values holds secret values registered for writers.
+	values = map[string]struct{}{}
+	// valuesGen counts changes to values so writers rebuild lazily.
+	valuesGen uint64
+)
+
+// MarkSecretValue registers secret values that every writer from
+// NewWriter masks, including writers created earlier. Bind registers
+// the values of fields tagged `secret:"true"` and of secret-like keys.
+// Values shorter than MinSecretLen are ignored.
+//
+// Parameters:
+//   - vals: The secret values.
+func MarkSecretValue(vals ...string) {
+	valuesMu.Lock()
+	defer valuesMu.Unlock()
+	for _, v := range vals {
+		if _, ok := values[v]; !ok && len(v) >= MinSecretLen {
+			values[v] = struct{}{}
+			valuesGen++
+		}
+	}
+}
+
+// Writer masks secret values in everything written through it.
+type Writer struct {
+	w     io.Writer
+	extra []string
+
+	mu  sync.Mutex
+	gen uint64
+	r   *strings.Replacer
+}
+
+// NewWriter returns a writer that replaces every occurrence of the
+// given values and of values registered with MarkSecretValue by Mask
+// before writing to w, e.g. wrapped around a logger's output so tokens
+// in third-party log lines do not leak. Each Write is masked on its
+// own, so a secret split across two writes is not masked; loggers
+// write whole lines.
+//
+// Parameters:
+//   - w: The underlying writer.
+//   - vals: Additional secret values; shorter than MinSecretLen are
+//     ignored.
+//
+// Returns:
+//   - *Writer: The masking writer.
+func NewWriter(w io.Writer, vals ...string) *Writer {
+	sw := &Writer{w: w, gen: ^uint64(0)}
+	for _, v := range vals {
+		if len(v) >= MinSecretLen {
+			sw.extra = append(sw.extra, v)
+		}
+	}
+	return sw
+}
+
+// Write masks secrets in p and writes the result. It reports len(p)
+// on success so callers do not treat the length change as a short
+// write.
+//
+// Parameters:
+//   - p: The data.
+//
+// Returns:
+//   - int: len(p), or 0 on failure.
+//   - error: The error of the underlying writer.
+func (sw *Writer) Write(p []byte) (int, error) {
+	r := sw.replacer()
+	if r == nil {
+		return sw.w.Write(p)
+	}
+	if _, err := io.WriteString(sw.w, r.Replace(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// replacer returns the replacer for the current secret values, or nil
+// when there are none.
+func (sw *Writer) replacer() *strings.Replacer {
+	valuesMu.RLock()
+	defer valuesMu.RUnlock()
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.gen == valuesGen {
+		return sw.r
+	}
+	all := append([]string(nil), sw.extra...)
+	for v := range values {
+		all = append(all, v)
+	}
+	// Longer values first, so a secret containing another is masked
+	// whole.
+	sort.Slice(all, func(i, j int) bool {
+		if len(all[i]) != len(all[j]) {
+			return len(all[i]) > len(all[j])
+		}
+		return all[i] < all[j]
+	})
+	sw.gen, sw.r = valuesGen, nil
+	if len(all) > 0 {
+		pairs := make([]string, 0, 2*len(all))
+		for _, v := range all {
+			pairs = append(pairs, v, Mask)
+		}
+		sw.r = strings.NewReplacer(pairs...)
+	}
+	return sw.r
+}
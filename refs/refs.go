@@ -0,0 +1,132 @@
+// Package refs resolves reference values such as
+// "ref+vault://secret/db#password" or "ref+awsssm:///myapp/db_pass"
+// through registered resolvers, so a plain .env file can point at
+// secret managers instead of holding the secrets themselves.
+package refs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Prefix marks a reference value.
+const Prefix = "ref+"
+
+// Resolver resolves references for one scheme.
+type Resolver interface {
+	// Resolve returns the value referenced by u. The "ref+" prefix has
+	// been stripped, so u.Scheme is e.g. "vault".
+	Resolve(u *url.URL) (string, error)
+}
+
+// ContextResolver is implemented by resolvers that perform remote
+// calls, such as the Vault and SSM sources, to honor deadlines and
+// cancellation. ValueContext prefers it over Resolve.
+type ContextResolver interface {
+	// ResolveContext is like Resolve but stops when ctx is done.
+	ResolveContext(ctx context.Context, u *url.URL) (string, error)
+}
+
+// ResolverFunc adapts a function to Resolver.
+type ResolverFunc func(u *url.URL) (string, error)
+
+// Resolve calls f.
+func (f ResolverFunc) Resolve(u *url.URL) (string, error) {
+	return f(u)
+}
+
+var (
+	// regMu protects registry.
+	regMu sync.RWMutex
+	// registry maps schemes to resolvers.
+	registry = map[string]Resolver{}
+)
+
+// Register installs r for scheme, e.g. "vault" for "ref+vault://...".
+// Passing a nil r removes the registration.
+//
+// Parameters:
+//   - scheme: The scheme without the "ref+" prefix.
+//   - r: The resolver.
+func Register(scheme string, r Resolver) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	if r == nil {
+		delete(registry, scheme)
+		return
+	}
+	registry[scheme] = r
+}
+
+// Reset removes every registered resolver. Meant for tests; see
+// envvartest.ResetGlobalState.
+func Reset() {
+	regMu.Lock()
+	defer regMu.Unlock()
+	registry = map[string]Resolver{}
+}
+
+// IsRef reports whether s uses the reference syntax.
+//
+// Parameters:
+//   - s: The value.
+//
+// Returns:
+//   - bool: True if s starts with Prefix.
+func IsRef(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Value returns s unchanged unless it is a reference, in which case it
+// is resolved through the resolver registered for its scheme.
+//
+// Parameters:
+//   - s: The value.
+//
+// Returns:
+//   - string: The resolved value.
+//   - error: The error if the reference is malformed or unresolvable.
+func Value(s string) (string, error) {
+	return ValueContext(context.Background(), s)
+}
+
+// ValueContext is like Value but passes ctx to resolvers implementing
+// ContextResolver, so remote lookups honor deadlines and cancellation.
+// Other resolvers are not called once ctx is done.
+//
+// Parameters:
+//   - ctx: The context.
+//   - s: The value.
+//
+// Returns:
+//   - string: The resolved value.
+//   - error: The error if the reference is malformed, unresolvable, or
+//     ctx is done.
+func ValueContext(ctx context.Context, s string) (string, error) {
+	if !IsRef(s) {
+		return s, nil
+	}
+	u, err := url.Parse(strings.TrimSpace(s[len(Prefix):]))
+	if err != nil || u.Scheme == "" {
+		return "", fmt.Errorf("malformed reference %q", s)
+	}
+	regMu.RLock()
+	r := registry[u.Scheme]
+	regMu.RUnlock()
+	if r == nil {
+		return "", fmt.Errorf("no resolver registered for %q", u.Scheme)
+	}
+	var v string
+	if cr, ok := r.(ContextResolver); ok {
+		v, err = cr.ResolveContext(ctx, u)
+	} else if err = ctx.Err(); err == nil {
+		v, err = r.Resolve(u)
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", u.Redacted(), err)
+	}
+	return v, nil
+}
@@ -0,0 +1,23 @@
+package refs
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	Register("test", ResolverFunc(func(u *url.URL) (string, error) {
+		return u.Host + u.Path + "#" + u.Fragment, nil
+	}))
+	defer Register("test", nil)
+
+	if got, err := Value("ref+test://secret/db#password"); err != nil || got != "secret/db#password" {
+		t.Fatalf("Value: %q %v", got, err)
+	}
+	if got, err := Value("plain"); err != nil || got != "plain" {
+		t.Fatalf("plain passthrough: %q %v", got, err)
+	}
+	if _, err := Value("ref+nope://x"); err == nil {
+		t.Fatalf("expected unregistered scheme error")
+	}
+}
@@ -0,0 +1,208 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// warmupWorkers bounds the concurrent lookups of Warmup.
+const warmupWorkers = 8
+
+// cacheEntry is a memoized lookup.
+type cacheEntry struct {
+	v  string
+	ok bool
+}
+
+// Cached memoizes the lookups of a slow source.
+type Cached struct {
+	src  Source
+	mu   sync.RWMutex
+	vals map[string]cacheEntry
+	// warmedOnly limits the cache to the keys passed to Warmup.
+	warmedOnly bool
+}
+
+// Cache wraps src so each key is looked up at most once until
+// Invalidate is called. Misses are cached too.
+//
+// Parameters:
+//   - src: The source to wrap.
+//
+// Returns:
+//   - *Cached: The caching source.
+func Cache(src Source) *Cached {
+	return &Cached{src: src, vals: map[string]cacheEntry{}}
+}
+
+// WarmCache wraps src like Cache but caches only the keys passed to
+// Warmup; other lookups go to src every time.
+//
+// Parameters:
+//   - src: The source to wrap.
+//
+// Returns:
+//   - *Cached: The caching source.
+func WarmCache(src Source) *Cached {
+	c := Cache(src)
+	c.warmedOnly = true
+	return c
+}
+
+// Lookup returns the cached value, looking it up on first use.
+func (c *Cached) Lookup(key string) (string, bool) {
+	c.mu.RLock()
+	e, hit := c.vals[key]
+	c.mu.RUnlock()
+	if hit {
+		return e.v, e.ok
+	}
+	v, ok := c.src.Lookup(key)
+	if !c.warmedOnly {
+		c.store(key, v, ok)
+	}
+	return v, ok
+}
+
+// Keys delegates to the wrapped source when it implements Lister.
+func (c *Cached) Keys() []string {
+	return KeysOf(c.src)
+}
+
+// Name returns the wrapped source's name.
+func (c *Cached) Name() string {
+	return NameOf(c.src)
+}
+
+// Unwrap returns the wrapped source.
+func (c *Cached) Unwrap() Source {
+	return c.src
+}
+
+// Invalidate drops the given keys from the cache, or every key when
+// none are given.
+//
+// Parameters:
+//   - keys: The keys to drop.
+func (c *Cached) Invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(keys) == 0 {
+		c.vals = map[string]cacheEntry{}
+		return
+	}
+	for _, k := range keys {
+		delete(c.vals, k)
+	}
+}
+
+// InvalidateCaches drops keys, or every key when none are given, from
+// each Cached source in src, so values changed underneath the caches
+// are read again.
+//
+// Parameters:
+//   - src: The root source.
+//   - keys: The keys to drop.
+func InvalidateCaches(src Source, keys ...string) {
+	Walk(src, func(s Source) {
+		if c, ok := s.(*Cached); ok {
+			c.Invalidate(keys...)
+		}
+	})
+}
+
+// Warmup concurrently looks up keys and caches the results, so slow
+// sources are queried before traffic arrives. Values that are secret
+// references ("ref+scheme://...") are resolved too and cached
+// resolved. Each key is reported through the OnGet hook as it
+// completes and the total through OnLoad("warmup", n).
+//
+// Parameters:
+//   - ctx: Bounds the warmup; lookups still running on cancellation
+//     complete in the background.
+//   - keys: The keys to warm.
+//
+// Returns:
+//   - error: ctx.Err() if ctx ends first, else the first reference
+//     resolution error.
+func (c *Cached) Warmup(ctx context.Context, keys ...string) error {
+	work := make(chan string)
+	errs := make(chan error, len(keys))
+	var wg sync.WaitGroup
+	for i := 0; i < min(warmupWorkers, len(keys)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range work {
+				errs <- c.warm(k)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var ctxErr error
+feed:
+	for _, k := range keys {
+		select {
+		case work <- k:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			break feed
+		}
+	}
+	close(work)
+	if ctxErr == nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		}
+	}
+	if ctxErr != nil {
+		return ctxErr
+	}
+	close(errs)
+	warmed := 0
+	var first error
+	for err := range errs {
+		if err != nil && first == nil {
+			first = err
+		}
+		if err == nil {
+			warmed++
+		}
+	}
+	types.CallOnLoad("warmup", warmed)
+	return first
+}
+
+// warm looks up and caches key, resolving references.
+func (c *Cached) warm(key string) error {
+	start := types.Now()
+	v, ok := c.src.Lookup(key)
+	var err error
+	if ok && refs.IsRef(v) {
+		var rv string
+		if rv, err = refs.Value(v); err == nil {
+			v = rv
+		}
+	}
+	if err == nil {
+		c.store(key, v, ok)
+	}
+	types.CallOnGet(key, ok, err, types.Since(start))
+	return err
+}
+
+// store caches a lookup result.
+func (c *Cached) store(key, v string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vals[key] = cacheEntry{v: v, ok: ok}
+}
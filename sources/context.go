@@ -0,0 +1,126 @@
+package sources
+
+import (
+	"context"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// ContextSource is implemented by sources whose lookups can block, such
+// as remote ones, so they honor deadlines and cancellation. The
+// composite sources of this package forward it to their members.
+type ContextSource interface {
+	// LookupContext is like Lookup but stops when ctx is done,
+	// returning its error.
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// LookupContextOf looks up key in src, through LookupContext when src
+// implements ContextSource. Other sources are not consulted once ctx is
+// done.
+//
+// Parameters:
+//   - ctx: The context.
+//   - src: The source.
+//   - key: The key to look up.
+//
+// Returns:
+//   - string: The value.
+//   - bool: The boolean indicating presence.
+//   - error: The context error, if ctx is done.
+func LookupContextOf(ctx context.Context, src Source, key string) (string, bool, error) {
+	if cs, ok := src.(ContextSource); ok {
+		return cs.LookupContext(ctx, key)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	v, ok := src.Lookup(key)
+	return v, ok, nil
+}
+
+// LookupContext resolves key from the default source, honoring ctx.
+//
+// Parameters:
+//   - ctx: The context.
+//   - key: The key to look up.
+//
+// Returns:
+//   - string: The value.
+//   - bool: The boolean indicating presence.
+//   - error: The context error, if ctx is done.
+func LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return LookupContextOf(ctx, Default(), key)
+}
+
+// LookupContext delegates to the wrapped source.
+func (n *named) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return LookupContextOf(ctx, n.src, key)
+}
+
+// LookupContext returns the first value found in the chain, stopping
+// when ctx is done.
+func (c chain) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	for _, s := range c {
+		v, ok, err := LookupContextOf(ctx, s, key)
+		if err != nil || ok {
+			return v, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+// LookupContext reads prefix+key.
+func (v *view) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return LookupContextOf(ctx, v.src, v.prefix+key)
+}
+
+// LookupContext resolves key from the currently effective order.
+func (o *ordered) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return o.current().LookupContext(ctx, key)
+}
+
+// LookupContext resolves key from its responsible source.
+func (r *Router) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	src := r.SourceFor(key)
+	if src == nil {
+		return "", false, nil
+	}
+	return LookupContextOf(ctx, src, key)
+}
+
+// LookupContext delegates to the wrapped source and records the
+// outcome; canceled lookups are not recorded.
+func (m *Metered) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	start := types.Now()
+	v, ok, err := LookupContextOf(ctx, m.src, key)
+	if err != nil {
+		return "", false, err
+	}
+	m.latency.Add(int64(types.Since(start)))
+	if ok {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+	return v, ok, nil
+}
+
+// LookupContext returns the cached value, looking it up on first use.
+// Canceled lookups are not cached.
+func (c *Cached) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	c.mu.RLock()
+	e, hit := c.vals[key]
+	c.mu.RUnlock()
+	if hit {
+		return e.v, e.ok, nil
+	}
+	v, ok, err := LookupContextOf(ctx, c.src, key)
+	if err != nil {
+		return "", false, err
+	}
+	if !c.warmedOnly {
+		c.store(key, v, ok)
+	}
+	return v, ok, nil
+}
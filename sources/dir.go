@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DirSource serves one variable per regular file in a directory, named
+// after the file and valued by its contents. It covers Kubernetes
+// Secret/ConfigMap volume mounts and Docker secrets.
+type DirSource struct {
+	path string
+
+	mu   sync.RWMutex
+	vals map[string]string
+}
+
+// Dir reads every regular file in path. Hidden entries (such as the
+// Kubernetes "..data" bookkeeping links) and subdirectories are skipped,
+// symlinks are followed, and a single trailing newline is trimmed from
+// each value.
+//
+// Parameters:
+//   - path: The directory, e.g. "/var/run/secrets/app".
+//
+// Returns:
+//   - *DirSource: The source.
+//   - error: The error if the directory cannot be read.
+func Dir(path string) (*DirSource, error) {
+	d := &DirSource{path: path}
+	if err := d.Refresh(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Refresh re-reads the directory. The snapshot is replaced only on
+// success, so rotated secrets are picked up atomically.
+//
+// Returns:
+//   - error: The error if the directory or a file cannot be read.
+func (d *DirSource) Refresh() error {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return fmt.Errorf("envvar: secret dir %s: %w", d.path, err)
+	}
+	vals := make(map[string]string, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		p := filepath.Join(d.path, name)
+		info, err := os.Stat(p)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("envvar: secret file %s: %w", p, err)
+		}
+		v := strings.TrimSuffix(string(b), "\n")
+		vals[name] = strings.TrimSuffix(v, "\r")
+	}
+	d.mu.Lock()
+	d.vals = vals
+	d.mu.Unlock()
+	return nil
+}
+
+// Lookup returns the contents of the file named key.
+func (d *DirSource) Lookup(key string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	v, ok := d.vals[key]
+	return v, ok
+}
+
+// Keys returns the file names in sorted order.
+func (d *DirSource) Keys() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	out := make([]string, 0, len(d.vals))
+	for k := range d.vals {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Name returns "dir:<path>".
+func (d *DirSource) Name() string {
+	return "dir:" + d.path
+}
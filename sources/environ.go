@@ -0,0 +1,62 @@
+package sources
+
+import (
+	"strings"
+)
+
+// FromEnviron builds a source from "KEY=VALUE" entries as returned by
+// os.Environ or used in exec.Cmd.Env. Only the first '=' separates the
+// key, so values may contain '='. A leading '=' is kept in the key, as
+// in Windows per-drive entries ("=C:=C:\\"). Entries without '=' are
+// ignored and later duplicates win.
+//
+// Parameters:
+//   - env: The entries.
+//
+// Returns:
+//   - Map: The source.
+func FromEnviron(env []string) Map {
+	m := make(Map, len(env))
+	for _, kv := range env {
+		i := strings.IndexByte(kv, '=')
+		if i == 0 {
+			// Windows-style hidden entry; the key ends at the next '='.
+			if j := strings.IndexByte(kv[1:], '='); j >= 0 {
+				i = j + 1
+			} else {
+				i = -1
+			}
+		}
+		if i < 0 {
+			continue
+		}
+		m[kv[:i]] = kv[i+1:]
+	}
+	return m
+}
+
+// ToEnviron renders the listable keys of src as sorted "KEY=VALUE"
+// entries suitable for exec.Cmd.Env. Keys that are empty or contain '='
+// or NUL, and values containing NUL, cannot be represented and are
+// skipped.
+//
+// Parameters:
+//   - src: The source.
+//
+// Returns:
+//   - []string: The entries.
+func ToEnviron(src Source) []string {
+	keys := SortedKeys(src)
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == "" || strings.ContainsAny(k[1:], "=\x00") || k[0] == 0 {
+			continue
+		}
+		v, ok := src.Lookup(k)
+		if !ok || strings.IndexByte(v, 0) >= 0 {
+			continue
+		}
+		out = append(out, k+"="+v)
+	}
+	return out
+}
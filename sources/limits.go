@@ -0,0 +1,49 @@
+package sources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// CheckLimits checks the listable variables of src against the global
+// types.Limits: the key count, the total size of keys and values, and
+// each value size. Call it at startup to reject pathological
+// environments before any value is decoded.
+//
+// Parameters:
+//   - src: The source, e.g. Default().
+//
+// Returns:
+//   - error: An error wrapping types.ErrLimit listing every violation.
+func CheckLimits(src Source) error {
+	lim := types.CurrentLimits()
+	if lim == (types.Limits{}) {
+		return nil
+	}
+	keys := SortedKeys(src)
+	var msgs []string
+	if lim.MaxKeys > 0 && len(keys) > lim.MaxKeys {
+		msgs = append(msgs, fmt.Sprintf("%d keys, max %d", len(keys), lim.MaxKeys))
+	}
+	total := 0
+	for _, k := range keys {
+		v, ok := src.Lookup(k)
+		if !ok {
+			continue
+		}
+		total += len(k) + len(v) + 1
+		if msg := lim.CheckValue(v); msg != "" {
+			msgs = append(msgs, k+": "+msg)
+		}
+	}
+	if lim.MaxTotalSize > 0 && total > lim.MaxTotalSize {
+		msgs = append(msgs, fmt.Sprintf("total size %d bytes, max %d",
+			total, lim.MaxTotalSize))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", types.ErrLimit, strings.Join(msgs, "; "))
+}
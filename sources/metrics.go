@@ -0,0 +1,153 @@
+package sources
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// Stats are read-through counters for a metered source.
+type Stats struct {
+	// Hits is the number of lookups that found the key.
+	Hits uint64
+	// Misses is the number of lookups that did not find the key.
+	Misses uint64
+	// Latency is the cumulative time spent in lookups.
+	Latency time.Duration
+}
+
+// Lookups returns Hits + Misses.
+func (s Stats) Lookups() uint64 {
+	return s.Hits + s.Misses
+}
+
+// AvgLatency returns the mean lookup latency.
+func (s Stats) AvgLatency() time.Duration {
+	n := s.Lookups()
+	if n == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(n)
+}
+
+// Metered wraps a source and records hits, misses, and latency.
+type Metered struct {
+	src     Source
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	latency atomic.Int64
+}
+
+// WithMetrics wraps src with read-through counters. Wrap each member of
+// a chain individually to see which source actually serves values.
+//
+// Parameters:
+//   - src: The source to wrap.
+//
+// Returns:
+//   - *Metered: The metered source.
+func WithMetrics(src Source) *Metered {
+	return &Metered{src: src}
+}
+
+// Lookup delegates to the wrapped source and records the outcome.
+func (m *Metered) Lookup(key string) (string, bool) {
+	start := types.Now()
+	v, ok := m.src.Lookup(key)
+	m.latency.Add(int64(types.Since(start)))
+	if ok {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+	return v, ok
+}
+
+// Keys delegates to the wrapped source when it implements Lister.
+func (m *Metered) Keys() []string {
+	return KeysOf(m.src)
+}
+
+// Name returns the wrapped source's name.
+func (m *Metered) Name() string {
+	return NameOf(m.src)
+}
+
+// Unwrap returns the wrapped source.
+func (m *Metered) Unwrap() Source {
+	return m.src
+}
+
+// Stats returns a snapshot of the counters.
+func (m *Metered) Stats() Stats {
+	return Stats{
+		Hits:    m.hits.Load(),
+		Misses:  m.misses.Load(),
+		Latency: time.Duration(m.latency.Load()),
+	}
+}
+
+// Reset zeroes the counters.
+func (m *Metered) Reset() {
+	m.hits.Store(0)
+	m.misses.Store(0)
+	m.latency.Store(0)
+}
+
+// CollectStats walks src and its members (chains, routers, named
+// wrappers) and returns the stats of every metered source keyed by
+// source name. Unnamed metered sources are keyed as "".
+//
+// Parameters:
+//   - src: The root source.
+//
+// Returns:
+//   - map[string]Stats: The stats by source name.
+func CollectStats(src Source) map[string]Stats {
+	out := map[string]Stats{}
+	Walk(src, func(s Source) {
+		if m, ok := s.(*Metered); ok {
+			st := m.Stats()
+			prev := out[m.Name()]
+			prev.Hits += st.Hits
+			prev.Misses += st.Misses
+			prev.Latency += st.Latency
+			out[m.Name()] = prev
+		}
+	})
+	return out
+}
+
+// ResetStats zeroes the counters of every metered source in src.
+//
+// Parameters:
+//   - src: The root source.
+func ResetStats(src Source) {
+	Walk(src, func(s Source) {
+		if m, ok := s.(*Metered); ok {
+			m.Reset()
+		}
+	})
+}
+
+// Walk calls fn for src and, recursively, every source it wraps or
+// composes.
+//
+// Parameters:
+//   - src: The root source.
+//   - fn: The visitor.
+func Walk(src Source, fn func(Source)) {
+	if src == nil {
+		return
+	}
+	fn(src)
+	switch s := src.(type) {
+	case interface{ Unwrap() Source }:
+		Walk(s.Unwrap(), fn)
+	case interface{ Members() []Source }:
+		for _, m := range s.Members() {
+			Walk(m, fn)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package sources
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// SourceOrderKey is the process environment variable consulted by
+// OrderedChain, e.g. ENVVAR_SOURCE_ORDER=os,file,vault.
+const SourceOrderKey = "ENVVAR_SOURCE_ORDER"
+
+// ordered is a chain whose precedence can be overridden at runtime.
+type ordered struct {
+	metaKey string
+	srcs    []Source
+	cur     atomic.Pointer[orderState]
+}
+
+// orderState caches the chain computed for a given meta value.
+type orderState struct {
+	raw   string
+	chain chain
+}
+
+// OrderedChain is like Chain but lets operators reorder the named
+// sources through SourceOrderKey without redeploying code. See
+// OrderedChainKey.
+//
+// Parameters:
+//   - srcs: The sources in their configured priority order.
+//
+// Returns:
+//   - Source: The composite source.
+func OrderedChain(srcs ...Source) Source {
+	return OrderedChainKey(SourceOrderKey, srcs...)
+}
+
+// OrderedChainKey is like OrderedChain with a custom meta-variable.
+// The meta value is a comma-separated list of source names (see Named).
+// Listed sources come first in the given order; unlisted sources keep
+// their configured order after them. Unknown names are ignored. The
+// meta-variable is read from the process environment on each lookup.
+//
+// Parameters:
+//   - metaKey: The meta-variable name.
+//   - srcs: The sources in their configured priority order.
+//
+// Returns:
+//   - Source: The composite source.
+func OrderedChainKey(metaKey string, srcs ...Source) Source {
+	return &ordered{metaKey: metaKey, srcs: append([]Source(nil), srcs...)}
+}
+
+// Lookup resolves key from the currently effective order.
+func (o *ordered) Lookup(key string) (string, bool) {
+	return o.current().Lookup(key)
+}
+
+// Keys returns the union of all listable keys.
+func (o *ordered) Keys() []string {
+	return o.current().Keys()
+}
+
+// Name returns "chain".
+func (o *ordered) Name() string {
+	return "chain"
+}
+
+// Members returns the sources in effective order.
+func (o *ordered) Members() []Source {
+	return o.current().Members()
+}
+
+// Order returns the names of the sources in effective order.
+func (o *ordered) Order() []string {
+	c := o.current()
+	out := make([]string, len(c))
+	for i, s := range c {
+		out[i] = NameOf(s)
+	}
+	return out
+}
+
+// current returns the chain for the present meta value.
+func (o *ordered) current() chain {
+	raw := os.Getenv(o.metaKey)
+	if st := o.cur.Load(); st != nil && st.raw == raw {
+		return st.chain
+	}
+	st := &orderState{raw: raw, chain: reorder(o.srcs, raw)}
+	o.cur.Store(st)
+	return st.chain
+}
+
+// reorder applies a comma-separated name list to srcs.
+func reorder(srcs []Source, raw string) chain {
+	out := make(chain, 0, len(srcs))
+	used := make([]bool, len(srcs))
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		for i, s := range srcs {
+			if !used[i] && NameOf(s) == name {
+				out = append(out, s)
+				used[i] = true
+				break
+			}
+		}
+	}
+	for i, s := range srcs {
+		if !used[i] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package sources
+
+// Origin returns the name of the source within src that supplies key:
+// routers descend into the responsible source, composites into the
+// first member holding key, and anonymous wrappers into the wrapped
+// source. Named sources are reported by their own name. It returns ""
+// when key is absent.
+//
+// Parameters:
+//   - src: The source.
+//   - key: The key.
+//
+// Returns:
+//   - string: The supplying source name.
+func Origin(src Source, key string) string {
+	if src == nil {
+		return ""
+	}
+	if _, ok := src.Lookup(key); !ok {
+		return ""
+	}
+	switch s := src.(type) {
+	case *named:
+		return s.name
+	case interface{ SourceFor(string) Source }:
+		return Origin(s.SourceFor(key), key)
+	case interface{ Members() []Source }:
+		for _, m := range s.Members() {
+			if _, ok := m.Lookup(key); ok {
+				if name := Origin(m, key); name != "" {
+					return name
+				}
+				break
+			}
+		}
+	case interface{ Unwrap() Source }:
+		if name := Origin(s.Unwrap(), key); name != "" {
+			return name
+		}
+	}
+	return NameOf(src)
+}
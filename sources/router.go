@@ -0,0 +1,136 @@
+package sources
+
+import (
+	"fmt"
+	"path"
+)
+
+// Route directs keys matching Pattern to Source exclusively.
+type Route struct {
+	// Pattern is a path.Match glob over the key, e.g. "*_SECRET".
+	Pattern string
+	// Source is the only source consulted for matching keys.
+	Source Source
+}
+
+// Router resolves each key from the source of the first matching route.
+// Keys that match no route are resolved from the fallback source.
+// A matching key is never looked up anywhere else, so secrets cannot be
+// accepted from a less-trusted source by accident.
+type Router struct {
+	routes   []Route
+	fallback Source
+}
+
+// NewRouter builds a router. Routes are evaluated in order.
+//
+// Parameters:
+//   - fallback: The source for keys matching no route. Nil disables it.
+//   - routes: The routing rules.
+//
+// Returns:
+//   - *Router: The router.
+//   - error: The error if a pattern is malformed or a source is nil.
+func NewRouter(fallback Source, routes ...Route) (*Router, error) {
+	for _, r := range routes {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("envvar: invalid route pattern %q: %w",
+				r.Pattern, err)
+		}
+		if r.Source == nil {
+			return nil, fmt.Errorf("envvar: route %q has nil source",
+				r.Pattern)
+		}
+	}
+	return &Router{
+		routes:   append([]Route(nil), routes...),
+		fallback: fallback,
+	}, nil
+}
+
+// MustNewRouter is like NewRouter but panics on error.
+//
+// Parameters:
+//   - fallback: The source for keys matching no route.
+//   - routes: The routing rules.
+//
+// Returns:
+//   - *Router: The router.
+func MustNewRouter(fallback Source, routes ...Route) *Router {
+	r, err := NewRouter(fallback, routes...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// SourceFor returns the source responsible for key.
+//
+// Parameters:
+//   - key: The key.
+//
+// Returns:
+//   - Source: The responsible source, or nil when none applies.
+func (r *Router) SourceFor(key string) Source {
+	if i := r.match(key); i >= 0 {
+		return r.routes[i].Source
+	}
+	return r.fallback
+}
+
+// match returns the index of the first route matching key, or -1.
+func (r *Router) match(key string) int {
+	for i, rt := range r.routes {
+		if ok, _ := path.Match(rt.Pattern, key); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lookup resolves key from its responsible source.
+func (r *Router) Lookup(key string) (string, bool) {
+	src := r.SourceFor(key)
+	if src == nil {
+		return "", false
+	}
+	return src.Lookup(key)
+}
+
+// Keys returns every listable key that would be resolved by its own
+// responsible source.
+func (r *Router) Keys() []string {
+	seen := map[string]struct{}{}
+	for i, rt := range r.routes {
+		for _, k := range KeysOf(rt.Source) {
+			if r.match(k) == i {
+				seen[k] = struct{}{}
+			}
+		}
+	}
+	if r.fallback != nil {
+		for _, k := range KeysOf(r.fallback) {
+			if r.match(k) < 0 {
+				seen[k] = struct{}{}
+			}
+		}
+	}
+	return setKeys(seen)
+}
+
+// Members returns the route sources followed by the fallback.
+func (r *Router) Members() []Source {
+	out := make([]Source, 0, len(r.routes)+1)
+	for _, rt := range r.routes {
+		out = append(out, rt.Source)
+	}
+	if r.fallback != nil {
+		out = append(out, r.fallback)
+	}
+	return out
+}
+
+// Name returns "router".
+func (r *Router) Name() string {
+	return "router"
+}
@@ -0,0 +1,290 @@
+package sources
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Source resolves raw values by key. Implementations must be safe for
+// concurrent use.
+type Source interface {
+	// Lookup returns the value for key and whether it was present.
+	Lookup(key string) (string, bool)
+}
+
+// Lister is implemented by sources that can enumerate their keys.
+type Lister interface {
+	// Keys returns the keys known to the source.
+	Keys() []string
+}
+
+// Namer is implemented by sources that carry a descriptive name.
+type Namer interface {
+	// Name returns the source name, e.g. "os" or "vault".
+	Name() string
+}
+
+var (
+	// defaultMu protects defaultSrc.
+	defaultMu sync.RWMutex
+	// defaultSrc is the source used by the package-level getters and
+	// binders. Nil means the process environment.
+	defaultSrc Source
+)
+
+// SetDefault installs the source used by package-level getters and
+// binders. Passing nil restores the process environment.
+//
+// Parameters:
+//   - src: The source to install.
+func SetDefault(src Source) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultSrc = src
+}
+
+// Default returns the source used by package-level getters and binders.
+//
+// Returns:
+//   - Source: The installed source or the process environment.
+func Default() Source {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultSrc == nil {
+		return OS()
+	}
+	return defaultSrc
+}
+
+// Lookup resolves key from the default source.
+//
+// Parameters:
+//   - key: The key to look up.
+//
+// Returns:
+//   - string: The value.
+//   - bool: The boolean indicating presence.
+func Lookup(key string) (string, bool) {
+	return Default().Lookup(key)
+}
+
+// LookupOrEnv resolves key from the default source, falling back to
+// the process environment when the source does not have it. Expansion
+// uses it so ${NAME} references see values that were loaded but not
+// exported.
+//
+// Parameters:
+//   - key: The key to look up.
+//
+// Returns:
+//   - string: The value.
+//   - bool: Whether the key was found.
+func LookupOrEnv(key string) (string, bool) {
+	if v, ok := Lookup(key); ok {
+		return v, true
+	}
+	return os.LookupEnv(key)
+}
+
+// NameOf returns the name of src if it implements Namer, else "".
+//
+// Parameters:
+//   - src: The source.
+//
+// Returns:
+//   - string: The source name.
+func NameOf(src Source) string {
+	if n, ok := src.(Namer); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// osSource reads from the process environment.
+type osSource struct{}
+
+// OS returns a source backed by the process environment.
+//
+// Returns:
+//   - Source: The process environment source.
+func OS() Source {
+	return osSource{}
+}
+
+// Lookup returns the process environment value for key.
+func (osSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Keys returns the sorted names of all process environment variables.
+func (osSource) Keys() []string {
+	env := os.Environ()
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		if k, _, ok := strings.Cut(kv, "="); ok && k != "" {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Name returns "os".
+func (osSource) Name() string {
+	return "os"
+}
+
+// Map is a source backed by a static map. The map must not be mutated
+// after it has been handed to readers.
+type Map map[string]string
+
+// Lookup returns the map value for key.
+func (m Map) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Keys returns the sorted map keys.
+func (m Map) Keys() []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Name returns "map".
+func (Map) Name() string {
+	return "map"
+}
+
+// named wraps a source with an explicit name.
+type named struct {
+	name string
+	src  Source
+}
+
+// Named attaches a name to src so it can be referred to by routing,
+// ordering, and reporting helpers.
+//
+// Parameters:
+//   - name: The name.
+//   - src: The source to wrap.
+//
+// Returns:
+//   - Source: The named source.
+func Named(name string, src Source) Source {
+	return &named{name: name, src: src}
+}
+
+// Lookup delegates to the wrapped source.
+func (n *named) Lookup(key string) (string, bool) {
+	return n.src.Lookup(key)
+}
+
+// Keys delegates to the wrapped source when it implements Lister.
+func (n *named) Keys() []string {
+	return KeysOf(n.src)
+}
+
+// Name returns the attached name.
+func (n *named) Name() string {
+	return n.name
+}
+
+// Unwrap returns the wrapped source.
+func (n *named) Unwrap() Source {
+	return n.src
+}
+
+// chain resolves keys from the first source that has them.
+type chain []Source
+
+// Chain returns a source that consults srcs in order and returns the
+// first hit.
+//
+// Parameters:
+//   - srcs: The sources in priority order.
+//
+// Returns:
+//   - Source: The composite source.
+func Chain(srcs ...Source) Source {
+	return chain(append([]Source(nil), srcs...))
+}
+
+// Lookup returns the first value found in the chain.
+func (c chain) Lookup(key string) (string, bool) {
+	for _, s := range c {
+		if v, ok := s.Lookup(key); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the union of keys of all listable sources in the chain.
+func (c chain) Keys() []string {
+	seen := map[string]struct{}{}
+	for _, s := range c {
+		for _, k := range KeysOf(s) {
+			seen[k] = struct{}{}
+		}
+	}
+	return setKeys(seen)
+}
+
+// Name returns "chain".
+func (chain) Name() string {
+	return "chain"
+}
+
+// Members returns the chained sources in priority order.
+func (c chain) Members() []Source {
+	return append([]Source(nil), c...)
+}
+
+// KeysOf returns the keys of src if it implements Lister, else nil.
+//
+// Parameters:
+//   - src: The source.
+//
+// Returns:
+//   - []string: The keys.
+func KeysOf(src Source) []string {
+	if l, ok := src.(Lister); ok {
+		return l.Keys()
+	}
+	return nil
+}
+
+// SortedKeys returns the keys of src in sorted order, or nil if src
+// does not implement Lister. Use it instead of KeysOf when output must
+// be reproducible, since third-party listers need not sort.
+//
+// Parameters:
+//   - src: The source.
+//
+// Returns:
+//   - []string: The sorted keys.
+func SortedKeys(src Source) []string {
+	keys := KeysOf(src)
+	if keys == nil {
+		return nil
+	}
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+	return keys
+}
+
+// setKeys returns the sorted keys of a set.
+func setKeys(m map[string]struct{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,292 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatuh/envvar/v2/refs"
+	"github.com/aatuh/envvar/v2/types"
+)
+
+func TestChainAndNamed(t *testing.T) {
+	a := Named("a", Map{"X": "1"})
+	b := Named("b", Map{"X": "2", "Y": "3"})
+	c := Chain(a, b)
+	if v, ok := c.Lookup("X"); !ok || v != "1" {
+		t.Fatalf("chain X: %q %v", v, ok)
+	}
+	if v, ok := c.Lookup("Y"); !ok || v != "3" {
+		t.Fatalf("chain Y: %q %v", v, ok)
+	}
+	if got := KeysOf(c); !reflect.DeepEqual(got, []string{"X", "Y"}) {
+		t.Fatalf("chain keys: %v", got)
+	}
+	if NameOf(a) != "a" {
+		t.Fatalf("name: %q", NameOf(a))
+	}
+	m := Map{"C": "", "A": "", "B": ""}
+	if got := SortedKeys(m); !reflect.DeepEqual(got, []string{"A", "B", "C"}) {
+		t.Fatalf("sorted keys: %v", got)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	vault := Map{"DB_SECRET": "vault"}
+	env := Map{"DB_SECRET": "env", "PORT": "8080", "API_SECRET": "leak"}
+	r, err := NewRouter(env, Route{Pattern: "*_SECRET", Source: vault})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := r.Lookup("DB_SECRET"); v != "vault" {
+		t.Fatalf("DB_SECRET: %q", v)
+	}
+	if _, ok := r.Lookup("API_SECRET"); ok {
+		t.Fatalf("API_SECRET must not fall back to env")
+	}
+	if v, _ := r.Lookup("PORT"); v != "8080" {
+		t.Fatalf("PORT: %q", v)
+	}
+	if got := r.Keys(); !reflect.DeepEqual(got, []string{"DB_SECRET", "PORT"}) {
+		t.Fatalf("router keys: %v", got)
+	}
+	if _, err := NewRouter(env, Route{Pattern: "[", Source: vault}); err == nil {
+		t.Fatalf("expected bad pattern error")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	SetDefault(Map{"ONLY_HERE": "yes"})
+	defer SetDefault(nil)
+	if v, ok := Lookup("ONLY_HERE"); !ok || v != "yes" {
+		t.Fatalf("default lookup: %q %v", v, ok)
+	}
+}
+
+func TestOrderedChain(t *testing.T) {
+	c := OrderedChain(
+		Named("os", Map{"K": "os"}),
+		Named("file", Map{"K": "file"}),
+		Named("vault", Map{"K": "vault"}),
+	)
+	if v, _ := c.Lookup("K"); v != "os" {
+		t.Fatalf("default order: %q", v)
+	}
+	t.Setenv(SourceOrderKey, "vault, file")
+	if v, _ := c.Lookup("K"); v != "vault" {
+		t.Fatalf("reordered: %q", v)
+	}
+	got := c.(interface{ Order() []string }).Order()
+	if !reflect.DeepEqual(got, []string{"vault", "file", "os"}) {
+		t.Fatalf("order: %v", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	remote := WithMetrics(Named("remote", Map{"A": "1"}))
+	local := WithMetrics(Named("os", Map{"B": "2"}))
+	c := Chain(remote, local)
+	c.Lookup("A")
+	c.Lookup("B")
+	c.Lookup("C")
+
+	st := CollectStats(c)
+	if got := st["remote"]; got.Hits != 1 || got.Misses != 2 {
+		t.Fatalf("remote stats: %+v", got)
+	}
+	if got := st["os"]; got.Hits != 1 || got.Misses != 1 {
+		t.Fatalf("os stats: %+v", got)
+	}
+	remote.Reset()
+	if remote.Stats().Lookups() != 0 {
+		t.Fatalf("reset failed")
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..data")
+	if err := os.Mkdir(data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "DB_PASSWORD"), []byte("pw\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "DB_PASSWORD"), filepath.Join(dir, "DB_PASSWORD")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "API_TOKEN"), []byte("tok"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := d.Lookup("DB_PASSWORD"); v != "pw" {
+		t.Fatalf("DB_PASSWORD: %q", v)
+	}
+	if got := d.Keys(); !reflect.DeepEqual(got, []string{"API_TOKEN", "DB_PASSWORD"}) {
+		t.Fatalf("keys: %v", got)
+	}
+	if _, err := Dir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("expected error for missing dir")
+	}
+}
+
+func TestEnvironRoundTrip(t *testing.T) {
+	in := []string{"A=1", "DSN=postgres://h/db?sslmode=disable", "=C:=C:\\x", "BROKEN", "A=2"}
+	m := FromEnviron(in)
+	if m["A"] != "2" || m["DSN"] != "postgres://h/db?sslmode=disable" || m["=C:"] != "C:\\x" {
+		t.Fatalf("FromEnviron: %#v", m)
+	}
+	if _, ok := m["BROKEN"]; ok {
+		t.Fatalf("entry without '=' must be skipped")
+	}
+	got := ToEnviron(m)
+	want := []string{"=C:=C:\\x", "A=2", "DSN=postgres://h/db?sslmode=disable"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ToEnviron: %v", got)
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	types.SetLimits(types.Limits{MaxValueSize: 4, MaxKeys: 2})
+	defer types.SetLimits(types.Limits{})
+	if err := CheckLimits(Map{"A": "1234", "B": "x"}); err != nil {
+		t.Fatalf("CheckLimits: %v", err)
+	}
+	err := CheckLimits(Map{"A": "12345", "B": "x", "C": "y"})
+	if !errors.Is(err, types.ErrLimit) ||
+		!strings.Contains(err.Error(), "3 keys") || !strings.Contains(err.Error(), "A: value is 5 bytes") {
+		t.Fatalf("CheckLimits = %v", err)
+	}
+}
+
+// countingSource counts lookups.
+type countingSource struct {
+	Map
+	mu sync.Mutex
+	n  int
+}
+
+func (c *countingSource) Lookup(key string) (string, bool) {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+	return c.Map.Lookup(key)
+}
+
+func TestCacheWarmup(t *testing.T) {
+	refs.Register("warm", refs.ResolverFunc(func(u *url.URL) (string, error) {
+		return "resolved-" + u.Host, nil
+	}))
+	defer refs.Register("warm", nil)
+	src := &countingSource{Map: Map{"A": "1", "B": "ref+warm://b"}}
+	c := Cache(src)
+	if err := c.Warmup(context.Background(), "A", "B", "MISSING"); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if src.n != 3 {
+		t.Fatalf("lookups = %d", src.n)
+	}
+	if v, ok := c.Lookup("B"); !ok || v != "resolved-b" {
+		t.Fatalf("B = %q, %v", v, ok)
+	}
+	if _, ok := c.Lookup("MISSING"); ok || src.n != 3 {
+		t.Fatalf("miss not cached, lookups = %d", src.n)
+	}
+	c.Invalidate("A")
+	c.Lookup("A")
+	if src.n != 4 {
+		t.Fatalf("invalidate: lookups = %d", src.n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Cache(src).Warmup(ctx, "A", "B"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestWarmCache(t *testing.T) {
+	src := &countingSource{Map: Map{"A": "1", "B": "2"}}
+	c := WarmCache(src)
+	if err := c.Warmup(context.Background(), "A"); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	c.Lookup("A")
+	c.Lookup("B")
+	c.LookupContext(context.Background(), "B")
+	if src.n != 3 {
+		t.Fatalf("lookups = %d, want only B read through", src.n)
+	}
+	src.Map["A"] = "changed"
+	InvalidateCaches(Chain(Map{}, c), "A")
+	if v, _ := c.Lookup("A"); v != "changed" {
+		t.Fatalf("A = %q after InvalidateCaches", v)
+	}
+}
+
+func TestReadOnlyView(t *testing.T) {
+	src := Map{"PAY_TIMEOUT": "5s", "PAY_URL": "u", "DB_PASSWORD": "secret"}
+	v := ReadOnlyView(src, "PAY_")
+	if got, ok := v.Lookup("TIMEOUT"); !ok || got != "5s" {
+		t.Fatalf("TIMEOUT = %q, %v", got, ok)
+	}
+	if _, ok := v.Lookup("../DB_PASSWORD"); ok {
+		t.Fatalf("escaped the prefix")
+	}
+	if got := KeysOf(v); !reflect.DeepEqual(got, []string{"TIMEOUT", "URL"}) {
+		t.Fatalf("keys = %v", got)
+	}
+	if _, ok := v.(interface{ Unwrap() Source }); ok {
+		t.Fatalf("view must not be unwrappable")
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	src := Chain(
+		Named("vault", Map{"A": "1"}),
+		Cache(Named("file", Map{"A": "2", "B": "3"})),
+	)
+	for key, want := range map[string]string{"A": "vault", "B": "file", "C": ""} {
+		if got := Origin(src, key); got != want {
+			t.Errorf("Origin(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// slowSource blocks each lookup until ctx is done.
+type slowSource struct{ Map }
+
+func (s slowSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	<-ctx.Done()
+	return "", false, ctx.Err()
+}
+
+func TestLookupContext(t *testing.T) {
+	src := Cache(WithMetrics(Chain(Map{"A": "1"}, Named("remote", slowSource{}))))
+	if v, ok, err := LookupContextOf(context.Background(), src, "A"); err != nil || !ok || v != "1" {
+		t.Fatalf("A = %q, %v, %v", v, ok, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := LookupContextOf(ctx, src, "B"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	// The abandoned lookup must not be cached as a miss.
+	if _, ok := src.vals["B"]; ok {
+		t.Fatal("canceled lookup was cached")
+	}
+	if _, _, err := LookupContextOf(ctx, Map{"A": "1"}, "A"); err == nil {
+		t.Fatal("want ctx error for a done context")
+	}
+}
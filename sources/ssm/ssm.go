@@ -0,0 +1,230 @@
+// Package ssm provides a source backed by AWS SSM Parameter Store.
+//
+// The package has no AWS SDK dependency. Callers adapt their SDK client
+// to the small Client interface, typically in a few lines around
+// ssm.Client.GetParametersByPath.
+package ssm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Parameter is a single parameter returned by Parameter Store.
+type Parameter struct {
+	// Name is the full parameter name, e.g. "/myapp/db/password".
+	Name string
+	// Value is the (decrypted, if requested) parameter value.
+	Value string
+}
+
+// Page is one page of a GetParametersByPath response.
+type Page struct {
+	// Parameters are the parameters in the page.
+	Parameters []Parameter
+	// NextToken is empty on the last page.
+	NextToken string
+}
+
+// Client is the subset of the SSM API used by the source.
+type Client interface {
+	// GetParametersByPath returns one page of parameters under path.
+	GetParametersByPath(ctx context.Context, in Input) (Page, error)
+}
+
+// Input mirrors the GetParametersByPath request fields used here.
+type Input struct {
+	Path           string
+	Recursive      bool
+	WithDecryption bool
+	NextToken      string
+}
+
+// Options configures a Source.
+type Options struct {
+	// Path is the parameter path prefix, e.g. "/myapp/prod/".
+	Path string
+	// Recursive fetches parameters in nested paths too.
+	Recursive bool
+	// Decrypt requests decryption of SecureString parameters.
+	Decrypt bool
+	// KeyFunc maps a parameter name (with Path stripped) to an env key.
+	// Defaults to DefaultKey.
+	KeyFunc func(name string) string
+}
+
+// Source is a sources.Source over a snapshot of Parameter Store values.
+type Source struct {
+	client Client
+	opts   Options
+
+	mu     sync.RWMutex
+	vals   map[string]string
+	byName map[string]string
+}
+
+// New creates a source and performs the initial fetch.
+//
+// Parameters:
+//   - ctx: The context for the initial fetch.
+//   - client: The SSM client adapter.
+//   - opts: The options.
+//
+// Returns:
+//   - *Source: The source.
+//   - error: The error if fetching fails.
+func New(ctx context.Context, client Client, opts Options) (*Source, error) {
+	if client == nil {
+		return nil, errors.New("envvar: ssm client is nil")
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = DefaultKey
+	}
+	s := &Source{client: client, opts: opts}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Refresh re-fetches all parameters under the configured path,
+// following pagination. The snapshot is replaced only on success.
+//
+// Parameters:
+//   - ctx: The context.
+//
+// Returns:
+//   - error: The error if fetching fails.
+func (s *Source) Refresh(ctx context.Context) error {
+	params, err := s.fetch(ctx, s.opts.Path, s.opts.Recursive)
+	if err != nil {
+		return err
+	}
+	vals := map[string]string{}
+	byName := make(map[string]string, len(params))
+	for _, p := range params {
+		byName[p.Name] = p.Value
+		name := strings.TrimPrefix(p.Name, s.opts.Path)
+		if k := s.opts.KeyFunc(name); k != "" {
+			vals[k] = p.Value
+		}
+	}
+	s.mu.Lock()
+	s.vals = vals
+	s.byName = byName
+	s.mu.Unlock()
+	return nil
+}
+
+// Resolve implements refs.Resolver for "ref+awsssm:///<name>"
+// references. Names inside the configured path are served from the
+// snapshot; others are fetched from their parent path.
+//
+// Parameters:
+//   - u: The reference with the "ref+" prefix stripped.
+//
+// Returns:
+//   - string: The parameter value.
+//   - error: The error if the parameter cannot be found.
+func (s *Source) Resolve(u *url.URL) (string, error) {
+	return s.ResolveContext(context.Background(), u)
+}
+
+// ResolveContext is like Resolve but passes ctx to the client when the
+// parameter is not in the snapshot. It implements refs.ContextResolver.
+//
+// Parameters:
+//   - ctx: The context.
+//   - u: The reference with the "ref+" prefix stripped.
+//
+// Returns:
+//   - string: The parameter value.
+//   - error: The error if the parameter cannot be found.
+func (s *Source) ResolveContext(ctx context.Context, u *url.URL) (string, error) {
+	name := u.Path
+	if name == "" {
+		return "", errors.New("want awsssm:///<parameter name>")
+	}
+	s.mu.RLock()
+	v, ok := s.byName[name]
+	s.mu.RUnlock()
+	if ok {
+		return v, nil
+	}
+	parent := name[:strings.LastIndex(name, "/")+1]
+	params, err := s.fetch(ctx, parent, false)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range params {
+		if p.Name == name {
+			return p.Value, nil
+		}
+	}
+	return "", fmt.Errorf("parameter %s not found", name)
+}
+
+// fetch returns all parameters under path, following pagination.
+func (s *Source) fetch(ctx context.Context, path string, recursive bool) ([]Parameter, error) {
+	var out []Parameter
+	in := Input{
+		Path:           path,
+		Recursive:      recursive,
+		WithDecryption: s.opts.Decrypt,
+	}
+	for {
+		page, err := s.client.GetParametersByPath(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("envvar: ssm %s: %w", path, err)
+		}
+		out = append(out, page.Parameters...)
+		if page.NextToken == "" {
+			return out, nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
+
+// Lookup returns the cached value for key.
+func (s *Source) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.vals[key]
+	return v, ok
+}
+
+// Keys returns the cached keys in sorted order.
+func (s *Source) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]string, 0, len(s.vals))
+	for k := range s.vals {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Name returns "ssm".
+func (s *Source) Name() string {
+	return "ssm"
+}
+
+// DefaultKey maps a relative parameter name such as "db/pass-word" to
+// "DB_PASS_WORD".
+//
+// Parameters:
+//   - name: The parameter name relative to the path.
+//
+// Returns:
+//   - string: The env-style key.
+func DefaultKey(name string) string {
+	name = strings.Trim(name, "/")
+	return strings.ToUpper(strings.NewReplacer("/", "_", "-", "_", ".", "_").
+		Replace(name))
+}
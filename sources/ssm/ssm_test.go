@@ -0,0 +1,67 @@
+package ssm
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aatuh/envvar/v2/binders"
+	"github.com/aatuh/envvar/v2/sources"
+)
+
+// fakeClient serves two pages of parameters.
+type fakeClient struct {
+	calls int
+}
+
+func (f *fakeClient) GetParametersByPath(_ context.Context, in Input) (Page, error) {
+	f.calls++
+	if in.NextToken == "" {
+		return Page{
+			Parameters: []Parameter{{Name: "/app/db/password", Value: "s3cr3t"}},
+			NextToken:  "p2",
+		}, nil
+	}
+	return Page{Parameters: []Parameter{{Name: "/app/port", Value: "8080"}}}, nil
+}
+
+func TestSSMSourceBind(t *testing.T) {
+	fc := &fakeClient{}
+	src, err := New(context.Background(), fc, Options{Path: "/app/", Decrypt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc.calls != 2 {
+		t.Fatalf("want 2 paged calls, got %d", fc.calls)
+	}
+
+	t.Setenv("PORT", "9090")
+	sources.SetDefault(sources.Chain(sources.OS(), src))
+	defer sources.SetDefault(nil)
+
+	var c struct {
+		Port int    `env:"PORT"`
+		Pass string `env:"DB_PASSWORD,required"`
+	}
+	if err := binders.Bind(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 9090 || c.Pass != "s3cr3t" {
+		t.Fatalf("unexpected: %+v", c)
+	}
+}
+
+func TestSSMResolve(t *testing.T) {
+	src, err := New(context.Background(), &fakeClient{}, Options{Path: "/app/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("awsssm:///app/db/password")
+	if v, err := src.Resolve(u); err != nil || v != "s3cr3t" {
+		t.Fatalf("Resolve: %q %v", v, err)
+	}
+	u, _ = url.Parse("awsssm:///app/missing")
+	if _, err := src.Resolve(u); err == nil {
+		t.Fatalf("expected not found error")
+	}
+}
@@ -0,0 +1,355 @@
+// Package vault provides a source backed by HashiCorp Vault KV v2
+// secrets, using only the standard library HTTP client.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// Options configures a Source.
+type Options struct {
+	// Address is the Vault base URL, e.g. "https://vault:8200".
+	Address string
+	// Token is the Vault token sent as X-Vault-Token.
+	Token string
+	// Mount is the KV v2 mount. Defaults to "secret".
+	Mount string
+	// Paths are the secret paths under Mount to read, e.g. "myapp/db".
+	Paths []string
+	// KeyFunc maps a secret path and field to an env key. Defaults to
+	// DefaultKey.
+	KeyFunc func(path, field string) string
+	// HTTPClient is used for requests. Defaults to a client with a 10s
+	// timeout.
+	HTTPClient *http.Client
+	// RefreshInterval enables background refresh in Start. Zero
+	// disables refreshing.
+	RefreshInterval time.Duration
+	// RenewToken renews the token (renew-self) on each refresh tick.
+	RenewToken bool
+	// Credentials returns a fresh token, e.g. from a Kubernetes or
+	// AppRole login. When set, a request rejected with 401 or 403 fetches
+	// a new token and is retried once, so long-running watchers recover
+	// from expired tokens. Each attempt is reported to AuthHook.
+	Credentials func(ctx context.Context) (string, error)
+}
+
+// Source is a sources.Source over cached Vault KV v2 secrets.
+type Source struct {
+	opts Options
+
+	mu    sync.RWMutex
+	vals  map[string]string
+	token string
+
+	// loopMu guards stop and done, set by the first Start.
+	loopMu   sync.Mutex
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a source and reads all configured paths once.
+//
+// Parameters:
+//   - ctx: The context for the initial read.
+//   - opts: The options.
+//
+// Returns:
+//   - *Source: The source.
+//   - error: The error if reading fails.
+func New(ctx context.Context, opts Options) (*Source, error) {
+	if opts.Address == "" {
+		return nil, errors.New("envvar: vault address is empty")
+	}
+	opts.Address = strings.TrimRight(opts.Address, "/")
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = DefaultKey
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	s := &Source{opts: opts, token: opts.Token}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Refresh re-reads all paths. The cache is replaced only on success.
+//
+// Parameters:
+//   - ctx: The context.
+//
+// Returns:
+//   - error: The error if any path fails to read.
+func (s *Source) Refresh(ctx context.Context) error {
+	vals := map[string]string{}
+	for _, p := range s.opts.Paths {
+		data, err := s.read(ctx, s.opts.Mount, p)
+		if err != nil {
+			return err
+		}
+		for field, v := range data {
+			if k := s.opts.KeyFunc(p, field); k != "" {
+				vals[k] = v
+			}
+		}
+	}
+	s.mu.Lock()
+	s.vals = vals
+	s.mu.Unlock()
+	types.CallOnLoad("vault", len(vals))
+	return nil
+}
+
+// Start launches background refresh (and token renewal when enabled)
+// every RefreshInterval until ctx is done or Stop is called. Errors are
+// reported through ErrorHook. It is a no-op when RefreshInterval is zero.
+//
+// Parameters:
+//   - ctx: The context bounding the background loop.
+func (s *Source) Start(ctx context.Context) {
+	if s.opts.RefreshInterval <= 0 {
+		return
+	}
+	s.loopMu.Lock()
+	defer s.loopMu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.stop, s.done = stop, done
+	go func() {
+		defer close(done)
+		t := types.CurrentClock().NewTicker(s.opts.RefreshInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-t.C():
+				if s.opts.RenewToken {
+					if err := s.renewSelf(ctx); err != nil {
+						types.CallOnError("vault", err)
+					}
+				}
+				if err := s.Refresh(ctx); err != nil {
+					types.CallOnError("vault", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop terminates the background loop started by Start and waits for
+// it to exit.
+func (s *Source) Stop() {
+	s.loopMu.Lock()
+	stop, done := s.stop, s.done
+	s.loopMu.Unlock()
+	if stop == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(stop) })
+	<-done
+}
+
+// Lookup returns the cached value for key.
+func (s *Source) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.vals[key]
+	return v, ok
+}
+
+// Keys returns the cached keys in sorted order.
+func (s *Source) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]string, 0, len(s.vals))
+	for k := range s.vals {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Name returns "vault".
+func (s *Source) Name() string {
+	return "vault"
+}
+
+// Resolve implements refs.Resolver for "ref+vault://<mount>/<path>#<field>"
+// references, reading the secret directly from Vault.
+//
+// Parameters:
+//   - u: The reference with the "ref+" prefix stripped.
+//
+// Returns:
+//   - string: The field value.
+//   - error: The error if the secret or field cannot be read.
+func (s *Source) Resolve(u *url.URL) (string, error) {
+	return s.ResolveContext(context.Background(), u)
+}
+
+// ResolveContext is like Resolve but aborts the Vault request when ctx
+// is done. It implements refs.ContextResolver.
+//
+// Parameters:
+//   - ctx: The context.
+//   - u: The reference with the "ref+" prefix stripped.
+//
+// Returns:
+//   - string: The field value.
+//   - error: The error if the secret or field cannot be read.
+func (s *Source) ResolveContext(ctx context.Context, u *url.URL) (string, error) {
+	if u.Host == "" || u.Fragment == "" {
+		return "", errors.New("want vault://<mount>/<path>#<field>")
+	}
+	data, err := s.read(ctx, u.Host, u.Path)
+	if err != nil {
+		return "", err
+	}
+	v, ok := data[u.Fragment]
+	if !ok {
+		return "", fmt.Errorf("field %q not found", u.Fragment)
+	}
+	return v, nil
+}
+
+// DefaultKey maps a secret field to an env key by uppercasing it and
+// replacing '-' and '.' with '_'. The path is ignored.
+//
+// Parameters:
+//   - path: The secret path.
+//   - field: The field name within the secret.
+//
+// Returns:
+//   - string: The env-style key.
+func DefaultKey(path, field string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").
+		Replace(field))
+}
+
+// kvResponse is the KV v2 read response shape.
+type kvResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+// read reads one KV v2 secret.
+func (s *Source) read(ctx context.Context, mount, path string) (map[string]string, error) {
+	addr := s.opts.Address + "/v1/" + mount + "/data/" +
+		strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	var body kvResponse
+	if err := s.do(req, &body); err != nil {
+		return nil, fmt.Errorf("envvar: vault read %s: %w", path, err)
+	}
+	out := make(map[string]string, len(body.Data.Data))
+	for k, v := range body.Data.Data {
+		switch tv := v.(type) {
+		case string:
+			out[k] = tv
+		default:
+			b, _ := json.Marshal(tv)
+			out[k] = string(b)
+		}
+	}
+	return out, nil
+}
+
+// renewSelf renews the configured token.
+func (s *Source) renewSelf(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		s.opts.Address+"/v1/auth/token/renew-self", strings.NewReader("{}"))
+	if err != nil {
+		return err
+	}
+	if err := s.do(req, nil); err != nil {
+		return fmt.Errorf("envvar: vault token renew: %w", err)
+	}
+	return nil
+}
+
+// do sends req with the token and decodes a JSON body into out. A 401
+// or 403 response triggers one credential refresh and retry when
+// Credentials is set.
+func (s *Source) do(req *http.Request, out any) error {
+	resp, err := s.send(req)
+	if err != nil {
+		return err
+	}
+	if s.opts.Credentials != nil && (resp.StatusCode == http.StatusUnauthorized ||
+		resp.StatusCode == http.StatusForbidden) {
+		resp.Body.Close()
+		if err := s.reauth(req.Context()); err != nil {
+			return err
+		}
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return err
+			}
+		}
+		if resp, err = s.send(retry); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// send sends req with the current token.
+func (s *Source) send(req *http.Request) (*http.Response, error) {
+	s.mu.RLock()
+	req.Header.Set("X-Vault-Token", s.token)
+	s.mu.RUnlock()
+	return s.opts.HTTPClient.Do(req)
+}
+
+// reauth replaces the token with one from Credentials and reports the
+// outcome to AuthHook.
+func (s *Source) reauth(ctx context.Context) error {
+	tok, err := s.opts.Credentials(ctx)
+	if err == nil && tok == "" {
+		err = errors.New("empty token")
+	}
+	if err != nil {
+		err = fmt.Errorf("envvar: vault credentials: %w", err)
+		types.CallOnAuthRefresh("vault", err)
+		return err
+	}
+	s.mu.Lock()
+	s.token = tok
+	s.mu.Unlock()
+	types.CallOnAuthRefresh("vault", nil)
+	return nil
+}
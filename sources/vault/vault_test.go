@@ -0,0 +1,193 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aatuh/envvar/v2/types"
+)
+
+// errHook records OnError calls.
+type errHook struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (h *errHook) OnLoad(string, int)                       {}
+func (h *errHook) OnGet(string, bool, error, time.Duration) {}
+func (h *errHook) OnError(_ string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+func TestVaultReadAndRefreshError(t *testing.T) {
+	var fail, renewed atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/auth/token/renew-self":
+			renewed.Store(true)
+			_, _ = w.Write([]byte(`{}`))
+		case "/v1/secret/data/myapp/db":
+			if fail.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"pw","max-conns":10}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	h := &errHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+
+	s, err := New(context.Background(), Options{
+		Address:         srv.URL,
+		Token:           "tok",
+		Paths:           []string{"myapp/db"},
+		RefreshInterval: 5 * time.Millisecond,
+		RenewToken:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Lookup("PASSWORD"); v != "pw" {
+		t.Fatalf("PASSWORD: %q", v)
+	}
+	if v, _ := s.Lookup("MAX_CONNS"); v != "10" {
+		t.Fatalf("MAX_CONNS: %q", v)
+	}
+	u, _ := url.Parse("vault://secret/myapp/db#password")
+	if v, err := s.Resolve(u); err != nil || v != "pw" {
+		t.Fatalf("Resolve: %q %v", v, err)
+	}
+
+	fail.Store(true)
+	s.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for {
+		h.mu.Lock()
+		n := len(h.errs)
+		h.mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Stop()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.errs) == 0 || errors.Unwrap(h.errs[0]) == nil {
+		t.Fatalf("expected wrapped refresh error, got %v", h.errs)
+	}
+	if !renewed.Load() {
+		t.Fatalf("expected token renewal")
+	}
+	if v, _ := s.Lookup("PASSWORD"); v != "pw" {
+		t.Fatalf("cache must survive failed refresh: %q", v)
+	}
+}
+
+func TestVaultStartStopConcurrent(t *testing.T) {
+	s := &Source{opts: Options{RefreshInterval: time.Hour}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.Start(context.Background())
+		}()
+		go func() {
+			defer wg.Done()
+			s.Stop()
+		}()
+	}
+	wg.Wait()
+	s.Stop()
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("refresh loop still running after Stop")
+	}
+}
+
+// authHook records OnAuthRefresh calls.
+type authHook struct {
+	errHook
+	refreshes []error
+}
+
+func (h *authHook) OnAuthRefresh(_ string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.refreshes = append(h.refreshes, err)
+}
+
+func TestVaultReauthOnExpiredToken(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("tok-1")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != valid.Load().(string) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"pw"}}}`))
+	}))
+	defer srv.Close()
+
+	h := &authHook{}
+	types.SetHook(h)
+	defer types.SetHook(nil)
+
+	var logins atomic.Int32
+	s, err := New(context.Background(), Options{
+		Address: srv.URL,
+		Token:   "tok-1",
+		Paths:   []string{"myapp/db"},
+		Credentials: func(context.Context) (string, error) {
+			logins.Add(1)
+			return valid.Load().(string), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logins.Load() != 0 {
+		t.Fatalf("a valid token must not trigger a login")
+	}
+
+	valid.Store("tok-2") // the first token expires
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh after expiry: %v", err)
+	}
+	if logins.Load() != 1 || len(h.refreshes) != 1 || h.refreshes[0] != nil {
+		t.Fatalf("want one successful re-auth, got %d logins, events %v",
+			logins.Load(), h.refreshes)
+	}
+
+	s.opts.Credentials = func(context.Context) (string, error) {
+		return "", errors.New("login denied")
+	}
+	valid.Store("tok-3")
+	if err := s.Refresh(context.Background()); err == nil {
+		t.Fatalf("want error when credentials cannot be refreshed")
+	}
+	if len(h.refreshes) != 2 || h.refreshes[1] == nil {
+		t.Fatalf("want failed re-auth event, got %v", h.refreshes)
+	}
+}
@@ -0,0 +1,48 @@
+package sources
+
+import "strings"
+
+// view exposes the prefixed keys of a source with the prefix removed.
+// It deliberately has no Unwrap, so holders cannot reach the wrapped
+// source.
+type view struct {
+	src    Source
+	prefix string
+}
+
+// ReadOnlyView returns a view of the keys of src starting with prefix,
+// with the prefix stripped: for prefix "PAYMENTS_", Lookup("TIMEOUT")
+// reads PAYMENTS_TIMEOUT. Keys outside the prefix are unreachable and
+// the view cannot be unwrapped, so it can be handed to third-party
+// code that should only see its own namespace.
+//
+// Parameters:
+//   - src: The source.
+//   - prefix: The namespace prefix.
+//
+// Returns:
+//   - Source: The view.
+func ReadOnlyView(src Source, prefix string) Source {
+	return &view{src: src, prefix: prefix}
+}
+
+// Lookup reads prefix+key.
+func (v *view) Lookup(key string) (string, bool) {
+	return v.src.Lookup(v.prefix + key)
+}
+
+// Keys returns the keys within the prefix, stripped and sorted.
+func (v *view) Keys() []string {
+	var out []string
+	for _, k := range SortedKeys(v.src) {
+		if rest, ok := strings.CutPrefix(k, v.prefix); ok && rest != "" {
+			out = append(out, rest)
+		}
+	}
+	return out
+}
+
+// Name returns "view:<prefix>".
+func (v *view) Name() string {
+	return "view:" + v.prefix
+}
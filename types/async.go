@@ -0,0 +1,194 @@
+package types
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncHook wraps a Hook so its calls run on a background goroutine
+// through a bounded queue. Calls are dropped and counted rather than
+// blocking when the queue is full, so slow hooks (e.g. network logging)
+// cannot stall getters. ErrorHook, ChangeHook, AuthHook, BindHook,
+// ValidationHook, DefaultHook, and MissHook calls are forwarded when the
+// wrapped hook implements them.
+type AsyncHook struct {
+	h         Hook
+	q         chan func()
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	// mu is held shared across the closed check and the send in
+	// enqueue, and exclusively by Close, so no call is queued after
+	// the drain.
+	mu        sync.RWMutex
+	closed    bool
+	dropped   atomic.Uint64
+	delivered atomic.Uint64
+}
+
+// NewAsyncHook starts an AsyncHook delivering to h with a queue of size
+// calls (1024 when size <= 0). Install it with SetHook and Close it on
+// shutdown to flush pending calls.
+//
+// Parameters:
+//   - h: The hook to call asynchronously.
+//   - size: The queue capacity.
+//
+// Returns:
+//   - *AsyncHook: The running hook.
+func NewAsyncHook(h Hook, size int) *AsyncHook {
+	if size <= 0 {
+		size = 1024
+	}
+	a := &AsyncHook{
+		h:    h,
+		q:    make(chan func(), size),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// run delivers queued calls until Close, then drains the queue.
+func (a *AsyncHook) run() {
+	defer close(a.done)
+	for {
+		select {
+		case fn := <-a.q:
+			a.deliver(fn)
+		case <-a.stop:
+			for {
+				select {
+				case fn := <-a.q:
+					a.deliver(fn)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver runs one queued call.
+func (a *AsyncHook) deliver(fn func()) {
+	fn()
+	a.delivered.Add(1)
+}
+
+// enqueue queues fn, dropping it when the queue is full or closed.
+func (a *AsyncHook) enqueue(fn func()) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		a.dropped.Add(1)
+		return
+	}
+	select {
+	case a.q <- fn:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// OnLoad queues the OnLoad call.
+func (a *AsyncHook) OnLoad(source string, keys int) {
+	a.enqueue(func() { a.h.OnLoad(source, keys) })
+}
+
+// OnGet queues the OnGet call.
+func (a *AsyncHook) OnGet(key string, ok bool, err error, dur time.Duration) {
+	a.enqueue(func() { a.h.OnGet(key, ok, err, dur) })
+}
+
+// OnError queues the OnError call if the wrapped hook is an ErrorHook.
+func (a *AsyncHook) OnError(source string, err error) {
+	if eh, ok := a.h.(ErrorHook); ok {
+		a.enqueue(func() { eh.OnError(source, err) })
+	}
+}
+
+// OnChange queues the OnChange call if the wrapped hook is a
+// ChangeHook.
+func (a *AsyncHook) OnChange(source string, changes []Change) {
+	if ch, ok := a.h.(ChangeHook); ok {
+		a.enqueue(func() { ch.OnChange(source, changes) })
+	}
+}
+
+// OnAuthRefresh queues the OnAuthRefresh call if the wrapped hook is an
+// AuthHook.
+func (a *AsyncHook) OnAuthRefresh(source string, err error) {
+	if ah, ok := a.h.(AuthHook); ok {
+		a.enqueue(func() { ah.OnAuthRefresh(source, err) })
+	}
+}
+
+// OnBind queues the OnBind call if the wrapped hook is a BindHook.
+func (a *AsyncHook) OnBind(structType string, fields, errors int, dur time.Duration) {
+	if bh, ok := a.h.(BindHook); ok {
+		a.enqueue(func() { bh.OnBind(structType, fields, errors, dur) })
+	}
+}
+
+// OnValidationError queues the OnValidationError call if the wrapped
+// hook is a ValidationHook.
+func (a *AsyncHook) OnValidationError(field, rule, msg string) {
+	if vh, ok := a.h.(ValidationHook); ok {
+		a.enqueue(func() { vh.OnValidationError(field, rule, msg) })
+	}
+}
+
+// OnDefaultUsed queues the OnDefaultUsed call if the wrapped hook is a
+// DefaultHook.
+func (a *AsyncHook) OnDefaultUsed(key, value string) {
+	if dh, ok := a.h.(DefaultHook); ok {
+		a.enqueue(func() { dh.OnDefaultUsed(key, value) })
+	}
+}
+
+// OnMissing queues the OnMissing call if the wrapped hook is a
+// MissHook.
+func (a *AsyncHook) OnMissing(key string) {
+	if mh, ok := a.h.(MissHook); ok {
+		a.enqueue(func() { mh.OnMissing(key) })
+	}
+}
+
+// Dropped returns the number of calls dropped because the queue was
+// full or the hook was closed.
+//
+// Returns:
+//   - uint64: The dropped call count.
+func (a *AsyncHook) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Delivered returns the number of calls delivered to the wrapped hook.
+//
+// Returns:
+//   - uint64: The delivered call count.
+func (a *AsyncHook) Delivered() uint64 {
+	return a.delivered.Load()
+}
+
+// Pending returns the number of queued calls.
+//
+// Returns:
+//   - int: The queue length.
+func (a *AsyncHook) Pending() int {
+	return len(a.q)
+}
+
+// Close stops accepting calls, delivers the queued ones, and waits for
+// the background goroutine to exit.
+func (a *AsyncHook) Close() {
+	a.closeOnce.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		a.mu.Unlock()
+		close(a.stop)
+	})
+	<-a.done
+}
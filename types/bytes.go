@@ -0,0 +1,78 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Bytes is a byte size configured with a human-readable suffix, e.g.
+// "512MB" or "2GiB". Bind parses fields of this type with ParseBytes.
+type Bytes uint64
+
+// String formats b with the largest exact IEC unit, e.g. "2GiB".
+func (b Bytes) String() string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	v := uint64(b)
+	i := 0
+	for v >= 1024 && v%1024 == 0 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return strconv.FormatUint(v, 10) + units[i]
+}
+
+// byteUnits maps lower-case suffixes to multipliers. SI units (KB, MB)
+// are powers of 1000 and IEC units (KiB, MiB) powers of 1024; the short
+// forms follow the Kubernetes convention (K = 1000, Ki = 1024).
+var byteUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "ki": 1 << 10, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mi": 1 << 20, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gi": 1 << 30, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "ti": 1 << 40, "tib": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pi": 1 << 50, "pib": 1 << 50,
+	"e": 1e18, "eb": 1e18, "ei": 1 << 60, "eib": 1 << 60,
+}
+
+// ParseBytes parses a byte size such as "512", "512MB", "1.5 GiB", or
+// "64Ki". Suffixes are case-insensitive.
+//
+// Parameters:
+//   - s: The size.
+//
+// Returns:
+//   - uint64: The size in bytes.
+//   - error: The error if s is malformed or overflows.
+func ParseBytes(s string) (uint64, error) {
+	t := strings.TrimSpace(s)
+	i := 0
+	for i < len(t) && (t[i] >= '0' && t[i] <= '9' || t[i] == '.') {
+		i++
+	}
+	num, unit := t[:i], strings.ToLower(strings.TrimSpace(t[i:]))
+	mult, ok := byteUnits[unit]
+	if num == "" || !ok {
+		return 0, errors.New("invalid byte size: " + s)
+	}
+	if !strings.Contains(num, ".") {
+		n, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return 0, errors.New("invalid byte size: " + s)
+		}
+		if m := uint64(mult); n > math.MaxUint64/m {
+			return 0, errors.New("byte size overflows: " + s)
+		}
+		return n * uint64(mult), nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, errors.New("invalid byte size: " + s)
+	}
+	f *= mult
+	if f >= math.MaxUint64 {
+		return 0, errors.New("byte size overflows: " + s)
+	}
+	return uint64(f), nil
+}
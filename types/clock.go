@@ -0,0 +1,110 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts time for hooks, caches, and watchers so tests can
+// simulate it. The default is the system clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a ticker firing every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C until stopped.
+type Ticker interface {
+	// C returns the channel ticks are delivered on.
+	C() <-chan time.Time
+	// Stop stops the ticker.
+	Stop()
+}
+
+// systemClock is the Clock backed by package time.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker wraps time.NewTicker.
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker adapts *time.Ticker to Ticker.
+type systemTicker struct {
+	t *time.Ticker
+}
+
+// C returns the ticker channel.
+func (t systemTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+// Stop stops the ticker.
+func (t systemTicker) Stop() {
+	t.t.Stop()
+}
+
+// SystemClock returns the Clock backed by package time.
+//
+// Returns:
+//   - Clock: The system clock.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+var (
+	// clockMu protects clock.
+	clockMu sync.RWMutex
+	// clock is the global clock.
+	clock Clock = systemClock{}
+)
+
+// SetClock installs the clock used for hook timings, load records, and
+// watcher polling. Passing nil restores the system clock. Watchers pick
+// up the clock when they start.
+//
+// Parameters:
+//   - c: The clock.
+func SetClock(c Clock) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	if c == nil {
+		c = systemClock{}
+	}
+	clock = c
+}
+
+// CurrentClock returns the installed clock.
+//
+// Returns:
+//   - Clock: The clock.
+func CurrentClock() Clock {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return clock
+}
+
+// Now returns the current time of the installed clock.
+//
+// Returns:
+//   - time.Time: The time.
+func Now() time.Time {
+	return CurrentClock().Now()
+}
+
+// Since returns the time elapsed since t on the installed clock.
+//
+// Parameters:
+//   - t: The start time.
+//
+// Returns:
+//   - time.Duration: The elapsed time.
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
@@ -0,0 +1,18 @@
+package types
+
+import "errors"
+
+// Sentinel errors matched by errors.Is against the key and field errors
+// of getters and binders, so callers can branch on the failure class
+// without inspecting error kinds.
+var (
+	// ErrMissingVar matches errors for required variables that are not
+	// set.
+	ErrMissingVar = errors.New("envvar: missing variable")
+	// ErrTypeMismatch matches errors for values that do not parse into
+	// the requested type.
+	ErrTypeMismatch = errors.New("envvar: type mismatch")
+	// ErrValidation matches errors for values rejected by a validation
+	// rule.
+	ErrValidation = errors.New("envvar: validation failed")
+)
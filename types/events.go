@@ -0,0 +1,126 @@
+package types
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind classifies an Event.
+type EventKind int
+
+const (
+	// EventLoaded reports a file or source load.
+	EventLoaded EventKind = iota + 1
+	// EventGet reports a read that found a value.
+	EventGet
+	// EventMiss reports a read that found no value.
+	EventMiss
+	// EventDeprecated reports a read of a deprecated variable.
+	EventDeprecated
+	// EventChanged reports changes detected in a watched source.
+	EventChanged
+	// EventValidationFailed reports a value rejected while binding.
+	EventValidationFailed
+)
+
+// String returns the event kind name.
+func (k EventKind) String() string {
+	switch k {
+	case EventLoaded:
+		return "loaded"
+	case EventGet:
+		return "get"
+	case EventMiss:
+		return "miss"
+	case EventDeprecated:
+		return "deprecated"
+	case EventChanged:
+		return "changed"
+	case EventValidationFailed:
+		return "validation_failed"
+	}
+	return "unknown"
+}
+
+// Event is one envvar activity record. Only the fields relevant to
+// Kind are set.
+type Event struct {
+	Kind EventKind
+	At   time.Time
+	// Source is the file or source of EventLoaded and EventChanged.
+	Source string
+	// Key is the variable of EventGet, EventMiss, EventDeprecated, and
+	// EventValidationFailed.
+	Key string
+	// Keys is the number of variables of EventLoaded.
+	Keys int
+	// Changes are the changes of EventChanged.
+	Changes []Change
+	// Err is the failure of EventGet or EventValidationFailed.
+	Err error
+	// Dur is the time spent in the read of EventGet and EventMiss.
+	Dur time.Duration
+}
+
+// eventBuffer is the capacity of each Events channel.
+const eventBuffer = 256
+
+var (
+	// eventsMu protects subscribers.
+	eventsMu sync.RWMutex
+	// subscribers are the open Events channels.
+	subscribers = map[<-chan Event]chan Event{}
+	// nsubs is len(subscribers), read without locking on hot paths.
+	nsubs atomic.Int32
+)
+
+// Events returns a new channel receiving every subsequent event. Events
+// are dropped rather than blocking the caller when the channel's buffer
+// is full, so consume it promptly. Release it with StopEvents.
+//
+// Returns:
+//   - <-chan Event: The event stream.
+func Events() <-chan Event {
+	ch := make(chan Event, eventBuffer)
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	subscribers[ch] = ch
+	nsubs.Store(int32(len(subscribers)))
+	return ch
+}
+
+// StopEvents unsubscribes and closes a channel returned by Events.
+//
+// Parameters:
+//   - ch: The channel.
+func StopEvents(ch <-chan Event) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if c, ok := subscribers[ch]; ok {
+		delete(subscribers, ch)
+		close(c)
+	}
+	nsubs.Store(int32(len(subscribers)))
+}
+
+// Emit sends e to every Events channel, stamping At when zero.
+//
+// Parameters:
+//   - e: The event.
+func Emit(e Event) {
+	if nsubs.Load() == 0 {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = Now()
+	}
+	eventsMu.RLock()
+	defer eventsMu.RUnlock()
+	for _, c := range subscribers {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// ErrLimit is returned (wrapped) when a configured Limits bound is
+// exceeded.
+var ErrLimit = errors.New("envvar: limit exceeded")
+
+// Limits bounds the size of values and environments, protecting
+// services from pathological injected environments. Zero fields are
+// unlimited.
+type Limits struct {
+	// MaxValueSize is the largest value, in bytes, getters, Bind, and
+	// loaders accept.
+	MaxValueSize int
+	// MaxKeys is the largest number of variables an env file or a
+	// checked environment may define.
+	MaxKeys int
+	// MaxTotalSize is the largest env file, or sum of keys and values
+	// of a checked environment, in bytes.
+	MaxTotalSize int
+}
+
+var (
+	// limitsMu protects limits.
+	limitsMu sync.RWMutex
+	// limits are the global limits.
+	limits Limits
+)
+
+// SetLimits installs global limits. The zero Limits disables them.
+//
+// Parameters:
+//   - l: The limits.
+func SetLimits(l Limits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	limits = l
+}
+
+// CurrentLimits returns the global limits.
+//
+// Returns:
+//   - Limits: The limits.
+func CurrentLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits
+}
+
+// CheckValue returns a message if v exceeds MaxValueSize, else "".
+//
+// Parameters:
+//   - v: The value.
+//
+// Returns:
+//   - string: The violation message, or "".
+func (l Limits) CheckValue(v string) string {
+	if l.MaxValueSize > 0 && len(v) > l.MaxValueSize {
+		return "value is " + strconv.Itoa(len(v)) + " bytes, max " +
+			strconv.Itoa(l.MaxValueSize)
+	}
+	return ""
+}
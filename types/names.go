@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidName reports whether key is a portable environment variable
+// name: ASCII letters, digits, and underscores, not starting with a
+// digit.
+//
+// Parameters:
+//   - key: The name.
+//
+// Returns:
+//   - error: The error describing why the name is invalid, or nil.
+func ValidName(key string) error {
+	if key == "" {
+		return fmt.Errorf("envvar: invalid variable name %q: empty", key)
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if isNameChar(c) && (i > 0 || c < '0' || c > '9') {
+			continue
+		}
+		if i == 0 && c >= '0' && c <= '9' {
+			return fmt.Errorf("envvar: invalid variable name %q: starts with a digit", key)
+		}
+		return fmt.Errorf("envvar: invalid variable name %q: character %q at %d",
+			key, c, i)
+	}
+	return nil
+}
+
+// NormalizeName turns s into a valid variable name: letters are
+// upper-cased, each run of invalid characters between valid ones
+// becomes a single underscore (leading and trailing runs are dropped),
+// and a leading digit is prefixed with an underscore, e.g.
+// "db.host-name" becomes "DB_HOST_NAME". It returns "" for "" and "_"
+// when no character is valid.
+//
+// Parameters:
+//   - s: The raw name.
+//
+// Returns:
+//   - string: The normalized name.
+func NormalizeName(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	pending := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if !isNameChar(c) {
+			pending = true
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		pending = false
+		if b.Len() == 0 && c >= '0' && c <= '9' {
+			b.WriteByte('_')
+		}
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 && pending {
+		return "_"
+	}
+	return b.String()
+}
+
+// NormalizeNames normalizes each name and reports collisions, where
+// distinct inputs such as "db.host" and "db-host" map to the same
+// variable.
+//
+// Parameters:
+//   - names: The raw names.
+//
+// Returns:
+//   - map[string]string: The normalized name of each input.
+//   - error: The error listing colliding inputs, or nil.
+func NormalizeNames(names []string) (map[string]string, error) {
+	out := make(map[string]string, len(names))
+	by := map[string][]string{}
+	for _, n := range names {
+		if _, dup := out[n]; dup {
+			continue
+		}
+		norm := NormalizeName(n)
+		out[n] = norm
+		by[norm] = append(by[norm], n)
+	}
+	var msgs []string
+	for norm, ins := range by {
+		if len(ins) > 1 {
+			sort.Strings(ins)
+			msgs = append(msgs, fmt.Sprintf("%s from %q", norm, ins))
+		}
+	}
+	if len(msgs) > 0 {
+		sort.Strings(msgs)
+		return out, fmt.Errorf("envvar: name collisions: %s", strings.Join(msgs, "; "))
+	}
+	return out, nil
+}
+
+// isNameChar reports whether c may appear in a variable name.
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') ||
+		(c >= '0' && c <= '9')
+}
@@ -0,0 +1,163 @@
+package types
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrPinMismatch is matched by errors for pinned variables whose value
+// does not hash to the pinned digest.
+var ErrPinMismatch = errors.New("envvar: pinned value mismatch")
+
+// PinPolicy selects what a pin mismatch does.
+type PinPolicy int
+
+const (
+	// PinAlert reports mismatches to ErrorHook and keeps the value.
+	PinAlert PinPolicy = iota
+	// PinFail makes reads and binds of a mismatched variable fail.
+	PinFail
+)
+
+// PinError reports a pinned variable whose value does not match. The
+// actual hash is not included in the message, since hashes of short
+// secrets can be brute-forced.
+type PinError struct {
+	// Key is the pinned variable.
+	Key string
+	// Want is the pinned digest, "sha256:<hex>".
+	Want string
+	// Got is the digest of the resolved value, "" when the variable is
+	// unset.
+	Got string
+}
+
+// Error returns the error message.
+//
+// Returns:
+//   - string: The error message.
+func (e *PinError) Error() string {
+	if e.Got == "" {
+		return "envvar: pinned variable " + e.Key + " is not set"
+	}
+	return "envvar: " + e.Key + ": value does not match pin " + e.Want
+}
+
+// Unwrap returns ErrPinMismatch.
+//
+// Returns:
+//   - error: ErrPinMismatch.
+func (e *PinError) Unwrap() error {
+	return ErrPinMismatch
+}
+
+var (
+	// pinsMu protects pins and pinPolicy.
+	pinsMu sync.RWMutex
+	// pins maps keys to their pinned "sha256:<hex>" digests.
+	pins = map[string]string{}
+	// pinPolicy is the global pin policy.
+	pinPolicy PinPolicy
+)
+
+// PinHash returns the digest Pin expects for value, "sha256:<hex>".
+//
+// Parameters:
+//   - value: The value.
+//
+// Returns:
+//   - string: The digest.
+func PinHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Pin pins key to a value digest, as returned by PinHash. A bare hex
+// SHA-256 digest is accepted too. Resolved values of key (after
+// expansion and decryption) are compared with it on every read and
+// bind. An empty digest removes the pin.
+//
+// Parameters:
+//   - key: The variable to pin.
+//   - hash: The expected digest.
+//
+// Returns:
+//   - error: The error if hash is not a SHA-256 digest.
+func Pin(key, hash string) error {
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	if hash == "" {
+		delete(pins, key)
+		return nil
+	}
+	h := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(hash), "sha256:"))
+	if b, err := hex.DecodeString(h); err != nil || len(b) != sha256.Size {
+		return errors.New("envvar: pin for " + key + " is not a sha256 digest")
+	}
+	pins[key] = "sha256:" + h
+	return nil
+}
+
+// SetPinPolicy sets what a pin mismatch does. The default is PinAlert.
+//
+// Parameters:
+//   - p: The policy.
+func SetPinPolicy(p PinPolicy) {
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	pinPolicy = p
+}
+
+// PinnedKeys returns the pinned variables in sorted order.
+//
+// Returns:
+//   - []string: The pinned keys.
+func PinnedKeys() []string {
+	pinsMu.RLock()
+	defer pinsMu.RUnlock()
+	out := make([]string, 0, len(pins))
+	for k := range pins {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CheckPin compares the resolved value of key with its pin. On a
+// mismatch it reports a *PinError to ErrorHook, and also returns it
+// under PinFail.
+//
+// Parameters:
+//   - key: The variable.
+//   - value: The resolved value.
+//   - ok: Whether the variable is set.
+//
+// Returns:
+//   - error: The *PinError under PinFail, else nil.
+func CheckPin(key, value string, ok bool) error {
+	pinsMu.RLock()
+	want, pinned := pins[key]
+	policy := pinPolicy
+	pinsMu.RUnlock()
+	if !pinned {
+		return nil
+	}
+	var got string
+	if ok {
+		got = PinHash(value)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1 {
+			return nil
+		}
+	}
+	err := &PinError{Key: key, Want: want, Got: got}
+	CallOnError("pin", err)
+	if policy == PinFail {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Profile is a strictness profile that adjusts several behaviors with
+// one switch. ProfileDev keeps the lenient defaults; ProfileStaging
+// surfaces problems without failing; ProfileProd fails on them.
+type Profile string
+
+const (
+	// ProfileDev is lenient: any common boolean spelling is accepted,
+	// Or getters fall back silently, unknown prefixed keys are ignored,
+	// and missing default env files are skipped.
+	ProfileDev Profile = "dev"
+	// ProfileStaging warns: Or getters and BindWithPrefix report
+	// unparsable values and unknown keys through ErrorHook.
+	ProfileStaging Profile = "staging"
+	// ProfileProd is strict: booleans must be true, false, 1, or 0, Or
+	// getters panic on unparsable values, BindWithPrefix rejects unknown
+	// keys, and LoadOnce fails when no env file exists.
+	ProfileProd Profile = "prod"
+)
+
+// profileKeys are the variables consulted for the profile when none is
+// set with SetProfile, in order; the same ones LoadProfile reads.
+var profileKeys = []string{"APP_ENV", "GO_ENV"}
+
+var (
+	// profileMu protects profile.
+	profileMu sync.RWMutex
+	// profile is the explicitly selected profile; "" defers to env.
+	profile Profile
+)
+
+// SetProfile selects the global profile. The empty profile restores
+// selection from APP_ENV and GO_ENV.
+//
+// Parameters:
+//   - p: The profile.
+func SetProfile(p Profile) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profile = p
+}
+
+// CurrentProfile returns the profile set with SetProfile, else the one
+// named by the first non-empty APP_ENV or GO_ENV variable, else ProfileDev.
+//
+// Returns:
+//   - Profile: The profile.
+func CurrentProfile() Profile {
+	profileMu.RLock()
+	p := profile
+	profileMu.RUnlock()
+	if p != "" {
+		return p
+	}
+	for _, k := range profileKeys {
+		if v := strings.TrimSpace(os.Getenv(k)); v != "" {
+			return ParseProfile(v)
+		}
+	}
+	return ProfileDev
+}
+
+// ParseProfile maps an environment name to a profile: "prod" and
+// "production" to ProfileProd, "staging" and "stage" to
+// ProfileStaging, and anything else to ProfileDev. Case is ignored.
+//
+// Parameters:
+//   - s: The environment name, e.g. the APP_ENV value.
+//
+// Returns:
+//   - Profile: The profile.
+func ParseProfile(s string) Profile {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "prod", "production":
+		return ProfileProd
+	case "staging", "stage":
+		return ProfileStaging
+	}
+	return ProfileDev
+}
+
+// StrictBools reports whether only true, false, 1, and 0 are accepted
+// as booleans.
+func (p Profile) StrictBools() bool { return p == ProfileProd }
+
+// RejectUnknown reports whether BindWithPrefix fails on unknown keys.
+func (p Profile) RejectUnknown() bool { return p == ProfileProd }
+
+// WarnUnknown reports whether BindWithPrefix reports unknown keys
+// through ErrorHook.
+func (p Profile) WarnUnknown() bool { return p == ProfileStaging }
+
+// RequireFiles reports whether LoadOnce fails when no file exists.
+func (p Profile) RequireFiles() bool { return p == ProfileProd }
+
+// ParseBool parses v as a boolean under the current profile: the
+// common spellings (1, t, true, y, yes, on and their negatives) by
+// default, only true, false, 1, and 0 under ProfileProd.
+//
+// Parameters:
+//   - v: The value.
+//
+// Returns:
+//   - bool: The value.
+//   - bool: Whether v is a valid boolean.
+func ParseBool(v string) (bool, bool) {
+	s := strings.ToLower(strings.TrimSpace(v))
+	if CurrentProfile().StrictBools() {
+		switch s {
+		case "true", "1":
+			return true, true
+		case "false", "0":
+			return false, true
+		}
+		return false, false
+	}
+	switch s {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, true
+	case "0", "f", "false", "n", "no", "off":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Access summarizes the reads of one key through getters and Bind.
+type Access struct {
+	Key string
+	// Reads is the number of reads.
+	Reads uint64
+	// Misses is the number of reads that found no value.
+	Misses uint64
+	// Errors is the number of reads that failed.
+	Errors uint64
+}
+
+// Load records one load of a file or source.
+type Load struct {
+	Source string
+	Keys   int
+	At     time.Time
+}
+
+// maxLoads bounds the load history.
+const maxLoads = 100
+
+// maxAccessKeys bounds the keys AccessReport tracks, so processes that
+// read dynamic keys do not grow without limit. Reads of further keys
+// are not recorded.
+const maxAccessKeys = 4096
+
+var (
+	// recording enables access recording; see SetRecordAccess.
+	recording atomic.Bool
+	// recMu protects accesses and loads.
+	recMu sync.Mutex
+	// accesses maps keys to their read counters.
+	accesses = map[string]*Access{}
+	// loads holds the most recent loads, oldest first.
+	loads []Load
+)
+
+// SetRecordAccess turns on the per-key read counters behind
+// AccessReport. Recording is off by default so reads on hot paths do
+// not serialize on a shared lock; turn it on at startup in processes
+// that use AccessReport, DumpUsed, or SupportBundle. At most 4096 keys
+// are tracked.
+//
+// Parameters:
+//   - on: Whether reads are recorded.
+func SetRecordAccess(on bool) {
+	recording.Store(on)
+}
+
+// recordGet counts a read of key when recording is on.
+func recordGet(key string, ok bool, err error) {
+	if !recording.Load() {
+		return
+	}
+	recMu.Lock()
+	defer recMu.Unlock()
+	a := accesses[key]
+	if a == nil {
+		if len(accesses) >= maxAccessKeys {
+			return
+		}
+		a = &Access{Key: key}
+		accesses[key] = a
+	}
+	a.Reads++
+	if !ok {
+		a.Misses++
+	}
+	if err != nil {
+		a.Errors++
+	}
+}
+
+// RecordAccess counts a read of key that bypasses getters, such as a
+// Bind lookup, without calling hooks or emitting events.
+//
+// Parameters:
+//   - key: The key read.
+//   - ok: Whether a value was found.
+func RecordAccess(key string, ok bool) {
+	recordGet(key, ok, nil)
+}
+
+// recordLoad appends a load, dropping the oldest beyond maxLoads.
+func recordLoad(source string, keys int) {
+	recMu.Lock()
+	defer recMu.Unlock()
+	if len(loads) == maxLoads {
+		loads = append(loads[:0], loads[1:]...)
+	}
+	loads = append(loads, Load{Source: source, Keys: keys, At: Now()})
+}
+
+// AccessReport returns the keys read through getters and Bind since
+// SetRecordAccess turned recording on, sorted by key. Values are never
+// recorded.
+//
+// Returns:
+//   - []Access: The per-key counters.
+func AccessReport() []Access {
+	recMu.Lock()
+	defer recMu.Unlock()
+	out := make([]Access, 0, len(accesses))
+	for _, a := range accesses {
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// LoadReport returns the most recent file and source loads, oldest
+// first.
+//
+// Returns:
+//   - []Load: The loads.
+func LoadReport() []Load {
+	recMu.Lock()
+	defer recMu.Unlock()
+	return append([]Load(nil), loads...)
+}
@@ -0,0 +1,22 @@
+package types
+
+// Reset restores the package's process-wide state: it removes the hook,
+// limits, clock override, profile override, pins, and the access and
+// load records, and turns access recording off. Event subscriptions
+// are left to their cancel functions. Meant for tests; see
+// envvartest.ResetGlobalState.
+func Reset() {
+	SetHook(nil)
+	SetLimits(Limits{})
+	SetClock(nil)
+	SetProfile("")
+	pinsMu.Lock()
+	pins = map[string]string{}
+	pinPolicy = PinAlert
+	pinsMu.Unlock()
+	SetRecordAccess(false)
+	recMu.Lock()
+	accesses = map[string]*Access{}
+	loads = nil
+	recMu.Unlock()
+}
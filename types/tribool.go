@@ -0,0 +1,58 @@
+package types
+
+// TriBool is a boolean that distinguishes "not configured" from an
+// explicit true or false. The zero value is TriUnset.
+type TriBool int
+
+const (
+	// TriUnset means the variable is absent or empty.
+	TriUnset TriBool = iota
+	// TriFalse means the variable is set to a false value.
+	TriFalse
+	// TriTrue means the variable is set to a true value.
+	TriTrue
+)
+
+// String returns "unset", "false", or "true".
+func (b TriBool) String() string {
+	switch b {
+	case TriFalse:
+		return "false"
+	case TriTrue:
+		return "true"
+	}
+	return "unset"
+}
+
+// IsSet reports whether b is TriTrue or TriFalse.
+func (b TriBool) IsSet() bool {
+	return b == TriTrue || b == TriFalse
+}
+
+// Or returns the boolean value of b, or def when unset.
+//
+// Parameters:
+//   - def: The value used when b is TriUnset.
+//
+// Returns:
+//   - bool: The value.
+func (b TriBool) Or(def bool) bool {
+	if !b.IsSet() {
+		return def
+	}
+	return b == TriTrue
+}
+
+// TriOf converts a bool to TriTrue or TriFalse.
+//
+// Parameters:
+//   - v: The value.
+//
+// Returns:
+//   - TriBool: The tri-state value.
+func TriOf(v bool) TriBool {
+	if v {
+		return TriTrue
+	}
+	return TriFalse
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,11 +15,118 @@ type Hook interface {
 	OnGet(key string, ok bool, err error, dur time.Duration)
 }
 
+// ErrorHook is an optional extension of Hook. Hooks implementing it are
+// notified of background errors, such as failed source refreshes.
+type ErrorHook interface {
+	// OnError is called when source encounters err outside a getter.
+	OnError(source string, err error)
+}
+
+// ChangeKind classifies a variable change.
+type ChangeKind int
+
+const (
+	// Added means the variable was not present before.
+	Added ChangeKind = iota + 1
+	// Removed means the variable is no longer present.
+	Removed
+	// Modified means the variable value changed.
+	Modified
+)
+
+// String returns the change kind name.
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Change describes a single variable change.
+type Change struct {
+	Key  string
+	Kind ChangeKind
+	Old  string
+	New  string
+}
+
+// String renders the change as "+KEY=new", "-KEY=old", or
+// "~KEY: old -> new".
+//
+// Returns:
+//   - string: The change.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return "+" + c.Key + "=" + c.New
+	case Removed:
+		return "-" + c.Key + "=" + c.Old
+	}
+	return "~" + c.Key + ": " + c.Old + " -> " + c.New
+}
+
+// ChangeHook is an optional extension of Hook. Hooks implementing it
+// are notified when watched sources change.
+type ChangeHook interface {
+	// OnChange is called with the changes detected in source.
+	OnChange(source string, changes []Change)
+}
+
+// AuthHook is an optional extension of Hook. Hooks implementing it are
+// notified when a token-based source refreshes its credentials after
+// the server rejected them.
+type AuthHook interface {
+	// OnAuthRefresh is called after source fetched new credentials; err
+	// is non-nil when fetching them failed.
+	OnAuthRefresh(source string, err error)
+}
+
+// BindHook is an optional extension of Hook. Hooks implementing it are
+// notified when a Bind call finishes.
+type BindHook interface {
+	// OnBind is called with the bound struct type, the number of tagged
+	// fields, the number of errors, and the time spent.
+	OnBind(structType string, fields, errors int, dur time.Duration)
+}
+
+// ValidationHook is an optional extension of Hook. Hooks implementing
+// it are notified when a field fails a validation rule during Bind and
+// when a value fails to parse, reported with the rule "type".
+type ValidationHook interface {
+	// OnValidationError is called with the Go field name (the key for
+	// getters), the failed rule, and the (redacted) message.
+	OnValidationError(field, rule, msg string)
+}
+
+// DefaultHook is an optional extension of Hook. Hooks implementing it
+// are notified when a default is used in place of a variable, so teams
+// can alert on production configuration drifting onto defaults.
+type DefaultHook interface {
+	// OnDefaultUsed is called with the key and the (redacted) default
+	// returned by an Or getter or applied from an envdef tag.
+	OnDefaultUsed(key, value string)
+}
+
+// MissHook is an optional extension of Hook. Hooks implementing it are
+// notified when a lookup finds no value.
+type MissHook interface {
+	// OnMissing is called with the key that was not set.
+	OnMissing(key string)
+}
+
 var (
 	// hookMu protects hook.
 	hookMu sync.RWMutex
 	// hook is the global hook instance.
 	hook Hook
+	// hookSet mirrors hook != nil so hot paths can check it without
+	// taking hookMu.
+	hookSet atomic.Bool
 )
 
 // SetHook installs a global hook. It is safe to call at program init.
@@ -29,10 +137,24 @@ func SetHook(h Hook) {
 	hookMu.Lock()
 	defer hookMu.Unlock()
 	hook = h
+	hookSet.Store(h != nil)
 }
 
-// CallOnLoad calls the OnLoad hook.
+// Observed reports whether a hook or an event subscriber is installed.
+// Reads are timed only when observed, so the common case skips the
+// clock entirely.
+//
+// Returns:
+//   - bool: True if durations passed to CallOnGet are used.
+func Observed() bool {
+	return hookSet.Load() || nsubs.Load() > 0
+}
+
+// CallOnLoad records the load for LoadReport, emits an EventLoaded,
+// and calls the OnLoad hook.
 func CallOnLoad(source string, keys int) {
+	recordLoad(source, keys)
+	Emit(Event{Kind: EventLoaded, Source: source, Keys: keys})
 	hookMu.RLock()
 	defer hookMu.RUnlock()
 	if hook != nil {
@@ -40,11 +162,97 @@ func CallOnLoad(source string, keys int) {
 	}
 }
 
-// CallOnGet calls the OnGet hook.
+// CallOnGet records the read for AccessReport, emits an EventGet or
+// EventMiss, and calls the OnGet hook, followed by OnMissing on misses
+// if the hook implements MissHook.
 func CallOnGet(key string, ok bool, err error, d time.Duration) {
+	recordGet(key, ok, err)
+	if ok {
+		Emit(Event{Kind: EventGet, Key: key, Err: err, Dur: d})
+	} else {
+		Emit(Event{Kind: EventMiss, Key: key, Err: err, Dur: d})
+	}
+	if !hookSet.Load() {
+		return
+	}
 	hookMu.RLock()
 	defer hookMu.RUnlock()
 	if hook != nil {
 		hook.OnGet(key, ok, err, d)
 	}
+	if mh, isMiss := hook.(MissHook); isMiss && !ok {
+		mh.OnMissing(key)
+	}
+}
+
+// CallOnError calls the OnError hook if the installed hook implements
+// ErrorHook.
+func CallOnError(source string, err error) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if eh, ok := hook.(ErrorHook); ok {
+		eh.OnError(source, err)
+	}
+}
+
+// CallOnChange emits an EventChanged and calls the OnChange hook if
+// the installed hook implements ChangeHook.
+func CallOnChange(source string, changes []Change) {
+	Emit(Event{Kind: EventChanged, Source: source, Changes: changes})
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if ch, ok := hook.(ChangeHook); ok {
+		ch.OnChange(source, changes)
+	}
+}
+
+// CallOnBind calls the OnBind hook if the installed hook implements
+// BindHook.
+func CallOnBind(structType string, fields, errors int, dur time.Duration) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if bh, ok := hook.(BindHook); ok {
+		bh.OnBind(structType, fields, errors, dur)
+	}
+}
+
+// CallOnValidationError calls the OnValidationError hook if the
+// installed hook implements ValidationHook.
+func CallOnValidationError(field, rule, msg string) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if vh, ok := hook.(ValidationHook); ok {
+		vh.OnValidationError(field, rule, msg)
+	}
+}
+
+// CallOnDefaultUsed calls the OnDefaultUsed hook if the installed hook
+// implements DefaultHook. Callers redact value.
+func CallOnDefaultUsed(key, value string) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if dh, ok := hook.(DefaultHook); ok {
+		dh.OnDefaultUsed(key, value)
+	}
+}
+
+// CallOnMissing calls the OnMissing hook if the installed hook
+// implements MissHook. Getters report misses through CallOnGet; this is
+// for lookups that bypass it, such as Bind.
+func CallOnMissing(key string) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if mh, ok := hook.(MissHook); ok {
+		mh.OnMissing(key)
+	}
+}
+
+// CallOnAuthRefresh calls the OnAuthRefresh hook if the installed hook
+// implements AuthHook.
+func CallOnAuthRefresh(source string, err error) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if ah, ok := hook.(AuthHook); ok {
+		ah.OnAuthRefresh(source, err)
+	}
 }
@@ -3,6 +3,9 @@ package types
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -44,3 +47,171 @@ func TestHooksIntegration(t *testing.T) {
 	}
 	// loads may be 0 if LoadOnce already ran; ensure code path safe.
 }
+
+func TestParseBytes(t *testing.T) {
+	cases := map[string]uint64{
+		"512":     512,
+		"512B":    512,
+		"1KB":     1000,
+		"1KiB":    1024,
+		"64Ki":    64 << 10,
+		"2 GiB":   2 << 30,
+		"512mb":   512e6,
+		"1.5GiB":  3 << 29,
+		" 3 T ":   3e12,
+		"16EiB":   0, // overflow, checked below
+		"1.5 kib": 1536,
+	}
+	for in, want := range cases {
+		got, err := ParseBytes(in)
+		if in == "16EiB" {
+			if err == nil {
+				t.Fatalf("ParseBytes(%q): want overflow error", in)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Fatalf("ParseBytes(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "MB", "12XB", "-1KB"} {
+		if _, err := ParseBytes(bad); err == nil {
+			t.Fatalf("ParseBytes(%q): want error", bad)
+		}
+	}
+	if s := Bytes(2 << 30).String(); s != "2GiB" {
+		t.Fatalf("String = %s", s)
+	}
+}
+
+func TestNames(t *testing.T) {
+	for _, ok := range []string{"PORT", "_X", "a1_B"} {
+		if err := ValidName(ok); err != nil {
+			t.Errorf("ValidName(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", "1X", "A-B", "A B", "Ä"} {
+		if ValidName(bad) == nil {
+			t.Errorf("ValidName(%q) = nil", bad)
+		}
+	}
+	for in, want := range map[string]string{
+		"db.host-name": "DB_HOST_NAME",
+		"--x--y--":     "X_Y",
+		"9lives":       "_9LIVES",
+		"...":          "_",
+		"":             "",
+	} {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	m, err := NormalizeNames([]string{"db.host", "db-host", "port"})
+	if err == nil || m["port"] != "PORT" {
+		t.Fatalf("NormalizeNames = %v, %v", m, err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	t.Setenv("GO_ENV", "Production")
+	if p := CurrentProfile(); p != ProfileProd {
+		t.Fatalf("GO_ENV=Production: %q", p)
+	}
+	if _, ok := ParseBool("yes"); ok {
+		t.Fatal("prod must reject yes")
+	}
+	if b, ok := ParseBool("1"); !ok || !b {
+		t.Fatal("prod must accept 1")
+	}
+	SetProfile(ProfileStaging)
+	defer SetProfile("")
+	if p := CurrentProfile(); p != ProfileStaging || !p.WarnUnknown() {
+		t.Fatalf("SetProfile must override env: %q", p)
+	}
+	if b, ok := ParseBool("on"); !ok || !b {
+		t.Fatal("staging must accept on")
+	}
+	if ParseProfile("qa") != ProfileDev {
+		t.Fatal("unknown names map to dev")
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ch := Events()
+	CallOnLoad("test.env", 2)
+	CallOnGet("EV_A", true, nil, 0)
+	CallOnGet("EV_B", false, nil, 0)
+	CallOnChange("test.env", []Change{{Key: "EV_A", Kind: Modified}})
+	StopEvents(ch)
+	var kinds []EventKind
+	for e := range ch {
+		if e.At.IsZero() {
+			t.Fatalf("event without time: %+v", e)
+		}
+		kinds = append(kinds, e.Kind)
+	}
+	want := []EventKind{EventLoaded, EventGet, EventMiss, EventChanged}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+	Emit(Event{Kind: EventGet}) // no subscribers; must not block
+}
+
+// blockingHook blocks OnGet until release is closed.
+type blockingHook struct {
+	release chan struct{}
+	gets    atomic.Int32
+}
+
+func (h *blockingHook) OnLoad(string, int) {}
+func (h *blockingHook) OnGet(string, bool, error, time.Duration) {
+	<-h.release
+	h.gets.Add(1)
+}
+
+func TestAsyncHook(t *testing.T) {
+	h := &blockingHook{release: make(chan struct{})}
+	a := NewAsyncHook(h, 2)
+	// The first call occupies the worker; two fill the queue; the rest
+	// are dropped without blocking.
+	for i := 0; i < 10; i++ {
+		a.OnGet("K", true, nil, 0)
+	}
+	if a.Dropped() < 7 {
+		t.Fatalf("Dropped = %d, want >= 7", a.Dropped())
+	}
+	close(h.release)
+	a.Close()
+	if got := uint64(h.gets.Load()); got != a.Delivered() || got+a.Dropped() != 10 {
+		t.Fatalf("delivered %d, dropped %d", got, a.Dropped())
+	}
+	a.OnGet("K", true, nil, 0)
+	if a.Dropped() == 0 || h.gets.Load() != int32(a.Delivered()) {
+		t.Fatal("calls after Close must be dropped")
+	}
+}
+
+func TestAsyncHookCloseRace(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		h := &blockingHook{release: make(chan struct{})}
+		close(h.release)
+		a := NewAsyncHook(h, 64)
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					a.OnGet("K", true, nil, 0)
+				}
+			}()
+		}
+		a.Close()
+		wg.Wait()
+		if a.Pending() != 0 || a.Delivered()+a.Dropped() != 400 {
+			t.Fatalf("pending %d, delivered %d, dropped %d",
+				a.Pending(), a.Delivered(), a.Dropped())
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// crossRules are the rules that reference a sibling field by its Go
+// name, e.g. `validate:"ltefield=MaxConns"`. Field skips them; Cross
+// evaluates them once every field is bound.
+var crossRules = map[string]string{
+	"required_with":    "",
+	"required_without": "",
+	"eqfield":          "==",
+	"nefield":          "!=",
+	"gtfield":          ">",
+	"gtefield":         ">=",
+	"ltfield":          "<",
+	"ltefield":         "<=",
+}
+
+// ErrRequired is returned by Cross when a required_with or
+// required_without rule fails.
+var ErrRequired = errors.New("required")
+
+// Cross checks the cross-field rules in tag for the field named field
+// of the struct sv. isSet reports whether a field, by Go name, received
+// a value. required_with=Other fails when Other is set and field is not;
+// required_without=Other fails when neither is set, and accepts several
+// fields separated by '|', so at least one of them must be set. The
+// comparison rules run only when field is set and compare it with
+// the other field's current value. Other rules in tag are ignored.
+//
+// Parameters:
+//   - sv: The struct holding both fields; promoted fields are found.
+//   - field: The Go name of the field being checked.
+//   - tag: The validate tag.
+//   - isSet: Reports whether a field received a value.
+//
+// Returns:
+//   - error: The *RuleError if a rule fails or names an unknown field.
+//     A failed required_with or required_without wraps ErrRequired.
+func Cross(sv reflect.Value, field, tag string, isSet func(field string) bool) error {
+	for _, r := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(r), "=")
+		if _, ok := crossRules[name]; !ok {
+			continue
+		}
+		if err := crossRule(sv, field, name, param, isSet); err != nil {
+			return &RuleError{Rule: name, Param: param, Err: err}
+		}
+	}
+	return nil
+}
+
+// crossRule evaluates a single cross-field rule.
+func crossRule(sv reflect.Value, field, name, param string, isSet func(string) bool) error {
+	if name == "required_without" {
+		others := strings.Split(param, "|")
+		for _, o := range others {
+			if !fieldByName(sv, o).IsValid() {
+				return fmt.Errorf("%s: unknown field %q", name, o)
+			}
+		}
+		for _, o := range others {
+			if isSet(o) {
+				return nil
+			}
+		}
+		switch {
+		case isSet(field):
+			return nil
+		case len(others) == 1:
+			return fmt.Errorf("%w when %s is not set", ErrRequired, param)
+		}
+		return fmt.Errorf("%w when none of %s is set", ErrRequired, strings.Join(others, ", "))
+	}
+	other := fieldByName(sv, param)
+	if !other.IsValid() {
+		return fmt.Errorf("%s: unknown field %q", name, param)
+	}
+	if name == "required_with" {
+		if isSet(param) && !isSet(field) {
+			return fmt.Errorf("%w when %s is set", ErrRequired, param)
+		}
+		return nil
+	}
+	if !isSet(field) {
+		return nil
+	}
+	cmp, ok := compareValues(deref(fieldByName(sv, field)), deref(other))
+	if !ok {
+		return fmt.Errorf("%s: cannot compare with %s", name, param)
+	}
+	if !holds(cmp, crossRules[name]) {
+		return fmt.Errorf("must be %s %s", crossRules[name], param)
+	}
+	return nil
+}
+
+// fieldByName is like sv.FieldByName but returns the zero value of a
+// field promoted through a nil embedded pointer instead of panicking.
+func fieldByName(sv reflect.Value, name string) reflect.Value {
+	sf, ok := sv.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}
+	}
+	v, err := sv.FieldByIndexErr(sf.Index)
+	if err != nil {
+		return reflect.Zero(sf.Type)
+	}
+	return v
+}
+
+// deref follows pointers, returning the zero Value for nil.
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// compareValues orders two numbers or strings of compatible kinds. A
+// nil pointer compares as the zero value of the other operand.
+func compareValues(a, b reflect.Value) (int, bool) {
+	if !a.IsValid() && !b.IsValid() {
+		return 0, true
+	}
+	if !a.IsValid() {
+		a = reflect.Zero(b.Type())
+	}
+	if !b.IsValid() {
+		b = reflect.Zero(a.Type())
+	}
+	switch {
+	case isInt(a) && isInt(b):
+		return compare(a.Int(), b.Int()), true
+	case isUint(a) && isUint(b):
+		return compare(a.Uint(), b.Uint()), true
+	case isFloat(a) && isFloat(b):
+		return compare(a.Float(), b.Float()), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	}
+	return 0, false
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isFloat(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"errors"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// stringRule adapts a string check into a rule. Values of other kinds
+// are left alone, so the rules only constrain string fields.
+func stringRule(check func(s string) error) ParamFunc {
+	return func(v reflect.Value, _ string) error {
+		if v.Kind() != reflect.String {
+			return nil
+		}
+		return check(v.String())
+	}
+}
+
+// checkIP accepts IPv4 and IPv6 addresses, including zones.
+func checkIP(s string) error {
+	if _, err := netip.ParseAddr(s); err != nil {
+		return errors.New("must be an IP address, e.g. 10.0.0.1")
+	}
+	return nil
+}
+
+// checkCIDR accepts prefixes such as 10.0.0.0/8.
+func checkCIDR(s string) error {
+	if _, err := netip.ParsePrefix(s); err != nil {
+		return errors.New("must be a CIDR prefix, e.g. 10.0.0.0/8")
+	}
+	return nil
+}
+
+// checkHostname accepts RFC 1123 host names: dot-separated labels of
+// letters, digits, and inner hyphens, each at most 63 bytes and 253
+// bytes in total. A single trailing dot is allowed.
+func checkHostname(s string) error {
+	bad := errors.New("must be a valid hostname, e.g. db.internal")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return bad
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 ||
+			label[0] == '-' || label[len(label)-1] == '-' {
+			return bad
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+				r >= '0' && r <= '9' || r == '-') {
+				return bad
+			}
+		}
+	}
+	return nil
+}
+
+// checkPort accepts ports 1 through 65535 in string and integer fields.
+func checkPort(v reflect.Value, _ string) error {
+	bad := errors.New("must be a port between 1 and 65535")
+	switch v.Kind() {
+	case reflect.String:
+		n, err := strconv.ParseUint(v.String(), 10, 16)
+		if err != nil || n == 0 {
+			return bad
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n < 1 || n > 65535 {
+			return bad
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n := v.Uint(); n < 1 || n > 65535 {
+			return bad
+		}
+	}
+	return nil
+}
+
+// checkURL accepts absolute URLs with a scheme and host, e.g.
+// https://example.com/path.
+func checkURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("must be an absolute URL, e.g. https://example.com")
+	}
+	return nil
+}
+
+// checkEmail accepts a bare address such as ops@example.com; display
+// names are rejected.
+func checkEmail(s string) error {
+	a, err := mail.ParseAddress(s)
+	if err != nil || a.Address != s {
+		return errors.New("must be an email address, e.g. ops@example.com")
+	}
+	return nil
+}
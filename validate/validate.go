@@ -0,0 +1,354 @@
+// Package validate checks bound values against `validate` struct tags
+// such as `validate:"min=1,max=65535"`. Rules are looked up by name in
+// a registry, so applications can add their own next to the built-in
+// required, min, max, gt, gte, lt, lte, and oneof rules and the network
+// format rules ip, cidr, hostname, port, url, and email.
+//
+// Unknown rule names are ignored, so tags shared with other validation
+// libraries keep binding; SetStrict makes them fail instead.
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Func validates a field value.
+type Func func(v reflect.Value) error
+
+// ParamFunc validates a field value against the rule parameter, e.g.
+// "5" in `min=5`.
+type ParamFunc func(v reflect.Value, param string) error
+
+var (
+	// rulesMu protects rules.
+	rulesMu sync.RWMutex
+	// rulesGen counts registrations, so parsed Rules notice them.
+	rulesGen atomic.Uint64
+	// rules maps rule names to their implementations.
+	rules = builtinRules()
+)
+
+// builtinRules returns the rules registered before any Register call.
+func builtinRules() map[string]ParamFunc {
+	return map[string]ParamFunc{
+		// required is enforced by Bind, which treats it like the
+		// required option of the env tag; a bound value satisfies it.
+		"required": func(reflect.Value, string) error { return nil },
+		"min":      checkMin,
+		"max":      checkMax,
+		"gt":       boundRule("gt", ">"),
+		"gte":      boundRule("gte", ">="),
+		"lt":       boundRule("lt", "<"),
+		"lte":      boundRule("lte", "<="),
+		"oneof":    checkOneOf,
+		// Network formats, see network.go.
+		"ip":       stringRule(checkIP),
+		"cidr":     stringRule(checkCIDR),
+		"hostname": stringRule(checkHostname),
+		"port":     checkPort,
+		"url":      stringRule(checkURL),
+		"email":    stringRule(checkEmail),
+	}
+}
+
+// strict makes unknown rule names fail.
+var strict atomic.Bool
+
+// SetStrict makes Field fail on rule names that are not registered,
+// catching typos such as `validate:"mni=1"`. It is off by default so
+// tags written for other validators, e.g. go-playground/validator, are
+// ignored rather than rejected.
+//
+// Parameters:
+//   - on: Whether unknown rules fail.
+func SetStrict(on bool) {
+	strict.Store(on)
+}
+
+// Register installs a rule that tags reference by name, e.g.
+// `validate:"s3bucket"`. Registering an existing name replaces it.
+//
+// Parameters:
+//   - name: The rule name.
+//   - fn: The rule.
+func Register(name string, fn Func) {
+	RegisterParam(name, func(v reflect.Value, _ string) error { return fn(v) })
+}
+
+// RegisterParam installs a rule that takes a parameter, e.g.
+// `validate:"prefix=s3://"`.
+//
+// Parameters:
+//   - name: The rule name.
+//   - fn: The rule.
+func RegisterParam(name string, fn ParamFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = fn
+	rulesGen.Add(1)
+}
+
+// Reset removes registered rules, restoring the built-in ones, and
+// turns strict mode off. Meant for tests; see
+// envvartest.ResetGlobalState.
+func Reset() {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules = builtinRules()
+	rulesGen.Add(1)
+	strict.Store(false)
+}
+
+// RuleError reports the rule that rejected a value.
+type RuleError struct {
+	// Rule is the rule name, e.g. "min".
+	Rule string
+	// Param is the rule parameter, e.g. "1" in `min=1`.
+	Param string
+	// Err is the rule's error.
+	Err error
+}
+
+// Error returns the rule's message.
+//
+// Returns:
+//   - string: The error message.
+func (e *RuleError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the rule's error.
+//
+// Returns:
+//   - error: The wrapped error.
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Field checks v against the rules in tag, a comma-separated list of
+// "name" or "name=param" entries. Pointers are dereferenced and nil
+// pointers skipped. The first failing rule is reported as a
+// *RuleError. Cross-field rules such as eqfield are skipped; see
+// Cross. Unknown rules are skipped unless SetStrict is on.
+//
+// Parameters:
+//   - v: The value.
+//   - tag: The validate tag.
+//
+// Returns:
+//   - error: The error if a rule fails, or is unknown under SetStrict.
+func Field(v reflect.Value, tag string) error {
+	return Parse(tag).Check(v)
+}
+
+// Rules is a parsed validate tag, for callers that check the same tag
+// repeatedly.
+type Rules struct {
+	list []rule
+	// gen is the registry generation the functions were looked up in.
+	gen uint64
+}
+
+// rule is one parsed entry of a validate tag.
+type rule struct {
+	name, param string
+	// fn is nil for names not registered at parse time.
+	fn ParamFunc
+}
+
+// Parse parses tag, a comma-separated list of "name" or "name=param"
+// entries, skipping cross-field rules. Rule functions are looked up
+// once; registering a rule later makes Check look them up again.
+//
+// Parameters:
+//   - tag: The validate tag.
+//
+// Returns:
+//   - Rules: The parsed rules.
+func Parse(tag string) Rules {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	rs := Rules{gen: rulesGen.Load()}
+	for _, r := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(r), "=")
+		if _, cross := crossRules[name]; name == "" || cross {
+			continue
+		}
+		rs.list = append(rs.list, rule{name: name, param: param, fn: rules[name]})
+	}
+	return rs
+}
+
+// Check checks v against the rules like Field.
+//
+// Parameters:
+//   - v: The value.
+//
+// Returns:
+//   - error: The error if a rule fails, or is unknown under SetStrict.
+func (rs Rules) Check(v reflect.Value) error {
+	if len(rs.list) == 0 {
+		return nil
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	stale := rs.gen != rulesGen.Load()
+	for _, r := range rs.list {
+		fn := r.fn
+		if stale {
+			rulesMu.RLock()
+			fn = rules[r.name]
+			rulesMu.RUnlock()
+		}
+		if fn == nil {
+			if strict.Load() {
+				return fmt.Errorf("unknown validation rule %q", r.name)
+			}
+			continue
+		}
+		if err := fn(v, r.param); err != nil {
+			return &RuleError{Rule: r.name, Param: r.param, Err: err}
+		}
+	}
+	return nil
+}
+
+// checkMin checks numbers against the bound and strings, slices, and
+// maps against the length.
+func checkMin(v reflect.Value, param string) error {
+	return checkBound(v, param, "min", ">=")
+}
+
+// checkMax is the upper-bound counterpart of checkMin.
+func checkMax(v reflect.Value, param string) error {
+	return checkBound(v, param, "max", "<=")
+}
+
+// boundRule returns a rule comparing values with rel, e.g. "gt" with ">".
+func boundRule(rule, rel string) ParamFunc {
+	return func(v reflect.Value, param string) error {
+		return checkBound(v, param, rule, rel)
+	}
+}
+
+// checkBound compares v with param using rel, one of ">=", "<=", ">",
+// or "<". Numbers compare by value, strings, slices, and maps by length.
+// Negative bounds are accepted for unsigned fields.
+func checkBound(v reflect.Value, param, rule, rel string) error {
+	var cmp int
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var bound int64
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(param)
+			if err != nil {
+				return fmt.Errorf("invalid %s parameter %q", rule, param)
+			}
+			bound = int64(d)
+		} else {
+			b, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s parameter %q", rule, param)
+			}
+			bound = b
+		}
+		cmp = compare(v.Int(), bound)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if strings.HasPrefix(param, "-") {
+			if _, err := strconv.ParseInt(param, 10, 64); err != nil {
+				return fmt.Errorf("invalid %s parameter %q", rule, param)
+			}
+			cmp = 1
+			break
+		}
+		bound, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s parameter %q", rule, param)
+		}
+		cmp = compare(v.Uint(), bound)
+	case reflect.Float32, reflect.Float64:
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil || math.IsNaN(bound) {
+			return fmt.Errorf("invalid %s parameter %q", rule, param)
+		}
+		f := v.Float()
+		if math.IsNaN(f) {
+			return fmt.Errorf("must be %s %s, got NaN", rel, param)
+		}
+		cmp = compare(f, bound)
+	case reflect.String, reflect.Slice, reflect.Map:
+		bound, err := strconv.Atoi(param)
+		if err != nil {
+			return fmt.Errorf("invalid %s parameter %q", rule, param)
+		}
+		n := v.Len()
+		if v.Kind() == reflect.String {
+			n = utf8.RuneCountInString(v.String())
+		}
+		if !holds(compare(n, bound), rel) {
+			return fmt.Errorf("length must be %s %s", rel, param)
+		}
+		return nil
+	default:
+		return nil
+	}
+	if !holds(cmp, rel) {
+		return fmt.Errorf("must be %s %s", rel, param)
+	}
+	return nil
+}
+
+// holds reports whether the comparison result cmp (-1, 0, 1 for less,
+// equal, greater) satisfies rel.
+func holds(cmp int, rel string) bool {
+	switch rel {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	return false
+}
+
+// compare returns -1, 0, or 1.
+func compare[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// checkOneOf checks that v, formatted, is one of the "|"-separated
+// options in param.
+func checkOneOf(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	for _, opt := range strings.Split(param, "|") {
+		if s == opt {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.ReplaceAll(param, "|", ", "))
+}
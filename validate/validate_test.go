@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	port := 8080
+	cases := []struct {
+		v    any
+		tag  string
+		want string
+	}{
+		{8080, "min=1,max=65535", ""},
+		{0, "min=1", "must be >= 1"},
+		{uint16(70), "max=64", "must be <= 64"},
+		{"ab", "min=3", "length must be >= 3"},
+		{[]string{"a", "b"}, "max=1", "length must be <= 1"},
+		{2 * time.Second, "min=5s", "must be >= 5s"},
+		{0.5, "min=0,max=1", ""},
+		{1.5, "max=1", "must be <= 1"},
+		{float32(-0.25), "gte=-0.5,lt=0", ""},
+		{0.0, "gt=0", "must be > 0"},
+		{-3, "gt=-5,lte=-3", ""},
+		{-5, "gt=-5", "must be > -5"},
+		{uint(0), "gt=-1", ""},
+		{uint(10), "lt=10", "must be < 10"},
+		{1.0, "min=x", `invalid min parameter "x"`},
+		{"abc", "gt=2,lt=4", ""},
+		{"warn", "oneof=debug|info|warn", ""},
+		{"trace", "oneof=debug|info", "must be one of debug, info"},
+		{&port, "max=80", "must be <= 80"},
+		{(*int)(nil), "min=1", ""},
+		{1, "nosuchrule,min=2", "must be >= 2"},
+		{"", "required", ""},
+	}
+	for _, c := range cases {
+		err := Field(reflect.ValueOf(c.v), c.tag)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != c.want {
+			t.Errorf("Field(%v, %q) = %q, want %q", c.v, c.tag, got, c.want)
+		}
+	}
+	SetStrict(true)
+	defer SetStrict(false)
+	if err := Field(reflect.ValueOf(1), "nosuchrule"); err == nil ||
+		err.Error() != `unknown validation rule "nosuchrule"` {
+		t.Fatalf("strict: %v", err)
+	}
+}
+
+func TestNetworkRules(t *testing.T) {
+	cases := []struct {
+		v    any
+		tag  string
+		want bool
+	}{
+		{"10.0.0.1", "ip", true},
+		{"fe80::1%eth0", "ip", true},
+		{"10.0.0.300", "ip", false},
+		{"10.0.0.0/8", "cidr", true},
+		{"10.0.0.0", "cidr", false},
+		{"db-1.internal", "hostname", true},
+		{"localhost.", "hostname", true},
+		{"-db.internal", "hostname", false},
+		{"db_1.internal", "hostname", false},
+		{"8080", "port", true},
+		{"0", "port", false},
+		{70000, "port", false},
+		{uint16(443), "port", true},
+		{"https://example.com/x", "url", true},
+		{"example.com", "url", false},
+		{"ops@example.com", "email", true},
+		{"Ops <ops@example.com>", "email", false},
+		{"ops", "email", false},
+	}
+	for _, c := range cases {
+		err := Field(reflect.ValueOf(c.v), c.tag)
+		if (err == nil) != c.want {
+			t.Errorf("Field(%v, %q) = %v, want ok=%v", c.v, c.tag, err, c.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("s3bucket", func(v reflect.Value) error {
+		if !strings.HasPrefix(v.String(), "s3://") {
+			return errors.New("must be an s3:// bucket")
+		}
+		return nil
+	})
+	if err := Field(reflect.ValueOf("s3://logs"), "s3bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Field(reflect.ValueOf("gs://logs"), "min=1,s3bucket"); err == nil {
+		t.Fatal("want custom rule error")
+	}
+	SetStrict(true)
+	Reset()
+	if err := Field(reflect.ValueOf("gs://logs"), "min=1,s3bucket"); err != nil {
+		t.Fatalf("after Reset: %v", err)
+	}
+	if err := Field(reflect.ValueOf(""), "min=1"); err == nil {
+		t.Fatal("Reset dropped built-in rules")
+	}
+}
+
+func TestCross(t *testing.T) {
+	cfg := struct {
+		Lo, Hi float64
+		Name   string
+	}{Lo: 2, Hi: 1}
+	set := func(string) bool { return true }
+	sv := reflect.ValueOf(cfg)
+	if err := Cross(sv, "Lo", "min=0,ltfield=Hi", set); err == nil || err.Error() != "must be < Hi" {
+		t.Fatalf("ltfield: %v", err)
+	}
+	if err := Cross(sv, "Lo", "gtfield=Hi", set); err != nil {
+		t.Fatalf("gtfield: %v", err)
+	}
+	if err := Cross(sv, "Lo", "eqfield=Name", set); err == nil {
+		t.Fatal("want error comparing float with string")
+	}
+	if err := Cross(sv, "Lo", "eqfield=Nope", set); err == nil {
+		t.Fatal("want unknown field error")
+	}
+	unset := func(f string) bool { return f == "Hi" }
+	if err := Cross(sv, "Lo", "required_with=Hi", unset); !errors.Is(err, ErrRequired) {
+		t.Fatalf("required_with: %v", err)
+	}
+	none := func(string) bool { return false }
+	if err := Cross(sv, "Lo", "required_without=Hi|Name", none); !errors.Is(err, ErrRequired) ||
+		err.Error() != "required when none of Hi, Name is set" {
+		t.Fatalf("required_without: %v", err)
+	}
+	if err := Cross(sv, "Lo", "required_without=Hi|Name", unset); err != nil {
+		t.Fatalf("required_without with Hi set: %v", err)
+	}
+	if err := Cross(sv, "Lo", "required_without=Hi|Nope", none); err == nil || errors.Is(err, ErrRequired) {
+		t.Fatalf("required_without unknown field: %v", err)
+	}
+	if err := Field(reflect.ValueOf(1.0), "eqfield=Hi"); err != nil {
+		t.Fatalf("Field must skip cross-field rules: %v", err)
+	}
+}